@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.zoe.im/x/cli"
+
+	"go.zoe.im/goser/expr"
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+var breakingOpts = struct {
+	old       string
+	new       string
+	transport string
+}{}
+
+// breakingReport is the JSON output of the breaking command
+type breakingReport struct {
+	// Breaking is true if at least one change is breaking
+	Breaking bool `json:"breaking"`
+	// Changes lists all the changes, compatible ones included
+	Changes []*expr.Change `json:"changes"`
+}
+
+// errBreaking is returned by the breaking command if any change is breaking
+var errBreaking = errors.New("breaking changes found")
+
+// breaking command detects the breaking changes between two versions of spec
+var breakingCmd = cli.New(
+	cli.Name("breaking"),
+	cli.Short("Detect the breaking changes between two versions of spec files."),
+	cli.Description(`Breaking compares the models and services of the old and the new
+spec files, and classifies every change as compatible or breaking
+for the http and grpc transports.
+
+The old version is a directory, a spec file or a git revision, in
+which case the spec files at the path of the new version are read
+from the revision.
+
+The report is printed as JSON and the command fails with a non-zero
+exit status if any change is breaking, which makes it usable as a CI
+gate.
+	`),
+	cli.RunE(func(c *cli.Command, args ...string) error {
+		ok, err := breaking()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errBreaking
+		}
+		return nil
+	}),
+)
+
+// breaking prints the report of the changes and returns false if any change
+// breaks the selected transport.
+func breaking() (bool, error) {
+	if breakingOpts.old == "" {
+		return false, fmt.Errorf("the old version is required")
+	}
+	switch breakingOpts.transport {
+	case "", expr.TransportHTTP, expr.TransportGRPC:
+	default:
+		return false, fmt.Errorf("unknown transport %q", breakingOpts.transport)
+	}
+	oldRt, err := loadDirOrRef(breakingOpts.old, breakingOpts.new)
+	if err != nil {
+		return false, err
+	}
+	newRt, err := loadDir(breakingOpts.new)
+	if err != nil {
+		return false, err
+	}
+	changes := runtime.Compare(oldRt, newRt)
+	report := &breakingReport{
+		Breaking: len(expr.BreakingChanges(changes, breakingOpts.transport)) > 0,
+		Changes:  changes,
+	}
+	if report.Changes == nil {
+		report.Changes = []*expr.Change{}
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	fmt.Println(string(data))
+	return !report.Breaking, nil
+}
+
+func init() {
+	breakingCmd.Flags().StringVar(&breakingOpts.old, "old", "", "old spec directory, file or git revision")
+	breakingCmd.Flags().StringVar(&breakingOpts.new, "new", ".", "new spec directory or file")
+	breakingCmd.Flags().StringVarP(&breakingOpts.transport, "transport", "t", "", "only fail on the changes breaking the transport, http or grpc")
+	Register(breakingCmd)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"go.zoe.im/x/cli"
+
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+var changelogOpts = struct {
+	from   string
+	to     string
+	path   string
+	title  string
+	output string
+}{}
+
+// changelog command renders the changes between two versions of spec
+var changelogCmd = cli.New(
+	cli.Name("changelog"),
+	cli.Short("Generate a Markdown changelog between two versions of spec files."),
+	cli.Description(`Changelog compares the models and services of two versions of
+the spec files, and renders the additions, deprecations, changes
+and removals as Markdown grouped by service.
+
+The versions are git revisions or directories, the spec files at
+the path are read from the revisions. The new version defaults to
+the working tree.
+	`),
+	cli.RunE(func(c *cli.Command, args ...string) error {
+		return changelog()
+	}),
+)
+
+func changelog() error {
+	if changelogOpts.from == "" {
+		return fmt.Errorf("the version to compare from is required")
+	}
+	oldRt, err := loadDirOrRef(changelogOpts.from, changelogOpts.path)
+	if err != nil {
+		return err
+	}
+	var newRt *runtime.Runtime
+	if changelogOpts.to == "" {
+		newRt, err = loadDir(changelogOpts.path)
+	} else {
+		newRt, err = loadDirOrRef(changelogOpts.to, changelogOpts.path)
+	}
+	if err != nil {
+		return err
+	}
+	title := changelogOpts.title
+	if title == "" {
+		to := changelogOpts.to
+		if to == "" {
+			to = "working tree"
+		}
+		title = fmt.Sprintf("Changes from %s to %s", changelogOpts.from, to)
+	}
+	md := runtime.Changelog(title, oldRt, newRt)
+	if changelogOpts.output == "" {
+		fmt.Print(md)
+		return nil
+	}
+	return ioutil.WriteFile(changelogOpts.output, []byte(md), 0644)
+}
+
+func init() {
+	changelogCmd.Flags().StringVar(&changelogOpts.from, "from", "", "git revision or directory of the old version")
+	changelogCmd.Flags().StringVar(&changelogOpts.to, "to", "", "git revision or directory of the new version, default to the working tree")
+	changelogCmd.Flags().StringVarP(&changelogOpts.path, "path", "p", ".", "path of the spec files")
+	changelogCmd.Flags().StringVar(&changelogOpts.title, "title", "", "title of the changelog")
+	changelogCmd.Flags().StringVarP(&changelogOpts.output, "output", "o", "", "output file, default to stdout")
+	Register(changelogCmd)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"go.zoe.im/x/cli"
+
+	"go.zoe.im/goser/codegen"
+)
+
+var cliOpts = struct {
+	output  string
+	version string
+}{}
+
+// cli command generates the command-line clients of the servers
+var cliCmd = cli.New(
+	cli.Name("cli"),
+	cli.Short("Generate the command-line clients of the servers."),
+	cli.Description(`Cli generates a main package in cmd/<server>-cli for every server
+of the spec files. The client has a command per service hosted by
+the server and a subcommand per method, the payload is set with
+--body as JSON and the payload fields with a flag each.
+
+The --host, --var and --transport flags select the server URI,
+the results are printed as indented JSON. The files are always
+regenerated, they must not be edited.
+
+The client of a versioned API calls the methods of the version
+selected with --version, the latest one by default, the HTTP
+routes are prefixed with it. The gRPC transport calls the
+services registered by the servers of gen example.
+	`),
+	cli.RunE(func(c *cli.Command, args ...string) error {
+		return genCLI(args...)
+	}),
+)
+
+func genCLI(paths ...string) error {
+	files, err := specPaths(paths...)
+	if err != nil {
+		return err
+	}
+	r, err := loadRuntime(files...)
+	if err != nil {
+		return err
+	}
+
+	services := r.Services()
+	if vers := r.Versions(); len(vers) > 0 {
+		version := cliOpts.version
+		if version == "" {
+			version = vers[len(vers)-1]
+		}
+		found := false
+		for _, v := range vers {
+			found = found || v == version
+		}
+		if !found {
+			return fmt.Errorf("unknown version %s, must be one of %s", version, strings.Join(vers, ", "))
+		}
+		services = r.AtVersion(version).Services()
+	} else if cliOpts.version != "" {
+		return fmt.Errorf("the API is not versioned, --version %s can't be used", cliOpts.version)
+	}
+
+	var gen []*genFile
+	for _, svr := range r.Servers() {
+		dir := filepath.Join(cliOpts.output, "cmd", svr.Name+"-cli")
+		gen = append(gen,
+			&genFile{
+				Path: filepath.Join(dir, "main.go"),
+				Content: goFile(generatedHeader, "main", []string{
+					`"fmt"`, `"net/url"`, `"os"`, `"strings"`, "",
+					`"go.zoe.im/x/cli"`,
+				},
+					codegen.GoClientMain(svr),
+					codegen.GoHosts(svr),
+				),
+			},
+			&genFile{
+				Path: filepath.Join(dir, "commands.go"),
+				Content: goFile(generatedHeader, "main", []string{
+					`"bytes"`, `"context"`, `"crypto/tls"`, `"encoding/json"`, `"fmt"`,
+					`"io/ioutil"`, `"net/http"`, `"net/url"`, `"os"`, `"strconv"`, `"strings"`, "",
+					`"go.zoe.im/x/cli"`, `"google.golang.org/grpc"`,
+					`"google.golang.org/grpc/credentials"`, `"google.golang.org/grpc/encoding"`,
+				}, codegen.GoCLI(svr, services)),
+			},
+		)
+	}
+	if len(gen) == 0 {
+		return fmt.Errorf("no server found")
+	}
+	return writeFiles(gen...)
+}
+
+func init() {
+	cliCmd.Flags().StringVarP(&cliOpts.output, "output", "o", ".", "output directory")
+	cliCmd.Flags().StringVar(&cliOpts.version, "version", "", "API version called by the clients, default to the latest one")
+	genCmd.Register(cliCmd)
+}
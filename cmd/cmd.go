@@ -11,8 +11,12 @@ var (
 		cli.Short("Goser is a toolbox to generate for common backend develop."),
 		cli.Description(`Generate code from yaml to RPC, REST API, database etc.
 		
-Default we will generate code in current directory,
-and use parent directory name as package name.
+Default we will generate the Go types, the proto messages, the OpenAPI
+document and the SQL tables of the enums, the unions and the models, and
+the Go errors of the services in current directory, and use parent
+directory name as package name.
+The code of a versioned API is generated in a sub package per version,
+e.g. v1, and the package converts the models between the versions.
 
 To make the code directory clear you can put all the defined proto yaml
 files to a directory.
@@ -22,9 +26,9 @@ directory name as the package name.
 
 Certainly, you can use a flag --pkg to set your package name.
 		`),
-		cli.Run(func(c *cli.Command, args ...string) {
+		cli.RunE(func(c *cli.Command, args ...string) error {
 			// we at here to start create project
-			generate(args...)
+			return generate(args...)
 		}),
 	)
 )
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"go.zoe.im/x/cli"
+
+	"go.zoe.im/goser/codegen"
+)
+
+var docsOpts = struct {
+	output string
+	format string
+	seed   string
+}{}
+
+// docs command generates the API reference documentation
+var docsCmd = cli.New(
+	cli.Name("docs"),
+	cli.Short("Generate the API reference documentation."),
+	cli.Description(`Docs generates a static site in the output directory:
+
+    index       the API overview with the versions, the contact,
+                the license, the servers and the services
+    <service>   a page per service with its methods, their
+                endpoints, payloads, results, errors and examples
+                of HTTP and gRPC requests and responses
+    types       the enums and the models with their fields
+
+The pages are Markdown files, or HTML files with --format html.
+The examples given in spec are used, the other ones are generated
+from the seed like the mock server does so the responses of the
+documentation match the responses of "goser mock".
+The files are always regenerated, they must not be edited.
+	`),
+	cli.RunE(func(c *cli.Command, args ...string) error {
+		return genDocs(args...)
+	}),
+)
+
+func genDocs(paths ...string) error {
+	if docsOpts.format != "md" && docsOpts.format != "html" {
+		return fmt.Errorf("unknown format %q, must be md or html", docsOpts.format)
+	}
+	files, err := specPaths(paths...)
+	if err != nil {
+		return err
+	}
+	r, err := loadRuntime(files...)
+	if err != nil {
+		return err
+	}
+
+	var version string
+	if vers := r.Versions(); len(vers) > 0 {
+		version = vers[len(vers)-1]
+	}
+	pages := [][2]string{
+		{"index", codegen.DocsIndex(r.API(), r.Versions(), r.Services(), r.Servers())},
+		{"types", codegen.DocsTypes(r.Enums(), r.Models())},
+	}
+	for _, svc := range r.Services() {
+		if name := codegen.DocsServicePage(svc.Name); name == "index" || name == "types" {
+			return fmt.Errorf("service %s: the page %s is reserved", svc.Name, name)
+		}
+		pages = append(pages, [2]string{codegen.DocsServicePage(svc.Name), codegen.DocsService(svc, version, docsOpts.seed)})
+	}
+	gen := make([]*genFile, len(pages))
+	for i, page := range pages {
+		content := page[1]
+		if docsOpts.format == "html" {
+			content = codegen.DocsHTML(content)
+		}
+		gen[i] = &genFile{
+			Path:    filepath.Join(docsOpts.output, page[0]+"."+docsOpts.format),
+			Content: "<!-- Code generated by goser, DO NOT EDIT. -->\n" + content,
+		}
+	}
+	return writeFiles(gen...)
+}
+
+func init() {
+	docsCmd.Flags().StringVarP(&docsOpts.output, "output", "o", "docs", "output directory")
+	docsCmd.Flags().StringVarP(&docsOpts.format, "format", "f", "md", "format of the pages, md or html")
+	docsCmd.Flags().StringVarP(&docsOpts.seed, "seed", "s", "goser", "seed of the generated examples")
+	genCmd.Register(docsCmd)
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+
+	"go.zoe.im/x/cli"
+
+	"go.zoe.im/goser/codegen"
+	"go.zoe.im/goser/expr"
+)
+
+var exampleOpts = struct {
+	output string
+	pkg    string
+	path   string
+}{}
+
+// example command scaffolds the servers main packages and the services
+var exampleCmd = cli.New(
+	cli.Name("example"),
+	cli.Short("Generate the servers main packages and the services scaffolding."),
+	cli.Description(`Example generates a runnable main package in cmd/<server> for
+every server of the spec files, it listens on the HTTP and gRPC
+URIs of the host selected with --host and shuts down gracefully
+on SIGINT or SIGTERM. The main.go file is generated only if
+absent, server.go holds the specs, the hosts and the HTTP and
+gRPC handlers of the services, it is regenerated on every run.
+The gRPC methods are named /<service>.<Service>/<Method> and
+their messages are encoded with JSON.
+
+A <service>_impl.go file with method stubs returning not
+implemented errors is generated for every service. The stubs are
+generated only if absent, hand-written code is never overwritten.
+
+If the methods have security requirements, auth.go holds the auth
+hooks of the security schemes and the auth_impl.go scaffolding
+implements them, the servers authorize the requests with it.
+	`),
+	cli.RunE(func(c *cli.Command, args ...string) error {
+		return example(args...)
+	}),
+)
+
+func example(paths ...string) error {
+	files, err := specPaths(paths...)
+	if err != nil {
+		return err
+	}
+	r, err := loadRuntime(files...)
+	if err != nil {
+		return err
+	}
+	specs := make([]string, len(files))
+	for i, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		specs[i] = string(data)
+	}
+
+	pkg := exampleOpts.pkg
+	if pkg == "" {
+		pkg = packageName(exampleOpts.output)
+	}
+	pkgPath := exampleOpts.path
+	if pkgPath == "" {
+		if pkgPath, err = importPath(exampleOpts.output); err != nil {
+			return err
+		}
+	}
+
+	var (
+		gen  []*genFile
+		reqs [][]*expr.SecurityExpr
+	)
+	for _, svc := range r.Services() {
+		for _, m := range svc.Methods {
+			reqs = append(reqs, m.Security)
+		}
+		gen = append(gen, &genFile{
+			Path: filepath.Join(exampleOpts.output, codegen.SnakeCase(svc.Name)+"_impl.go"),
+			Content: goFile(scaffoldHeader, pkg, []string{
+				`"context"`, "",
+				`"go.zoe.im/goser/pkg/runtime"`,
+			}, codegen.GoServiceImpl(svc)),
+			Scaffold: true,
+		})
+	}
+	if schemes := expr.Schemes(reqs...); len(schemes) > 0 {
+		code := codegen.GoAuther(schemes)
+		imports := append(goImports(code), "", `"google.golang.org/grpc/metadata"`)
+		gen = append(gen,
+			&genFile{
+				Path:    filepath.Join(exampleOpts.output, "auth.go"),
+				Content: goFile(generatedHeader, pkg, imports, code),
+			},
+			&genFile{
+				Path:     filepath.Join(exampleOpts.output, "auth_impl.go"),
+				Content:  goFile(scaffoldHeader, pkg, []string{`"context"`}, codegen.GoAuthImpl(schemes)),
+				Scaffold: true,
+			},
+		)
+	}
+	for _, svr := range r.Servers() {
+		dir := filepath.Join(exampleOpts.output, "cmd", svr.Name)
+		handlers := codegen.GoExampleHandlers(svr, r.Services(), pkg, specs)
+		hosts := codegen.GoHosts(svr)
+		imports := append(goImports(handlers, hosts), "")
+		imports = append(imports, usedImports([]string{
+			`"go.zoe.im/x/cli"`, `"google.golang.org/grpc"`, `"google.golang.org/grpc/codes"`,
+			`"google.golang.org/grpc/encoding"`, `"google.golang.org/grpc/status"`,
+		}, handlers, hosts)...)
+		imports = append(imports, "", `"go.zoe.im/goser/pkg/runtime"`, pkg+" "+strconv.Quote(pkgPath))
+		gen = append(gen,
+			&genFile{
+				Path: filepath.Join(dir, "main.go"),
+				Content: goFile(scaffoldHeader, "main", []string{
+					`"context"`, `"fmt"`, `"net"`, `"net/http"`, `"net/url"`, `"os"`,
+					`"os/signal"`, `"syscall"`, `"time"`, "",
+					`"go.zoe.im/x/cli"`, `"google.golang.org/grpc"`,
+				}, codegen.GoServerMain(svr)),
+				Scaffold: true,
+			},
+			&genFile{
+				Path:    filepath.Join(dir, "server.go"),
+				Content: goFile(generatedHeader, "main", imports, handlers, hosts),
+			},
+		)
+	}
+	if len(gen) == 0 {
+		return fmt.Errorf("no service nor server found")
+	}
+	return writeFiles(gen...)
+}
+
+func init() {
+	exampleCmd.Flags().StringVarP(&exampleOpts.output, "output", "o", ".", "output directory")
+	exampleCmd.Flags().StringVar(&exampleOpts.pkg, "pkg", "", "package name of the services, default to the output directory name")
+	exampleCmd.Flags().StringVar(&exampleOpts.path, "import", "", "import path of the output directory, default to the path computed from go.mod")
+	genCmd.Register(exampleCmd)
+}
@@ -1,6 +1,374 @@
 package cmd
 
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.zoe.im/x/cli"
+
+	"go.zoe.im/goser/codegen"
+	"go.zoe.im/goser/expr"
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+var genOpts = struct {
+	output string
+	pkg    string
+	path   string
+}{}
+
+// generate writes the enums, the unions and the models of the spec files to
+// the output directory: the Go types, the proto messages, the OpenAPI document
+// and the SQL tables. The code of a versioned API is generated in a package
+// per version and the output package converts the models between the
+// consecutive versions.
 func generate(paths ...string) error {
+	files, err := specPaths(paths...)
+	if err != nil {
+		return err
+	}
+	r, err := loadRuntime(files...)
+	if err != nil {
+		return err
+	}
+	pkg := genOpts.pkg
+	if pkg == "" {
+		pkg = packageName(genOpts.output)
+	}
+	name := codegen.SnakeCase(r.API().Name)
+	if name == "" {
+		name = pkg
+	}
+
+	versions := r.Versions()
+	if len(versions) == 0 {
+		gen, err := genPackage(r, genOpts.output, pkg, name, name, "")
+		if err != nil {
+			return err
+		}
+		return writeFiles(gen...)
+	}
+
+	pkgPath := genOpts.path
+	if pkgPath == "" {
+		if pkgPath, err = importPath(genOpts.output); err != nil {
+			return err
+		}
+	}
+	var (
+		gen     []*genFile
+		imports []string
+		convs   []string
+		vrs     = make([]*runtime.Runtime, len(versions))
+	)
+	for i, v := range versions {
+		vpkg := codegen.VersionPackage(v)
+		vrs[i] = r.AtVersion(v)
+		vgen, err := genPackage(vrs[i], filepath.Join(genOpts.output, vpkg), vpkg, name, codegen.ProtoPackage(name, v), v)
+		if err != nil {
+			return err
+		}
+		gen = append(gen, vgen...)
+		imports = append(imports, strconv.Quote(pkgPath+"/"+vpkg))
+	}
+	for i := 1; i < len(versions); i++ {
+		for _, m := range vrs[i].Models() {
+			prev := vrs[i-1].Model(m.Name)
+			if prev == nil || !expr.IsObject(m.Type) {
+				continue
+			}
+			convs = append(convs,
+				codegen.GoConvert(prev.Type, m.Type, versions[i-1], versions[i]),
+				codegen.GoConvert(m.Type, prev.Type, versions[i], versions[i-1]),
+			)
+		}
+	}
+	if len(convs) > 0 {
+		gen = append(gen, &genFile{
+			Path:    filepath.Join(genOpts.output, "convert.go"),
+			Content: goFile(generatedHeader, pkg, usedImports(imports, convs...), convs...),
+		})
+	}
+	return writeFiles(gen...)
+}
+
+// genPackage returns the files of the package generated in dir for the
+// enums, the unions, the models and the errors of r: the Go types and errors,
+// the proto file, the OpenAPI document and the SQL tables.
+func genPackage(r *runtime.Runtime, dir, pkg, name, protoPkg, version string) ([]*genFile, error) {
+	var (
+		goCode  []string
+		protos  []string
+		tables  []string
+		schemas = make(map[string]interface{})
+	)
+	for _, e := range r.Enums() {
+		goCode = append(goCode, codegen.GoEnum(e))
+		protos = append(protos, codegen.ProtoEnum(e))
+		schemas[codegen.Goify(e.Name(), true)] = codegen.OpenAPIEnumSchema(e)
+	}
+	for _, u := range r.Unions() {
+		goCode = append(goCode, codegen.GoUnion(u))
+		protos = append(protos, codegen.ProtoUnion(u))
+		schemas[codegen.Goify(u.Name(), true)] = codegen.OpenAPIUnionSchema(u)
+	}
+	for _, m := range r.Models() {
+		if !expr.IsObject(m.Type) {
+			continue
+		}
+		goCode = append(goCode, codegen.GoModel(m.Type))
+		protos = append(protos, codegen.ProtoMessage(m.Type))
+		tables = append(tables, codegen.SQLTable(m.Type))
+		schemas[codegen.Goify(m.Name, true)] = codegen.OpenAPIModelSchema(m.Type)
+	}
+
+	var errs []*expr.ErrorExpr
+	for _, svc := range r.Services() {
+		for _, m := range svc.Methods {
+			errs = append(errs, m.Errors...)
+		}
+	}
+
+	doc, err := json.MarshalIndent(codegen.OpenAPIDocument(r.API(), version, r.Services(), schemas), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	gen := []*genFile{
+		{
+			Path:    filepath.Join(dir, "openapi.json"),
+			Content: string(doc) + "\n",
+		},
+	}
+	if len(goCode) > 0 {
+		gen = append(gen,
+			&genFile{
+				Path:    filepath.Join(dir, "types.go"),
+				Content: goFile(generatedHeader, pkg, goImports(goCode...), goCode...),
+			},
+			&genFile{
+				Path:    filepath.Join(dir, name+".proto"),
+				Content: "// Code generated by goser, DO NOT EDIT.\n\n" + codegen.ProtoFile(protoPkg, protos...),
+			},
+		)
+	}
+	if len(errs) > 0 {
+		code, err := codegen.GoErrors(errs)
+		if err != nil {
+			return nil, fmt.Errorf("version %s: %v", version, err)
+		}
+		gen = append(gen, &genFile{
+			Path:    filepath.Join(dir, "errors.go"),
+			Content: goFile(generatedHeader, pkg, goImports(code), code),
+		})
+	}
+	if len(tables) > 0 {
+		gen = append(gen, &genFile{
+			Path:    filepath.Join(dir, "schema.sql"),
+			Content: "-- Code generated by goser, DO NOT EDIT.\n\n" + strings.Join(tables, "\n"),
+		})
+	}
+	return gen, nil
+}
+
+// goPackages are the standard packages the generated code may use.
+var goPackages = []string{
+	"bytes", "context", "crypto/rand", "encoding/base64", "encoding/json", "errors",
+	"fmt", "io", "math/big", "net/http", "net/url", "strings", "time",
+}
+
+// goImports returns the imports of the standard packages used by the code.
+func goImports(code ...string) []string {
+	imports := make([]string, len(goPackages))
+	for i, p := range goPackages {
+		imports[i] = strconv.Quote(p)
+	}
+	return usedImports(imports, code...)
+}
+
+// usedImports returns the quoted imports whose packages are used by the code,
+// the packages are named after the last element of their paths.
+func usedImports(imports []string, code ...string) []string {
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package p\n\n"+strings.Join(code, "\n"), 0)
+	if err != nil {
+		// the code is reported invalid when formatted
+		return nil
+	}
+	used := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+	var res []string
+	for _, imp := range imports {
+		p, _ := strconv.Unquote(imp)
+		if used[path.Base(p)] {
+			res = append(res, imp)
+		}
+	}
+	return res
+}
 
+// gen command groups the code generators working on spec files
+var genCmd = cli.New(
+	cli.Name("gen"),
+	cli.Short("Generate code from spec files."),
+	cli.Description(`Gen groups the generators of code, clients and documentation
+working on the yaml spec files, see the sub commands.
+	`),
+)
+
+// genFile is a generated file
+type genFile struct {
+	// Path of the file
+	Path string
+	// Content of the file, Go files are formatted
+	Content string
+	// Scaffold files are hand-edited, they are generated only if
+	// absent and never overwritten
+	Scaffold bool
+}
+
+// writeFiles writes the generated files, the Go files are formatted first.
+// The scaffold files which already exist are skipped.
+func writeFiles(files ...*genFile) error {
+	for _, f := range files {
+		if f.Scaffold {
+			if _, err := os.Stat(f.Path); err == nil {
+				fmt.Printf("skip %s\n", f.Path)
+				continue
+			}
+		}
+		content := []byte(f.Content)
+		if filepath.Ext(f.Path) == ".go" {
+			src, err := format.Source(content)
+			if err != nil {
+				return fmt.Errorf("format %s: %v", f.Path, err)
+			}
+			content = src
+		}
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(f.Path, content, 0644); err != nil {
+			return err
+		}
+		fmt.Println(f.Path)
+	}
 	return nil
 }
+
+const (
+	// generatedHeader is the header of the generated Go files
+	generatedHeader = "// Code generated by goser, DO NOT EDIT."
+	// scaffoldHeader is the header of the scaffold Go files
+	scaffoldHeader = "// Code scaffolded by goser, edit as needed."
+)
+
+// goFile returns the content of a Go file with the given header, package
+// name, imports and code.
+func goFile(header, pkg string, imports []string, code ...string) string {
+	var b strings.Builder
+	b.WriteString(header + "\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	if len(imports) > 0 {
+		b.WriteString("import (\n")
+		for _, imp := range imports {
+			if imp == "" {
+				b.WriteString("\n")
+				continue
+			}
+			fmt.Fprintf(&b, "\t%s\n", imp)
+		}
+		b.WriteString(")\n\n")
+	}
+	b.WriteString(strings.Join(code, "\n"))
+	return b.String()
+}
+
+// specPaths returns the spec files of the paths, the directories are replaced
+// with the spec files they contain.
+func specPaths(paths ...string) ([]string, error) {
+	var res []string
+	for _, p := range paths {
+		files, err := specFiles(p)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, files...)
+	}
+	return res, nil
+}
+
+// importPath returns the Go import path of the directory computed from the
+// module path of the closest go.mod.
+func importPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for root := abs; ; root = filepath.Dir(root) {
+		data, err := ioutil.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			mod := modulePath(data)
+			if mod == "" {
+				return "", fmt.Errorf("no module path in %s", filepath.Join(root, "go.mod"))
+			}
+			rel, err := filepath.Rel(root, abs)
+			if err != nil {
+				return "", err
+			}
+			if rel == "." {
+				return mod, nil
+			}
+			return mod + "/" + filepath.ToSlash(rel), nil
+		}
+		if filepath.Dir(root) == root {
+			return "", fmt.Errorf("no go.mod found for %s, use --import", dir)
+		}
+	}
+}
+
+// modulePath returns the module path of the go.mod content.
+func modulePath(data []byte) string {
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// packageName returns the Go package name of the output directory.
+func packageName(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	name := strings.ToLower(filepath.Base(abs))
+	return strings.NewReplacer("-", "", ".", "", "_", "").Replace(name)
+}
+
+func init() {
+	cmd.Flags().StringVarP(&genOpts.output, "output", "o", ".", "output directory")
+	cmd.Flags().StringVar(&genOpts.pkg, "pkg", "", "package name of the generated code, default to the output directory name")
+	cmd.Flags().StringVar(&genOpts.path, "import", "", "import path of the output directory, default to the path computed from go.mod")
+	Register(genCmd)
+}
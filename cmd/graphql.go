@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"go.zoe.im/x/cli"
+
+	"go.zoe.im/goser/codegen"
+)
+
+var graphqlOpts = struct {
+	output string
+	pkg    string
+}{}
+
+// graphql command generates the GraphQL schema and resolvers of the services
+var graphqlCmd = cli.New(
+	cli.Name("graphql"),
+	cli.Short("Generate the GraphQL schema and resolvers of the services."),
+	cli.Description(`Graphql generates the schema.graphql file of the models, enums and
+the methods with the graphql:query or graphql:mutation meta, and
+the resolver.go file of the Go resolvers of the Query and Mutation
+fields. The Writable models are also GraphQL input types.
+
+The resolvers call the methods with the runtime so they are handled
+by the same service implementations as the HTTP and gRPC requests.
+The files are always regenerated, they must not be edited.
+	`),
+	cli.RunE(func(c *cli.Command, args ...string) error {
+		return genGraphQL(args...)
+	}),
+)
+
+func genGraphQL(paths ...string) error {
+	files, err := specPaths(paths...)
+	if err != nil {
+		return err
+	}
+	r, err := loadRuntime(files...)
+	if err != nil {
+		return err
+	}
+
+	fields := codegen.GraphQLFields(r.Services())
+	schema, err := codegen.GraphQLSchema(r.Enums(), r.Models(), fields)
+	if err != nil {
+		return err
+	}
+	pkg := graphqlOpts.pkg
+	if pkg == "" {
+		pkg = packageName(graphqlOpts.output)
+	}
+	return writeFiles(
+		&genFile{
+			Path:    filepath.Join(graphqlOpts.output, "schema.graphql"),
+			Content: "# Code generated by goser, DO NOT EDIT.\n\n" + schema + "\n",
+		},
+		&genFile{
+			Path: filepath.Join(graphqlOpts.output, "resolver.go"),
+			Content: goFile(generatedHeader, pkg, []string{
+				`"context"`, `"fmt"`, "",
+				`"go.zoe.im/goser/pkg/runtime"`,
+			}, codegen.GoGraphQLResolvers(fields)),
+		},
+	)
+}
+
+func init() {
+	graphqlCmd.Flags().StringVarP(&graphqlOpts.output, "output", "o", "graphql", "output directory")
+	graphqlCmd.Flags().StringVar(&graphqlOpts.pkg, "pkg", "", "package name of the resolvers, default to the output directory name")
+	genCmd.Register(graphqlCmd)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"log"
+	"net/http"
+
+	"go.zoe.im/x/cli"
+)
+
+var mockOpts = struct {
+	addr string
+	seed string
+}{}
+
+// mock command start a server answers with examples
+var mockCmd = cli.New(
+	cli.Name("mock"),
+	cli.Short("Start a mock server answers with examples of the spec files."),
+	cli.Description(`Mock exposes all methods described in the yaml spec files
+as HTTP/JSON endpoints, every request is answered with an
+example of the method result.
+
+The examples defined in spec are used when present, otherwise
+examples are generated from the seed, the same seed always
+generates the same responses.
+	`),
+	cli.RunE(func(c *cli.Command, args ...string) error {
+		return mock(args...)
+	}),
+)
+
+func mock(paths ...string) error {
+	r, err := loadRuntime(paths...)
+	if err != nil {
+		return err
+	}
+	log.Printf("mocking on %s with seed %q", mockOpts.addr, mockOpts.seed)
+	return http.ListenAndServe(mockOpts.addr, r.MockHandler(mockOpts.seed))
+}
+
+func init() {
+	mockCmd.Flags().StringVarP(&mockOpts.addr, "addr", "a", ":8080", "address to listen on")
+	mockCmd.Flags().StringVarP(&mockOpts.seed, "seed", "s", "goser", "seed of the generated examples")
+	Register(mockCmd)
+}
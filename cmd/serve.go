@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"go.zoe.im/x/cli"
+
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+var serveOpts = struct {
+	addr string
+}{}
+
+// serve command start a server from spec directly without generating code
+var serveCmd = cli.New(
+	cli.Name("serve"),
+	cli.Short("Serve the APIs described in spec files without code generation."),
+	cli.Description(`Serve loads the models and services from the yaml spec files,
+and exposes all methods as HTTP/JSON endpoints.
+
+Requests are validated against the models, and the methods
+named create, list, get, update and delete are handled by an
+in-memory store, which makes it easy to prototype APIs.
+	`),
+	cli.RunE(func(c *cli.Command, args ...string) error {
+		return serve(args...)
+	}),
+)
+
+func serve(paths ...string) error {
+	r, err := loadRuntime(paths...)
+	if err != nil {
+		return err
+	}
+	log.Printf("serving on %s", serveOpts.addr)
+	return http.ListenAndServe(serveOpts.addr, r.Handler())
+}
+
+// loadRuntime loads all spec files to a runtime
+func loadRuntime(paths ...string) (*runtime.Runtime, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("at least one spec file is required")
+	}
+	r := runtime.New()
+	for _, p := range paths {
+		spec, err := runtime.LoadSpec(p)
+		if err != nil {
+			return nil, fmt.Errorf("load %s: %v", p, err)
+		}
+		if err := r.Load(spec); err != nil {
+			return nil, fmt.Errorf("load %s: %v", p, err)
+		}
+	}
+	return r, nil
+}
+
+func init() {
+	serveCmd.Flags().StringVarP(&serveOpts.addr, "addr", "a", ":8080", "address to listen on")
+	Register(serveCmd)
+}
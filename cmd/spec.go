@@ -1 +1,109 @@
 package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+// specFiles returns the yaml spec files of the directory sorted by name, the
+// path itself if it's a file.
+func specFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	infos, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, info := range infos {
+		if !info.IsDir() && isSpecFile(info.Name()) {
+			files = append(files, filepath.Join(path, info.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no spec file found in %s", path)
+	}
+	return files, nil
+}
+
+// isSpecFile returns true if the file name has a yaml extension.
+func isSpecFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// loadDir loads the spec files of the directory or the spec file to a runtime.
+func loadDir(path string) (*runtime.Runtime, error) {
+	files, err := specFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	return loadRuntime(files...)
+}
+
+// loadRef loads the spec files of the directory or the spec file at path from
+// the git revision ref, path is the path in the working tree.
+func loadRef(ref, path string) (*runtime.Runtime, error) {
+	out, err := git("ls-tree", "-r", "--name-only", ref, "--", path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range strings.Split(strings.TrimSpace(out), "\n") {
+		if f != "" && isSpecFile(f) {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no spec file found in %s at %s", path, ref)
+	}
+	sort.Strings(files)
+	r := runtime.New()
+	for _, f := range files {
+		data, err := git("show", ref+":./"+filepath.ToSlash(f))
+		if err != nil {
+			return nil, err
+		}
+		spec, err := runtime.ParseSpec([]byte(data))
+		if err != nil {
+			return nil, fmt.Errorf("load %s at %s: %v", f, ref, err)
+		}
+		if err := r.Load(spec); err != nil {
+			return nil, fmt.Errorf("load %s at %s: %v", f, ref, err)
+		}
+	}
+	return r, nil
+}
+
+// loadDirOrRef loads the directory or spec file if it exists, otherwise src
+// is considered as a git revision the spec files at path are loaded from.
+func loadDirOrRef(src, path string) (*runtime.Runtime, error) {
+	if _, err := os.Stat(src); err == nil {
+		return loadDir(src)
+	}
+	return loadRef(src, path)
+}
+
+// git runs a git command and returns its output.
+func git(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	c := exec.Command("git", args...)
+	c.Stdout, c.Stderr = &stdout, &stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
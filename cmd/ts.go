@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"go.zoe.im/x/cli"
+
+	"go.zoe.im/goser/codegen"
+	"go.zoe.im/goser/expr"
+)
+
+var tsOpts = struct {
+	output string
+}{}
+
+// ts command generates the TypeScript types and clients of the services
+var tsCmd = cli.New(
+	cli.Name("ts"),
+	cli.Short("Generate the TypeScript types and clients of the services."),
+	cli.Description(`Ts generates a TypeScript module in the output directory:
+
+    types.ts            the interfaces of the models, the enums and
+                        their validate<Name> functions
+    client.ts           the client options, errors and fetch calls
+    <service>_client.ts a client class per service
+    index.ts            re-exports all the above
+
+The payloads are validated before the requests are sent, the
+errors declared by the methods are thrown as ServiceError values.
+The clients of a versioned API call the latest version, the paths
+are prefixed with it.
+The files are always regenerated, they must not be edited.
+	`),
+	cli.RunE(func(c *cli.Command, args ...string) error {
+		return genTS(args...)
+	}),
+)
+
+func genTS(paths ...string) error {
+	files, err := specPaths(paths...)
+	if err != nil {
+		return err
+	}
+	r, err := loadRuntime(files...)
+	if err != nil {
+		return err
+	}
+
+	var types []expr.UserType
+	for _, e := range r.Enums() {
+		types = append(types, e)
+	}
+	for _, m := range r.Models() {
+		types = append(types, m.Type)
+	}
+	tsTypes := codegen.TSTypes(types)
+	for _, u := range r.Unions() {
+		tsTypes += "\n" + codegen.TSUnion(u)
+	}
+	gen := []*genFile{
+		{Path: filepath.Join(tsOpts.output, "types.ts"), Content: tsFile(tsTypes)},
+		{Path: filepath.Join(tsOpts.output, "client.ts"), Content: tsFile(codegen.TSClient())},
+	}
+	var version string
+	if vers := r.Versions(); len(vers) > 0 {
+		version = vers[len(vers)-1]
+	}
+	modules := []string{"./types", "./client"}
+	for _, svc := range r.Services() {
+		name := codegen.SnakeCase(svc.Name) + "_client"
+		gen = append(gen, &genFile{
+			Path:    filepath.Join(tsOpts.output, name+".ts"),
+			Content: tsFile(codegen.TSServiceClient(svc, version)),
+		})
+		modules = append(modules, "./"+name)
+	}
+	var index strings.Builder
+	for _, m := range modules {
+		fmt.Fprintf(&index, "export * from %q;\n", m)
+	}
+	gen = append(gen, &genFile{Path: filepath.Join(tsOpts.output, "index.ts"), Content: tsFile(index.String())})
+	return writeFiles(gen...)
+}
+
+// tsFile returns the content of a generated TypeScript file.
+func tsFile(code string) string {
+	return generatedHeader + "\n\n" + code
+}
+
+func init() {
+	tsCmd.Flags().StringVarP(&tsOpts.output, "output", "o", "ts", "output directory")
+	genCmd.Register(tsCmd)
+}
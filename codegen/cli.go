@@ -0,0 +1,386 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+// GoCLI returns the Go code of the client commands of the services hosted by
+// the server: a command per service with a subcommand per method. The method
+// payload is read from the --body flag as JSON and the fields of object
+// payloads are set with a flag each, the flags override the body fields. The
+// method is called with the transport selected with --transport and the
+// result is printed as indented JSON. The gRPC calls use a JSON codec and the
+// /<service>.<Service>/<Method> method names of the services registered by
+// GoExampleHandlers. The services of a versioned API are the services at the
+// version called by the client whose routes are prefixed with it, see
+// runtime.Runtime.AtVersion. The package must define the code
+// generated by GoClientMain and GoHosts. The generated code uses the bytes,
+// context, crypto/tls, encoding/json, fmt, io/ioutil, net/http, net/url, os,
+// strconv, strings, go.zoe.im/x/cli, google.golang.org/grpc,
+// google.golang.org/grpc/credentials and google.golang.org/grpc/encoding
+// packages.
+func GoCLI(svr *expr.ServerExpr, services []*runtime.Service) string {
+	var b strings.Builder
+	for _, svc := range HostedServices(svr, services) {
+		if len(svc.Methods) == 0 {
+			continue
+		}
+		writeGoCLIService(&b, svc)
+		b.WriteString("\n")
+	}
+	b.WriteString(goCall)
+	return b.String()
+}
+
+// writeGoCLIService writes the function registering the command of the service.
+func writeGoCLIService(b *strings.Builder, svc *runtime.Service) {
+	fn := "register" + Goify(svc.Name, true) + "Commands"
+	fmt.Fprintf(b, "func init() {\n\tcommands = append(commands, %s)\n}\n\n", fn)
+	fmt.Fprintf(b, "// %s registers the %s service command and its methods\n// subcommands.\n", fn, svc.Name)
+	fmt.Fprintf(b, "func %s(root *cli.Command) {\n", fn)
+	fmt.Fprintf(b, "\tsvc := cli.New(\n\t\tcli.Name(%q),\n\t\tcli.Short(%q),\n\t)\n", svc.Name, cliShort(svc.Description, "Call the "+svc.Name+" service methods."))
+	for _, m := range svc.Methods {
+		writeGoCLIMethod(b, svc, m)
+	}
+	b.WriteString("\troot.Register(svc)\n}\n")
+}
+
+// writeGoCLIMethod writes the block registering the subcommand of the method.
+func writeGoCLIMethod(b *strings.Builder, svc *runtime.Service, m *runtime.Method) {
+	var fields []*expr.NamedAttributeExpr
+	if m.Payload != nil {
+		if obj := expr.AsObject(m.Payload.Type); obj != nil {
+			fields = *obj
+		}
+	}
+	b.WriteString("\t{\n\t\tvar body string\n")
+	if len(fields) > 0 {
+		b.WriteString("\t\tvar (\n")
+		for _, f := range fields {
+			fmt.Fprintf(b, "\t\t\t%s string\n", cliVar(f.Name))
+		}
+		b.WriteString("\t\t)\n")
+	}
+	fmt.Fprintf(b, "\t\tcmd := cli.New(\n\t\t\tcli.Name(%q),\n\t\t\tcli.Short(%q),\n", m.Name, cliShort(m.Description, "Call the "+m.Name+" method."))
+	b.WriteString("\t\t\tcli.Run(func(c *cli.Command, args ...string) {\n")
+	fmt.Fprintf(b, "\t\t\t\tcall(&method{\n\t\t\t\t\tservice: %q,\n\t\t\t\t\tname:    %q,\n\t\t\t\t\tverb:    %q,\n\t\t\t\t\tpath:    %q,\n\t\t\t\t\tgrpc:    %q,\n\t\t\t\t}, body, []*field{",
+		svc.Name, m.Name, m.Route.Method, m.Route.Path, GoGRPCMethodName(svc.Name, m.Name))
+	if len(fields) > 0 {
+		b.WriteString("\n")
+		for _, f := range fields {
+			fmt.Fprintf(b, "\t\t\t\t\t{name: %q, flag: %q, kind: %q, value: %s},\n", f.Name, cliFlag(f.Name), cliKind(f.Attribute.Type), cliVar(f.Name))
+		}
+		b.WriteString("\t\t\t\t")
+	}
+	b.WriteString("})\n\t\t\t}),\n\t\t)\n")
+	b.WriteString("\t\taddClientFlags(cmd)\n")
+	b.WriteString("\t\tcmd.Flags().StringVar(&body, \"body\", \"\", \"JSON payload, the flags override its fields\")\n")
+	for _, f := range fields {
+		fmt.Fprintf(b, "\t\tcmd.Flags().StringVar(&%s, %q, \"\", %q)\n", cliVar(f.Name), cliFlag(f.Name), cliUsage(m.Payload, f))
+	}
+	b.WriteString("\t\tsvc.Register(cmd)\n\t}\n")
+}
+
+// GoGRPCMethodName returns the full gRPC name of the service method.
+func GoGRPCMethodName(service, method string) string {
+	return fmt.Sprintf("/%s/%s", GoGRPCServiceName(service), Goify(method, true))
+}
+
+// GoGRPCServiceName returns the gRPC name of the service.
+func GoGRPCServiceName(service string) string {
+	return SnakeCase(service) + "." + Goify(service, true)
+}
+
+// cliShort returns the first line of the description or def if empty.
+func cliShort(desc, def string) string {
+	if desc == "" {
+		return def
+	}
+	if i := strings.IndexByte(desc, '\n'); i >= 0 {
+		desc = desc[:i]
+	}
+	return strings.TrimSpace(desc)
+}
+
+// cliVar returns the name of the variable holding the value of the field flag.
+func cliVar(name string) string {
+	return Goify(name, false) + "Flag"
+}
+
+// cliFlag returns the name of the flag of the field, the fields named after
+// the client flags are prefixed with "field-".
+func cliFlag(name string) string {
+	flag := strings.Replace(SnakeCase(name), "_", "-", -1)
+	switch flag {
+	case "body", "host", "var", "transport", "help":
+		return "field-" + flag
+	}
+	return flag
+}
+
+// cliKind returns the kind used by the generated code to parse the flag
+// values: boolean, integer, number, string or json. The time, decimal and
+// big integer values are strings and the enum values are parsed according to
+// their base type.
+func cliKind(dt expr.DataType) string {
+	if e := expr.AsEnum(dt); e != nil {
+		dt = e.Base()
+	}
+	switch dt.Kind() {
+	case expr.BooleanKind:
+		return "boolean"
+	case expr.IntKind, expr.Int32Kind, expr.Int64Kind,
+		expr.UIntKind, expr.UInt32Kind, expr.UInt64Kind:
+		return "integer"
+	case expr.Float32Kind, expr.Float64Kind:
+		return "number"
+	case expr.StringKind, expr.BytesKind, expr.TimestampKind, expr.DateKind,
+		expr.DurationKind, expr.DecimalKind, expr.BigIntKind:
+		return "string"
+	}
+	return "json"
+}
+
+// cliUsage returns the usage of the field flag: the description followed by
+// the type, the enum values, the default value and whether the field is
+// required.
+func cliUsage(payload *expr.AttributeExpr, f *expr.NamedAttributeExpr) string {
+	att := f.Attribute
+	usage := att.Description
+	if usage == "" {
+		usage = f.Name
+	}
+	details := []string{att.Type.Name()}
+	if cliKind(att.Type) == "json" {
+		details[0] += " as JSON"
+	}
+	var values []string
+	if e := expr.AsEnum(att.Type); e != nil {
+		for _, v := range e.Values {
+			values = append(values, fmt.Sprintf("%v", v.Value))
+		}
+	}
+	if att.Validation != nil {
+		for _, v := range att.Validation.Values {
+			values = append(values, fmt.Sprintf("%v", v))
+		}
+	}
+	if len(values) > 0 {
+		details = append(details, "one of "+strings.Join(values, ", "))
+	}
+	if att.DefaultValue != nil {
+		def := fmt.Sprintf("%v", att.DefaultValue)
+		if cliKind(att.Type) == "json" {
+			if js, err := json.Marshal(att.DefaultValue); err == nil {
+				def = string(js)
+			}
+		}
+		details = append(details, "default "+def)
+	}
+	if payload.IsRequired(f.Name) {
+		details = append(details, "required")
+	}
+	return fmt.Sprintf("%s (%s)", usage, strings.Join(details, ", "))
+}
+
+// goCall is the Go code of the client calling the methods independent of the
+// services.
+const goCall = `
+// method is a service method called by the client.
+type method struct {
+	// service is the name of the service
+	service string
+	// name is the name of the method
+	name string
+	// verb and path of the HTTP route
+	verb, path string
+	// grpc is the full gRPC method name
+	grpc string
+}
+
+// field is a payload field set with a flag.
+type field struct {
+	// name of the field
+	name string
+	// flag setting the field
+	flag string
+	// kind of the value: boolean, integer, number, string or json
+	kind string
+	// value of the flag, empty if not set
+	value string
+}
+
+// parse returns the field value parsed according to its kind.
+func (f *field) parse() (interface{}, error) {
+	switch f.kind {
+	case "boolean":
+		return strconv.ParseBool(f.value)
+	case "integer":
+		return strconv.ParseInt(f.value, 10, 64)
+	case "number":
+		return strconv.ParseFloat(f.value, 64)
+	case "json":
+		var v interface{}
+		err := json.Unmarshal([]byte(f.value), &v)
+		return v, err
+	}
+	return f.value, nil
+}
+
+// call calls the method with the payload built from the body and the fields
+// and prints the result, the process exits on error.
+func call(m *method, body string, fields []*field) {
+	res, err := invoke(m, body, fields)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if res == nil {
+		return
+	}
+	js, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(string(js))
+}
+
+// invoke calls the method with the selected transport and returns the result.
+func invoke(m *method, body string, fields []*field) (interface{}, error) {
+	p, err := payload(body, fields)
+	if err != nil {
+		return nil, err
+	}
+	u, err := endpoint()
+	if err != nil {
+		return nil, err
+	}
+	if flags.transport == "grpc" {
+		return invokeGRPC(u, m, p)
+	}
+	return invokeHTTP(u, m, p)
+}
+
+// payload returns the payload decoded from the JSON body with the fields set
+// with flags.
+func payload(body string, fields []*field) (interface{}, error) {
+	var p interface{}
+	if body != "" {
+		if err := json.Unmarshal([]byte(body), &p); err != nil {
+			return nil, fmt.Errorf("invalid --body: %v", err)
+		}
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		obj, ok := p.(map[string]interface{})
+		if !ok {
+			if p != nil {
+				return nil, fmt.Errorf("--body must be a JSON object to use --%s", f.flag)
+			}
+			obj = map[string]interface{}{}
+			p = obj
+		}
+		v, err := f.parse()
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s: %v", f.flag, err)
+		}
+		obj[f.name] = v
+	}
+	return p, nil
+}
+
+// invokeHTTP sends the HTTP request of the method, the route parameters are
+// set with the payload fields of the same name.
+func invokeHTTP(u *url.URL, m *method, p interface{}) (interface{}, error) {
+	path := m.path
+	if obj, ok := p.(map[string]interface{}); ok {
+		for k, v := range obj {
+			path = strings.Replace(path, "{"+k+"}", url.PathEscape(fmt.Sprintf("%v", v)), -1)
+		}
+	}
+	target := *u
+	target.Path = strings.TrimSuffix(target.Path, "/") + path
+	var body bytes.Buffer
+	if p != nil {
+		if err := json.NewEncoder(&body).Encode(p); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequest(m.verb, target.String(), &body)
+	if err != nil {
+		return nil, err
+	}
+	if p != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var res interface{}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &res); err != nil {
+			return nil, fmt.Errorf("%s.%s: %s: %s", m.service, m.name, resp.Status, data)
+		}
+	}
+	if resp.StatusCode >= 400 {
+		js, _ := json.MarshalIndent(res, "", "  ")
+		return nil, fmt.Errorf("%s.%s: %s\n%s", m.service, m.name, resp.Status, js)
+	}
+	return res, nil
+}
+
+// invokeGRPC calls the gRPC method, the messages are encoded with JSON.
+func invokeGRPC(u *url.URL, m *method, p interface{}) (interface{}, error) {
+	opt := grpc.WithInsecure()
+	if u.Scheme == "grpcs" {
+		opt = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))
+	}
+	conn, err := grpc.Dial(dialAddr(u), opt)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var res interface{}
+	if err := conn.Invoke(context.Background(), m.grpc, p, &res, grpc.CallContentSubtype(jsonCodec{}.Name())); err != nil {
+		return nil, fmt.Errorf("%s.%s: %v", m.service, m.name, err)
+	}
+	return res, nil
+}
+
+// dialAddr returns the address of the gRPC URI, the port defaults to 8080 or
+// 8443 for the grpc and grpcs schemes.
+func dialAddr(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	if u.Scheme == "grpcs" {
+		return u.Host + ":8443"
+	}
+	return u.Host + ":8080"
+}
+
+// jsonCodec encodes the gRPC messages with JSON.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return "json" }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+`
@@ -0,0 +1,133 @@
+package codegen
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"go.zoe.im/goser/expr"
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+func TestGoCLI(t *testing.T) {
+	spec, err := runtime.ParseSpec([]byte(`
+versions: [v1, v2]
+enums:
+  Role:
+    values: [admin, guest]
+  Level:
+    type: int32
+    values: [1, 2]
+models:
+  User:
+    fields:
+      - name: name
+        type: string
+        description: Name of the user
+        required: true
+      - name: age
+        type: int32
+        default: 18
+      - name: tags
+        type: array<string>
+      - name: role
+        type: Role
+      - name: level
+        type: Level
+      - name: born_at
+        type: timestamp
+      - name: balance
+        type: decimal
+      - name: nickname
+        type: string
+        until: v1
+services:
+  users:
+    description: Manages the users.
+    methods:
+      create:
+        description: Create a user.
+        payload: User
+        result: User
+        http: POST /users
+      ping: {}
+  teams:
+    methods:
+      list:
+        result: array<User>
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := runtime.New()
+	if err := r.Load(spec); err != nil {
+		t.Fatal(err)
+	}
+	svr := &expr.ServerExpr{
+		Name:     "usersvr",
+		Services: []string{"users"},
+		Hosts:    []*expr.HostExpr{{Name: "dev", URIs: []expr.URIExpr{"grpc://localhost:8080"}}},
+	}
+	code := GoCLI(svr, r.AtVersion("v2").Services())
+	main := GoClientMain(svr)
+
+	cases := map[string]struct {
+		code        string
+		contains    []string
+		notContains []string
+	}{
+		"commands": {code, []string{
+			"\tcommands = append(commands, registerUsersCommands)\n",
+			"cli.Name(\"users\"),\n\t\tcli.Short(\"Manages the users.\"),",
+			"cli.Name(\"create\"),\n\t\t\tcli.Short(\"Create a user.\"),",
+			"verb:    \"POST\",\n\t\t\t\t\tpath:    \"/v2/users\",\n\t\t\t\t\tgrpc:    \"/users.Users/Create\",",
+			"{name: \"age\", flag: \"age\", kind: \"integer\", value: ageFlag},",
+			"{name: \"tags\", flag: \"tags\", kind: \"json\", value: tagsFlag},",
+			"{name: \"role\", flag: \"role\", kind: \"string\", value: roleFlag},",
+			"{name: \"level\", flag: \"level\", kind: \"integer\", value: levelFlag},",
+			"{name: \"born_at\", flag: \"born-at\", kind: \"string\", value: bornAtFlag},",
+			"{name: \"balance\", flag: \"balance\", kind: \"string\", value: balanceFlag},",
+			"cmd.Flags().StringVar(&roleFlag, \"role\", \"\", \"role (Role, one of admin, guest)\")",
+			"cmd.Flags().StringVar(&nameFlag, \"name\", \"\", \"Name of the user (string, required)\")",
+			"cmd.Flags().StringVar(&ageFlag, \"age\", \"\", \"age (int32, default 18)\")",
+			"cmd.Flags().StringVar(&tagsFlag, \"tags\", \"\", \"tags (array as JSON)\")",
+			"}, body, []*field{})",
+			"encoding.RegisterCodec(jsonCodec{})",
+		}, []string{"teams", "nickname"}},
+		"main": {main, []string{
+			"func addClientFlags(c *cli.Command) {\n\taddHostFlags(c, &flags.hostFlags)\n",
+			"\"transport\", \"t\", \"grpc\",",
+		}, nil},
+	}
+	for k, tc := range cases {
+		if _, err := format.Source([]byte("package p\n\n" + tc.code)); err != nil {
+			t.Errorf("%s: invalid Go code: %v\n%s", k, err, tc.code)
+		}
+		for _, s := range tc.contains {
+			if !strings.Contains(tc.code, s) {
+				t.Errorf("%s: got\n%s\nexpected it to contain\n%s", k, tc.code, s)
+			}
+		}
+		for _, s := range tc.notContains {
+			if strings.Contains(tc.code, s) {
+				t.Errorf("%s: got\n%s\nexpected it not to contain %s", k, tc.code, s)
+			}
+		}
+	}
+}
+
+func TestCLIFlag(t *testing.T) {
+	cases := map[string]struct {
+		name     string
+		expected string
+	}{
+		"simple":   {"name", "name"},
+		"camel":    {"firstName", "first-name"},
+		"reserved": {"host", "field-host"},
+	}
+	for k, tc := range cases {
+		if got := cliFlag(tc.name); got != tc.expected {
+			t.Errorf("%s: got %q, expected %q", k, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package codegen
+
+import (
+	"strings"
+
+	"go.zoe.im/goser/expr"
+)
+
+// GoDeprecation returns the "Deprecated:" paragraph of the Go doc comment of
+// the element with the given meta, empty if the element is not deprecated.
+func GoDeprecation(meta expr.MetaExpr) string {
+	d := expr.Deprecation(meta)
+	if d == nil {
+		return ""
+	}
+	msg := d.Reason
+	if d.Since != "" {
+		if msg != "" {
+			msg += " "
+		}
+		msg += "(since " + d.Since + ")"
+	}
+	if msg == "" {
+		msg = "do not use."
+	}
+	return "Deprecated: " + msg
+}
+
+// ProtoDeprecatedOption returns the option of the proto field or enum value
+// with the given meta, empty if the element is not deprecated.
+func ProtoDeprecatedOption(meta expr.MetaExpr) string {
+	if expr.Deprecation(meta) == nil {
+		return ""
+	}
+	return " [deprecated = true]"
+}
+
+// writeDoc writes the description and the deprecation of the element with
+// the given meta as a Go doc comment, def is used if the description is
+// empty.
+func writeDoc(b *strings.Builder, indent, desc, def string, meta expr.MetaExpr) {
+	if desc == "" {
+		desc = def
+	}
+	if dep := GoDeprecation(meta); dep != "" {
+		if desc != "" {
+			desc += "\n\n"
+		}
+		desc += dep
+	}
+	writeComment(b, indent, desc, "")
+}
@@ -0,0 +1,67 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"go.zoe.im/goser/expr"
+)
+
+func TestDeprecation(t *testing.T) {
+	status := expr.NewEnumTypeExpr("status", expr.String,
+		&expr.EnumValueExpr{Value: "active"},
+		&expr.EnumValueExpr{Value: "banned", Description: "Banned user",
+			Meta: expr.Deprecate(nil, "use disabled instead", "v2")},
+	)
+	status.Meta = expr.Deprecate(status.Meta, "", "")
+	user := &expr.UserTypeExpr{
+		TypeName: "user",
+		AttributeExpr: &expr.AttributeExpr{
+			Type: &expr.Object{
+				{Name: "name", Attribute: &expr.AttributeExpr{Type: expr.String}},
+				{Name: "nick", Attribute: &expr.AttributeExpr{Type: expr.String, Meta: expr.Deprecate(nil, "use name", "")}},
+			},
+			Meta: expr.Deprecate(nil, "use account", "v3"),
+		},
+	}
+
+	cases := map[string]struct {
+		code     string
+		contains []string
+	}{
+		"go": {GoEnum(status), []string{
+			"// Status is the status enum.\n//\n// Deprecated: do not use.\ntype Status string",
+			"\t// Banned user\n\t//\n\t// Deprecated: use disabled instead (since v2)\n\tStatusBanned Status",
+		}},
+		"proto": {ProtoEnum(status), []string{
+			"enum Status {\n  option deprecated = true;\n",
+			"  STATUS_ACTIVE = 1;\n",
+			"  STATUS_BANNED = 2 [deprecated = true];\n",
+		}},
+		"go model": {GoModel(user), []string{
+			"// User is the user model.\n//\n// Deprecated: use account (since v3)\ntype User struct",
+			"\t// Deprecated: use name\n\tNick string",
+		}},
+		"proto message": {ProtoMessage(user), []string{
+			"message User {\n  option deprecated = true;\n",
+			"  string name = 1;\n",
+			"  string nick = 2 [deprecated = true];\n",
+		}},
+		"sql": {SQLTable(user), []string{
+			"-- Deprecated: use account (since v3)\nCREATE TABLE \"user\" (",
+		}},
+	}
+	for k, tc := range cases {
+		for _, s := range tc.contains {
+			if !strings.Contains(tc.code, s) {
+				t.Errorf("%s: got\n%s\nexpected it to contain\n%s", k, tc.code, s)
+			}
+		}
+	}
+	if schema := OpenAPIEnumSchema(status); schema["deprecated"] != true {
+		t.Errorf("got %#v, expected deprecated schema", schema)
+	}
+	if schema := OpenAPIModelSchema(user); schema["deprecated"] != true {
+		t.Errorf("got %#v, expected deprecated schema", schema)
+	}
+}
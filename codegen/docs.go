@@ -0,0 +1,710 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+// DocsIndex returns the Markdown of the overview page of the API reference:
+// the API information, the versions, the servers and the links to the pages
+// of the services and of the types.
+func DocsIndex(api *runtime.API, versions []string, services []*runtime.Service, servers []*expr.ServerExpr) string {
+	var b strings.Builder
+	title := api.Name
+	if title == "" {
+		title = "API"
+	}
+	fmt.Fprintf(&b, "# %s reference\n\n", title)
+	writeDocsParagraph(&b, api.Description)
+	writeDocsLink(&b, api.Docs)
+
+	var info []string
+	if len(versions) > 0 {
+		info = append(info, fmt.Sprintf("- Versions: %s, the HTTP routes are prefixed with the version, e.g. `/%s`", docsCodes(versions), versions[len(versions)-1]))
+	}
+	if api.TermsOfService != "" {
+		terms := api.TermsOfService
+		if docsURL(terms) {
+			terms = fmt.Sprintf("<%s>", terms)
+		}
+		info = append(info, "- Terms of service: "+terms)
+	}
+	if c := api.Contact; c != nil {
+		contact := c.Name
+		if c.URL != "" {
+			contact = fmt.Sprintf("[%s](%s)", docsDefault(c.Name, c.URL), c.URL)
+		}
+		if c.Email != "" {
+			contact = strings.TrimSpace(fmt.Sprintf("%s <%s>", contact, c.Email))
+		}
+		info = append(info, "- Contact: "+contact)
+	}
+	if l := api.License; l != nil {
+		license := l.Name
+		if l.URL != "" {
+			license = fmt.Sprintf("[%s](%s)", docsDefault(l.Name, l.URL), l.URL)
+		}
+		info = append(info, "- License: "+license)
+	}
+	if len(info) > 0 {
+		b.WriteString(strings.Join(info, "\n") + "\n\n")
+	}
+
+	if len(servers) > 0 {
+		b.WriteString("## Servers\n\n")
+		for _, svr := range servers {
+			fmt.Fprintf(&b, "### %s\n\n", svr.Name)
+			writeDocsParagraph(&b, svr.Description)
+			if len(svr.Services) > 0 {
+				links := make([]string, len(svr.Services))
+				for i, s := range svr.Services {
+					links[i] = fmt.Sprintf("[%s](%s.md)", s, DocsServicePage(s))
+				}
+				fmt.Fprintf(&b, "Services: %s\n\n", strings.Join(links, ", "))
+			}
+			b.WriteString("| Host | URIs | Description |\n| --- | --- | --- |\n")
+			for _, h := range svr.Hosts {
+				uris := make([]string, len(h.URIs))
+				for i, u := range h.URIs {
+					uris[i] = string(u)
+				}
+				fmt.Fprintf(&b, "| %s | %s | %s |\n", h.Name, docsCodes(uris), docsCell(h.Description))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("## Services\n\n| Service | Description |\n| --- | --- |\n")
+	for _, svc := range services {
+		fmt.Fprintf(&b, "| [%s](%s.md) | %s |\n", svc.Name, DocsServicePage(svc.Name), docsCell(docsSummary(svc.Description, svc.Meta)))
+	}
+	b.WriteString("\nThe payloads and results are described in [Types](types.md).\n\n")
+
+	b.WriteString("## Errors\n\n")
+	b.WriteString("The errors are returned with the HTTP status and the gRPC code listed by the methods, the HTTP response body is:\n\n")
+	b.WriteString("```json\n{\n  \"name\": \"not_found\",\n  \"id\": \"3F1FKVRR\",\n  \"message\": \"user not found\",\n  \"temporary\": false,\n  \"timeout\": false,\n  \"fault\": false\n}\n```\n")
+	return b.String()
+}
+
+// DocsService returns the Markdown of the page of the service: the methods
+// with their endpoints, payload and result tables, errors and examples of
+// requests and responses for the HTTP and gRPC transports. The HTTP paths
+// of the examples are prefixed with the version if not empty, the examples
+// are generated with the seed like the mock server does if not given in spec.
+func DocsService(svc *runtime.Service, version, seed string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s service\n\n", svc.Name)
+	writeDocsMeta(&b, svc.Meta)
+	writeDocsParagraph(&b, svc.Description)
+	writeDocsLink(&b, svc.Docs)
+	b.WriteString("[Overview](index.md) · [Types](types.md)\n\n")
+
+	b.WriteString("## Methods\n\n| Method | Description |\n| --- | --- |\n")
+	for _, m := range svc.Methods {
+		fmt.Fprintf(&b, "| [%s](#%s) | %s |\n", m.Name, docsAnchor(m.Name), docsCell(docsSummary(m.Description, m.Meta)))
+	}
+	for _, m := range svc.Methods {
+		b.WriteString("\n")
+		writeDocsMethod(&b, m, version, seed)
+	}
+	return b.String()
+}
+
+// DocsServicePage returns the name of the page of the service without the
+// extension.
+func DocsServicePage(service string) string {
+	return SnakeCase(service)
+}
+
+// writeDocsMethod writes the section of the method.
+func writeDocsMethod(b *strings.Builder, m *runtime.Method, version, seed string) {
+	fmt.Fprintf(b, "## %s\n\n", m.Name)
+	writeDocsMeta(b, m.Meta)
+	writeDocsParagraph(b, m.Description)
+	writeDocsLink(b, m.Docs)
+	b.WriteString("| Transport | Endpoint |\n| --- | --- |\n")
+	fmt.Fprintf(b, "| HTTP | `%s %s` |\n", m.Route.Method, docsPath(m.Route.Path, version))
+	fmt.Fprintf(b, "| gRPC | `%s` |\n\n", GoGRPCMethodName(m.Service, m.Name))
+
+	b.WriteString("### Payload\n\n")
+	writeDocsFields(b, m.Payload)
+	b.WriteString("### Result\n\n")
+	writeDocsFields(b, m.Result)
+
+	if len(m.Errors) > 0 {
+		b.WriteString("### Errors\n\n| Name | HTTP status | gRPC code | Description |\n| --- | --- | --- | --- |\n")
+		for _, e := range m.Errors {
+			var flags []string
+			if e.IsTemporary() {
+				flags = append(flags, "temporary")
+			}
+			if e.IsTimeout() {
+				flags = append(flags, "timeout")
+			}
+			if e.IsFault() {
+				flags = append(flags, "fault")
+			}
+			desc := docsCell(e.Description)
+			if len(flags) > 0 {
+				desc = strings.TrimSpace(fmt.Sprintf("%s (%s)", desc, strings.Join(flags, ", ")))
+			}
+			fmt.Fprintf(b, "| `%s` | %d | %d | %s |\n", e.Name, e.HTTPStatus(), e.GRPCCode(), desc)
+		}
+		b.WriteString("\n")
+	}
+
+	var payload, result interface{}
+	if m.Payload != nil {
+		payload = m.Payload.Example(expr.NewRandom(seed + ":" + m.Key() + ":payload"))
+	}
+	if m.Result != nil {
+		result = m.Result.Example(expr.NewRandom(seed + ":" + m.Key()))
+	}
+	b.WriteString("### Examples\n\n#### HTTP\n\n")
+	b.WriteString("```http\n" + docsHTTPRequest(m, version, payload) + "```\n\n")
+	if m.Result == nil {
+		b.WriteString("```http\nHTTP/1.1 204 No Content\n```\n\n")
+	} else {
+		fmt.Fprintf(b, "```http\nHTTP/1.1 200 OK\nContent-Type: application/json\n\n%s\n```\n\n", docsJSON(result))
+	}
+	fmt.Fprintf(b, "#### gRPC\n\nThe messages of `%s` are encoded with the json codec.\n\n", GoGRPCMethodName(m.Service, m.Name))
+	if m.Payload == nil {
+		b.WriteString("The request message is empty.\n\n")
+	} else {
+		fmt.Fprintf(b, "Request:\n\n```json\n%s\n```\n\n", docsJSON(payload))
+	}
+	if m.Result == nil {
+		b.WriteString("The response message is empty.\n")
+	} else {
+		fmt.Fprintf(b, "Response:\n\n```json\n%s\n```\n", docsJSON(result))
+	}
+}
+
+// docsHTTPRequest returns the example HTTP request of the method, the path
+// params are taken from the payload, the other fields are sent in the query
+// string for GET and HEAD requests and in the body otherwise.
+func docsHTTPRequest(m *runtime.Method, version string, payload interface{}) string {
+	path := docsPath(m.Route.Path, version)
+	body := payload
+	query := url.Values{}
+	if obj, ok := payload.(map[string]interface{}); ok {
+		rest := make(map[string]interface{}, len(obj))
+		for _, k := range sortedKeys(obj) {
+			v := obj[k]
+			param := "{" + k + "}"
+			switch {
+			case strings.Contains(path, param):
+				path = strings.Replace(path, param, url.PathEscape(fmt.Sprint(v)), -1)
+			case (m.Route.Method == "GET" || m.Route.Method == "HEAD") && docsScalar(v):
+				query.Set(k, fmt.Sprint(v))
+			default:
+				rest[k] = v
+			}
+		}
+		body = rest
+		if len(rest) == 0 {
+			body = nil
+		}
+	}
+	if q := query.Encode(); q != "" {
+		path += "?" + q
+	}
+	req := fmt.Sprintf("%s %s HTTP/1.1\n", m.Route.Method, path)
+	if body != nil {
+		req += fmt.Sprintf("Content-Type: application/json\n\n%s\n", docsJSON(body))
+	}
+	return req
+}
+
+// docsScalar returns true if the example value can be sent as a query param.
+func docsScalar(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return false
+	}
+	return true
+}
+
+// docsPath returns the HTTP path prefixed with the version if not empty.
+func docsPath(path, version string) string {
+	if version == "" {
+		return path
+	}
+	return "/" + version + path
+}
+
+// DocsTypes returns the Markdown of the page describing the enums and the
+// models with their fields.
+func DocsTypes(enums []*expr.EnumTypeExpr, models []*runtime.Model) string {
+	var b strings.Builder
+	b.WriteString("# Types\n\n[Overview](index.md)\n\n")
+	if len(enums) > 0 {
+		b.WriteString("## Enums\n\n")
+		for _, e := range enums {
+			fmt.Fprintf(&b, "### %s\n\n", e.Name())
+			writeDocsMeta(&b, e.Meta)
+			writeDocsParagraph(&b, e.Description)
+			fmt.Fprintf(&b, "Type: %s\n\n", docsType(e.Type))
+			b.WriteString("| Value | Description |\n| --- | --- |\n")
+			for _, v := range e.Values {
+				desc := docsCell(v.Description)
+				if dep := docsDeprecation(v.Meta); dep != "" {
+					desc = strings.TrimSpace(desc + " " + dep)
+				}
+				fmt.Fprintf(&b, "| `%s` | %s |\n", docsLiteral(v.Value), desc)
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(models) > 0 {
+		b.WriteString("## Models\n\n")
+		for _, m := range models {
+			fmt.Fprintf(&b, "### %s\n\n", m.Name)
+			writeDocsMeta(&b, m.Type.Meta)
+			writeDocsParagraph(&b, m.Description)
+			writeDocsFields(&b, m.Type.AttributeExpr)
+		}
+	}
+	return b.String()
+}
+
+// writeDocsFields writes the table of the fields of the object attribute,
+// the type of the attribute otherwise.
+func writeDocsFields(b *strings.Builder, att *expr.AttributeExpr) {
+	if att == nil {
+		b.WriteString("None.\n\n")
+		return
+	}
+	if ut, ok := att.Type.(expr.UserType); ok {
+		fmt.Fprintf(b, "Type: %s\n\n", docsType(ut))
+	}
+	obj := expr.AsObject(att.Type)
+	if obj == nil {
+		if _, ok := att.Type.(expr.UserType); !ok {
+			fmt.Fprintf(b, "Type: %s\n\n", docsType(att.Type))
+		}
+		return
+	}
+	if len(*obj) == 0 {
+		b.WriteString("No fields.\n\n")
+		return
+	}
+	b.WriteString("| Name | Type | Required | Default | Constraints | Description |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, nat := range *obj {
+		f := nat.Attribute
+		required := ""
+		if att.IsRequired(nat.Name) {
+			required = "yes"
+		}
+		def := ""
+		if f.DefaultValue != nil {
+			def = fmt.Sprintf("`%s`", docsCell(docsLiteral(f.DefaultValue)))
+		}
+		desc := docsCell(f.Description)
+		if f.Docs != nil && f.Docs.URL != "" {
+			desc = strings.TrimSpace(fmt.Sprintf("%s [%s](%s)", desc, docsCell(docsDefault(f.Docs.Description, "More")), f.Docs.URL))
+		}
+		if avail := docsAvailability(f.Meta); avail != "" {
+			desc = strings.TrimSpace(desc + " " + avail)
+		}
+		if dep := docsDeprecation(f.Meta); dep != "" {
+			desc = strings.TrimSpace(desc + " " + dep)
+		}
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s | %s | %s |\n",
+			nat.Name, docsType(f.Type), required, def, docsConstraints(f.Validation), desc)
+	}
+	b.WriteString("\n")
+}
+
+// docsType returns the Markdown of the data type, the user types link to
+// their description in the types page.
+func docsType(dt expr.DataType) string {
+	switch t := dt.(type) {
+	case *expr.Array:
+		return "array of " + docsType(t.ElemType.Type)
+	case *expr.Map:
+		return fmt.Sprintf("map of %s to %s", docsType(t.KeyType.Type), docsType(t.ElemType.Type))
+	case *expr.Union:
+		alts := make([]string, len(t.Values))
+		for i, nat := range t.Values {
+			alts[i] = docsType(nat.Attribute.Type)
+		}
+		return "one of " + strings.Join(alts, ", ")
+	case expr.UserType:
+		return fmt.Sprintf("[%s](types.md#%s)", t.Name(), docsAnchor(t.Name()))
+	}
+	return fmt.Sprintf("`%s`", dt.Name())
+}
+
+// docsConstraints returns the validations of the field.
+func docsConstraints(v *expr.ValidationExpr) string {
+	if v == nil {
+		return ""
+	}
+	var res []string
+	if len(v.Values) > 0 {
+		values := make([]string, len(v.Values))
+		for i, val := range v.Values {
+			values[i] = "`" + docsCell(docsLiteral(val)) + "`"
+		}
+		res = append(res, "one of "+strings.Join(values, ", "))
+	}
+	if v.Format != "" {
+		res = append(res, fmt.Sprintf("format `%s`", v.Format))
+	}
+	if v.Pattern != "" {
+		res = append(res, fmt.Sprintf("pattern `%s`", docsCell(v.Pattern)))
+	}
+	if v.Minimum != nil {
+		res = append(res, "minimum "+strconv.FormatFloat(*v.Minimum, 'f', -1, 64))
+	}
+	if v.Maximum != nil {
+		res = append(res, "maximum "+strconv.FormatFloat(*v.Maximum, 'f', -1, 64))
+	}
+	if v.MinLength != nil {
+		res = append(res, fmt.Sprintf("min length %d", *v.MinLength))
+	}
+	if v.MaxLength != nil {
+		res = append(res, fmt.Sprintf("max length %d", *v.MaxLength))
+	}
+	if v.Precision != nil {
+		res = append(res, fmt.Sprintf("precision %d", *v.Precision))
+	}
+	if v.Scale != nil {
+		res = append(res, fmt.Sprintf("scale %d", *v.Scale))
+	}
+	return strings.Join(res, ", ")
+}
+
+// writeDocsMeta writes the deprecation and the availability of the element
+// with the given meta.
+func writeDocsMeta(b *strings.Builder, meta expr.MetaExpr) {
+	if dep := docsDeprecation(meta); dep != "" {
+		b.WriteString(dep + "\n\n")
+	}
+	if avail := docsAvailability(meta); avail != "" {
+		b.WriteString(avail + "\n\n")
+	}
+}
+
+// docsDeprecation returns the deprecation notice of the element with the
+// given meta, empty if the element is not deprecated.
+func docsDeprecation(meta expr.MetaExpr) string {
+	d := expr.Deprecation(meta)
+	if d == nil {
+		return ""
+	}
+	notice := "**Deprecated**"
+	if d.Since != "" {
+		notice += fmt.Sprintf(" since `%s`", d.Since)
+	}
+	if d.Reason != "" {
+		notice += ": " + docsCell(d.Reason)
+	}
+	return notice
+}
+
+// docsAvailability returns the API versions the element with the given meta
+// is available in, empty if it is available in all the versions.
+func docsAvailability(meta expr.MetaExpr) string {
+	since, until := meta[expr.VersionSinceMeta], meta[expr.VersionUntilMeta]
+	switch {
+	case len(since) > 0 && len(until) > 0:
+		return fmt.Sprintf("Available from `%s` to `%s`.", since[0], until[0])
+	case len(since) > 0:
+		return fmt.Sprintf("Available since `%s`.", since[0])
+	case len(until) > 0:
+		return fmt.Sprintf("Available until `%s`.", until[0])
+	}
+	return ""
+}
+
+// docsSummary returns the first line of the description followed by the
+// deprecation notice.
+func docsSummary(desc string, meta expr.MetaExpr) string {
+	summary := cliShort(desc, "")
+	if dep := docsDeprecation(meta); dep != "" {
+		summary = strings.TrimSpace(summary + " " + dep)
+	}
+	return summary
+}
+
+// writeDocsParagraph writes the text followed by a blank line if not empty.
+func writeDocsParagraph(b *strings.Builder, text string) {
+	if text = strings.TrimSpace(text); text != "" {
+		b.WriteString(text + "\n\n")
+	}
+}
+
+// writeDocsLink writes the link to the external documentation if any.
+func writeDocsLink(b *strings.Builder, docs *expr.DocsExpr) {
+	if docs == nil || docs.URL == "" {
+		return
+	}
+	fmt.Fprintf(b, "See [%s](%s).\n\n", docsDefault(docs.Description, docs.URL), docs.URL)
+}
+
+// docsAnchor returns the anchor of the heading, like GitHub does it: the
+// heading in lower case without the punctuation and with dashes as spaces.
+func docsAnchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// docsCell returns the text written in a table cell on a single line with
+// the pipes escaped.
+func docsCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.Replace(s, "|", `\|`, -1)
+}
+
+// docsCodes returns the values as a comma separated list of code spans.
+func docsCodes(values []string) string {
+	codes := make([]string, len(values))
+	for i, v := range values {
+		codes[i] = "`" + v + "`"
+	}
+	return strings.Join(codes, ", ")
+}
+
+// docsDefault returns s or def if s is empty.
+func docsDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// docsURL returns true if s is an absolute URL.
+func docsURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// docsLiteral returns the JSON literal of the value.
+func docsLiteral(v interface{}) string {
+	js, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(js)
+}
+
+// docsJSON returns the indented JSON of the example value.
+func docsJSON(v interface{}) string {
+	js, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(js)
+}
+
+// DocsHTML converts the Markdown of a page returned by DocsIndex,
+// DocsService or DocsTypes to a standalone HTML page, the links to the
+// Markdown pages are changed to link to the HTML pages. Only the subset of
+// Markdown used by the pages is supported: headings, paragraphs, lists,
+// tables, fenced code blocks, code spans, links and strong emphasis. The ids
+// of the headings are their anchors suffixed with a counter when repeated,
+// like GitHub does it.
+func DocsHTML(md string) string {
+	var (
+		body  strings.Builder
+		title string
+		lines = strings.Split(md, "\n")
+		ids   = make(map[string]int)
+	)
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+		case strings.HasPrefix(line, "```"):
+			lang := strings.TrimPrefix(line, "```")
+			if lang != "" {
+				fmt.Fprintf(&body, "<pre><code class=\"language-%s\">", html.EscapeString(lang))
+			} else {
+				body.WriteString("<pre><code>")
+			}
+			for i++; i < len(lines) && !strings.HasPrefix(lines[i], "```"); i++ {
+				body.WriteString(html.EscapeString(lines[i]) + "\n")
+			}
+			body.WriteString("</code></pre>\n")
+		case strings.HasPrefix(line, "#"):
+			level := len(line) - len(strings.TrimLeft(line, "#"))
+			text := strings.TrimSpace(line[level:])
+			if title == "" {
+				title = text
+			}
+			id := docsAnchor(text)
+			if n := ids[id]; n > 0 {
+				id = fmt.Sprintf("%s-%d", id, n)
+			}
+			ids[docsAnchor(text)]++
+			fmt.Fprintf(&body, "<h%d id=\"%s\">%s</h%d>\n", level, id, docsInline(text), level)
+		case strings.HasPrefix(line, "|"):
+			body.WriteString("<table>\n")
+			for start := i; i < len(lines) && strings.HasPrefix(lines[i], "|"); i++ {
+				if i == start+1 {
+					continue
+				}
+				tag := "td"
+				if i == start {
+					tag = "th"
+				}
+				body.WriteString("<tr>")
+				for _, cell := range docsCells(lines[i]) {
+					fmt.Fprintf(&body, "<%s>%s</%s>", tag, docsInline(cell), tag)
+				}
+				body.WriteString("</tr>\n")
+			}
+			i--
+			body.WriteString("</table>\n")
+		case strings.HasPrefix(line, "- "):
+			body.WriteString("<ul>\n")
+			for ; i < len(lines) && strings.HasPrefix(lines[i], "- "); i++ {
+				fmt.Fprintf(&body, "<li>%s</li>\n", docsInline(lines[i][2:]))
+			}
+			i--
+			body.WriteString("</ul>\n")
+		default:
+			para := []string{line}
+			for i+1 < len(lines) && docsParagraphLine(lines[i+1]) {
+				i++
+				para = append(para, lines[i])
+			}
+			fmt.Fprintf(&body, "<p>%s</p>\n", docsInline(strings.Join(para, "\n")))
+		}
+	}
+	return fmt.Sprintf(docsHTMLPage, html.EscapeString(title), body.String())
+}
+
+// docsParagraphLine returns true if the line continues a paragraph.
+func docsParagraphLine(line string) bool {
+	if strings.TrimSpace(line) == "" {
+		return false
+	}
+	for _, p := range []string{"```", "#", "|", "- "} {
+		if strings.HasPrefix(line, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// docsCells splits the table row in cells, the escaped pipes are kept in the
+// cells.
+func docsCells(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+	var (
+		cells []string
+		cell  strings.Builder
+	)
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case row[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+var (
+	docsLinkRe     = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]*)\)`)
+	docsAutoLinkRe = regexp.MustCompile(`&lt;(https?://[^\s&]*|[^\s@&]+@[^\s&]+)&gt;`)
+	docsStrongRe   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+)
+
+// docsInline converts the inline Markdown of the text to HTML, the text of
+// the code spans is kept as is.
+func docsInline(text string) string {
+	var b strings.Builder
+	parts := strings.Split(text, "`")
+	for i, part := range parts {
+		part = html.EscapeString(part)
+		if i%2 == 1 && i < len(parts)-1 {
+			b.WriteString("<code>" + part + "</code>")
+			continue
+		}
+		if i%2 == 1 {
+			part = "`" + part
+		}
+		part = docsLinkRe.ReplaceAllStringFunc(part, func(s string) string {
+			m := docsLinkRe.FindStringSubmatch(s)
+			return fmt.Sprintf("<a href=\"%s\">%s</a>", docsHTMLLink(m[2]), m[1])
+		})
+		part = docsAutoLinkRe.ReplaceAllStringFunc(part, func(s string) string {
+			target := strings.TrimSuffix(strings.TrimPrefix(s, "&lt;"), "&gt;")
+			href := target
+			if !strings.Contains(target, "://") {
+				href = "mailto:" + target
+			}
+			return fmt.Sprintf("<a href=\"%s\">%s</a>", href, target)
+		})
+		part = docsStrongRe.ReplaceAllString(part, "<strong>$1</strong>")
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// docsHTMLLink changes the relative link to a Markdown page to link to the
+// HTML page.
+func docsHTMLLink(href string) string {
+	if strings.Contains(href, "://") {
+		return href
+	}
+	page, anchor := href, ""
+	if i := strings.IndexByte(href, '#'); i >= 0 {
+		page, anchor = href[:i], href[i:]
+	}
+	if strings.HasSuffix(page, ".md") {
+		page = strings.TrimSuffix(page, ".md") + ".html"
+	}
+	return page + anchor
+}
+
+// docsHTMLPage is the layout of the HTML pages, it takes the title and the
+// body.
+const docsHTMLPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; line-height: 1.5; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+pre { background: #f6f8fa; padding: 1em; overflow: auto; }
+code { font-family: monospace; }
+</style>
+</head>
+<body>
+%s</body>
+</html>
+`
@@ -0,0 +1,126 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+func TestDocs(t *testing.T) {
+	spec, err := runtime.ParseSpec([]byte(`
+name: teams
+description: Manages the teams.
+terms_of_service: https://example.com/terms
+contact:
+  name: support
+  email: support@example.com
+license:
+  name: MIT
+  url: https://opensource.org/licenses/MIT
+enums:
+  Role:
+    description: Role of the user.
+    values:
+      - admin
+      - value: member
+        description: A regular member.
+models:
+  User:
+    description: User is a member of a team.
+    fields:
+      - name: name
+        type: string
+        description: Name of the user | login
+        required: true
+        min_length: 1
+        pattern: "^[a-z]+$"
+        example: alice
+      - name: age
+        type: int32
+        default: 18
+        minimum: 0
+        deprecated: use birthday
+      - name: role
+        type: Role
+      - name: tags
+        type: array<string>
+        docs:
+          url: https://example.com/tags
+services:
+  users:
+    description: Manages the users.
+    errors:
+      not_found:
+        description: The user does not exist.
+        status: 404
+    methods:
+      get:
+        description: Get a user by name.
+        payload: User
+        result: User
+        http: GET /users/{name}
+      ping: {}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := runtime.New()
+	if err := r.Load(spec); err != nil {
+		t.Fatal(err)
+	}
+	index := DocsIndex(r.API(), r.Versions(), r.Services(), r.Servers())
+	service := DocsService(r.Service("users"), "", "goser")
+
+	cases := map[string]struct {
+		code     string
+		contains []string
+	}{
+		"index": {index, []string{
+			"# teams reference\n\nManages the teams.\n\n",
+			"- Terms of service: <https://example.com/terms>\n",
+			"- Contact: support <support@example.com>\n",
+			"- License: [MIT](https://opensource.org/licenses/MIT)\n",
+			"| [users](users.md) | Manages the users. |\n",
+		}},
+		"service": {service, []string{
+			"| [get](#get) | Get a user by name. |\n",
+			"## get\n\nGet a user by name.\n\n",
+			"| HTTP | `GET /users/{name}` |\n| gRPC | `/users.Users/Get` |\n",
+			"Type: [User](types.md#user)\n\n",
+			"| `name` | `string` | yes |  | pattern `^[a-z]+$`, min length 1 | Name of the user \\| login |\n",
+			"| `age` | `int32` |  | `18` | minimum 0 | **Deprecated**: use birthday |\n",
+			"| `role` | [Role](types.md#role) |  |  |  |  |\n",
+			"| `tags` | array of `string` |  |  |  | [More](https://example.com/tags) |\n",
+			"| `not_found` | 404 | 5 | The user does not exist. |\n",
+			"```http\nGET /users/alice?age=113&role=member HTTP/1.1\nContent-Type: application/json\n\n{\n  \"tags\": [",
+			"## ping\n\n",
+			"### Payload\n\nNone.\n\n",
+			"```http\nHTTP/1.1 204 No Content\n```\n",
+			"The request message is empty.\n",
+		}},
+		"types": {DocsTypes(r.Enums(), r.Models()), []string{
+			"### Role\n\nRole of the user.\n\nType: `string`\n\n",
+			"| `\"member\"` | A regular member. |\n",
+			"### User\n\nUser is a member of a team.\n\n| Name |",
+		}},
+		"html": {DocsHTML(service), []string{
+			"<title>users service</title>",
+			"<h2 id=\"get\">get</h2>\n",
+			"<p>Type: <a href=\"types.html#user\">User</a></p>\n",
+			"<tr><td>gRPC</td><td><code>/users.Users/Get</code></td></tr>\n</table>\n",
+			"<h3 id=\"payload-1\">Payload</h3>\n",
+			"<td><code>name</code></td>",
+			"<td>Name of the user | login</td>",
+			"<pre><code class=\"language-http\">GET /users/alice?age=113&amp;role=member HTTP/1.1\n",
+			"<p>The request message is empty.</p>\n",
+		}},
+	}
+	for k, tc := range cases {
+		for _, s := range tc.contains {
+			if !strings.Contains(tc.code, s) {
+				t.Errorf("%s: got\n%s\nexpected it to contain\n%s", k, tc.code, s)
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+)
+
+// GoEnum returns the Go definition of the enum: a named type, a constant per
+// value and the String method. String enums are marshaled as text with the
+// MarshalText and UnmarshalText methods, integer enums as JSON numbers with the
+// MarshalJSON and UnmarshalJSON methods, as documented by their OpenAPI
+// schemas. The generated code uses the fmt package and encoding/json for
+// integer enums.
+func GoEnum(e *expr.EnumTypeExpr) string {
+	var (
+		b      strings.Builder
+		name   = Goify(e.Name(), true)
+		consts = make([]string, len(e.Values))
+		str    = e.Base() == expr.String
+	)
+	for i, v := range e.Values {
+		consts[i] = name + Goify(v.Name, true)
+	}
+
+	writeDoc(&b, "", e.Description, name+" is the "+e.Name()+" enum.", e.Meta)
+	fmt.Fprintf(&b, "type %s %s\n\n", name, GoTypeName(e.Base()))
+	b.WriteString("const (\n")
+	for i, v := range e.Values {
+		writeDoc(&b, "\t", v.Description, "", v.Meta)
+		fmt.Fprintf(&b, "\t%s %s = %#v\n", consts[i], name, v.Value)
+	}
+	b.WriteString(")\n\n")
+
+	b.WriteString("// String returns the value as text.\n")
+	fmt.Fprintf(&b, "func (e %s) String() string {\n", name)
+	if str {
+		b.WriteString("\treturn string(e)\n")
+	} else {
+		b.WriteString("\tswitch e {\n")
+		for i, v := range e.Values {
+			fmt.Fprintf(&b, "\tcase %s:\n\t\treturn %q\n", consts[i], v.Name)
+		}
+		b.WriteString("\t}\n")
+		fmt.Fprintf(&b, "\treturn fmt.Sprintf(\"%s(%%d)\", e)\n", name)
+	}
+	b.WriteString("}\n\n")
+
+	if !str {
+		b.WriteString("// MarshalJSON implements json.Marshaler, the value is a JSON number.\n")
+		fmt.Fprintf(&b, "func (e %s) MarshalJSON() ([]byte, error) {\n", name)
+		fmt.Fprintf(&b, "\tswitch e {\n\tcase %s:\n", strings.Join(consts, ", "))
+		b.WriteString("\t\treturn json.Marshal(int64(e))\n\t}\n")
+		fmt.Fprintf(&b, "\treturn nil, fmt.Errorf(\"invalid %s value %%d\", int64(e))\n", name)
+		b.WriteString("}\n\n")
+
+		b.WriteString("// UnmarshalJSON implements json.Unmarshaler.\n")
+		fmt.Fprintf(&b, "func (e *%s) UnmarshalJSON(data []byte) error {\n", name)
+		b.WriteString("\tvar v int64\n\tif err := json.Unmarshal(data, &v); err != nil {\n\t\treturn err\n\t}\n")
+		fmt.Fprintf(&b, "\tswitch %s(v) {\n\tcase %s:\n", name, strings.Join(consts, ", "))
+		fmt.Fprintf(&b, "\t\t*e = %s(v)\n\t\treturn nil\n\t}\n", name)
+		fmt.Fprintf(&b, "\treturn fmt.Errorf(\"invalid %s value %%d\", v)\n}\n", name)
+		return b.String()
+	}
+
+	b.WriteString("// MarshalText implements encoding.TextMarshaler.\n")
+	fmt.Fprintf(&b, "func (e %s) MarshalText() ([]byte, error) {\n", name)
+	fmt.Fprintf(&b, "\tswitch e {\n\tcase %s:\n", strings.Join(consts, ", "))
+	b.WriteString("\t\treturn []byte(e), nil\n\t}\n")
+	fmt.Fprintf(&b, "\treturn nil, fmt.Errorf(\"invalid %s value %%v\", string(e))\n", name)
+	b.WriteString("}\n\n")
+
+	b.WriteString("// UnmarshalText implements encoding.TextUnmarshaler.\n")
+	fmt.Fprintf(&b, "func (e *%s) UnmarshalText(text []byte) error {\n", name)
+	b.WriteString("\tswitch string(text) {\n")
+	for i, v := range e.Values {
+		fmt.Fprintf(&b, "\tcase %q:\n\t\t*e = %s\n", v.Value.(string), consts[i])
+	}
+	b.WriteString("\tdefault:\n")
+	fmt.Fprintf(&b, "\t\treturn fmt.Errorf(\"invalid %s value %%q\", text)\n", name)
+	b.WriteString("\t}\n\treturn nil\n}\n")
+	return b.String()
+}
+
+// ProtoEnum returns the proto3 definition of the enum, the values are prefixed
+// with the enum name and a zero UNSPECIFIED value is added as required by
+// proto3.
+func ProtoEnum(e *expr.EnumTypeExpr) string {
+	var (
+		b      strings.Builder
+		prefix = strings.ToUpper(SnakeCase(e.Name())) + "_"
+	)
+	writeComment(&b, "", e.Description, "")
+	fmt.Fprintf(&b, "enum %s {\n", Goify(e.Name(), true))
+	if expr.Deprecation(e.Meta) != nil {
+		b.WriteString("  option deprecated = true;\n")
+	}
+	fmt.Fprintf(&b, "  %sUNSPECIFIED = 0;\n", prefix)
+	for _, v := range e.Values {
+		writeComment(&b, "  ", v.Description, "")
+		fmt.Fprintf(&b, "  %s%s = %d%s;\n", prefix, strings.ToUpper(SnakeCase(v.Name)), v.Tag, ProtoDeprecatedOption(v.Meta))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// OpenAPIEnumSchema returns the OpenAPI schema of the enum.
+func OpenAPIEnumSchema(e *expr.EnumTypeExpr) map[string]interface{} {
+	typ, format := OpenAPIType(e.Base())
+	values := make([]interface{}, len(e.Values))
+	for i, v := range e.Values {
+		values[i] = v.Value
+	}
+	schema := map[string]interface{}{
+		"type": typ,
+		"enum": values,
+	}
+	if format != "" {
+		schema["format"] = format
+	}
+	if e.Description != "" {
+		schema["description"] = e.Description
+	}
+	if expr.Deprecation(e.Meta) != nil {
+		schema["deprecated"] = true
+	}
+	return schema
+}
+
+// SQLEnumCheck returns the SQL check constraint restricting the column to the
+// enum values.
+func SQLEnumCheck(column string, e *expr.EnumTypeExpr) string {
+	values := make([]string, len(e.Values))
+	for i, v := range e.Values {
+		if s, ok := v.Value.(string); ok {
+			values[i] = "'" + strings.Replace(s, "'", "''", -1) + "'"
+		} else {
+			values[i] = fmt.Sprintf("%v", v.Value)
+		}
+	}
+	return fmt.Sprintf("CHECK (%s IN (%s))", column, strings.Join(values, ", "))
+}
+
+// writeComment writes the description as a comment with the given
+// indentation, def is used if the description is empty.
+func writeComment(b *strings.Builder, indent, desc, def string) {
+	if desc == "" {
+		desc = def
+	}
+	if desc == "" {
+		return
+	}
+	for _, l := range strings.Split(strings.TrimSpace(desc), "\n") {
+		b.WriteString(strings.TrimRight(indent+"// "+l, " ") + "\n")
+	}
+}
@@ -0,0 +1,96 @@
+package codegen
+
+import (
+	"go/format"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.zoe.im/goser/expr"
+)
+
+func testEnums() (*expr.EnumTypeExpr, *expr.EnumTypeExpr) {
+	status := expr.NewEnumTypeExpr("status", expr.String,
+		&expr.EnumValueExpr{Value: "active", Description: "Active user"},
+		&expr.EnumValueExpr{Value: "it's off", Name: "disabled", Tag: 3},
+	)
+	status.Description = "Status of user"
+	level := expr.NewEnumTypeExpr("log_level", expr.Int32,
+		&expr.EnumValueExpr{Value: 10, Name: "debug"},
+		&expr.EnumValueExpr{Value: 20, Name: "info"},
+	)
+	return status, level
+}
+
+func TestGoEnum(t *testing.T) {
+	status, level := testEnums()
+	cases := map[string]struct {
+		enum     *expr.EnumTypeExpr
+		contains []string
+		excludes []string
+	}{
+		"string": {status, []string{
+			"// Status of user\ntype Status string",
+			"\t// Active user\n\tStatusActive Status = \"active\"",
+			"StatusDisabled Status = \"it's off\"",
+			"case \"it's off\":\n\t\t*e = StatusDisabled",
+		}, []string{"MarshalJSON", "UnmarshalJSON"}},
+		"integer": {level, []string{
+			"// LogLevel is the log_level enum.\ntype LogLevel int32",
+			"LogLevelDebug LogLevel = 10",
+			"case LogLevelInfo:\n\t\treturn \"info\"",
+			"case LogLevelDebug, LogLevelInfo:\n\t\treturn json.Marshal(int64(e))",
+			"switch LogLevel(v) {\n\tcase LogLevelDebug, LogLevelInfo:\n\t\t*e = LogLevel(v)",
+		}, []string{"MarshalText", "UnmarshalText"}},
+	}
+	for k, tc := range cases {
+		code := GoEnum(tc.enum)
+		if _, err := format.Source([]byte("package p\n\n" + code)); err != nil {
+			t.Errorf("%s: invalid Go code: %v\n%s", k, err, code)
+		}
+		for _, s := range tc.contains {
+			if !strings.Contains(code, s) {
+				t.Errorf("%s: got\n%s\nexpected it to contain\n%s", k, code, s)
+			}
+		}
+		for _, s := range tc.excludes {
+			if strings.Contains(code, s) {
+				t.Errorf("%s: got\n%s\nexpected it not to contain\n%s", k, code, s)
+			}
+		}
+	}
+}
+
+func TestProtoEnum(t *testing.T) {
+	status, _ := testEnums()
+	expected := `// Status of user
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  // Active user
+  STATUS_ACTIVE = 1;
+  STATUS_DISABLED = 3;
+}
+`
+	if actual := ProtoEnum(status); actual != expected {
+		t.Errorf("got\n%s\nexpected\n%s", actual, expected)
+	}
+}
+
+func TestEnumSchemaAndCheck(t *testing.T) {
+	status, level := testEnums()
+	schema := OpenAPIEnumSchema(level)
+	expected := map[string]interface{}{
+		"type":   "integer",
+		"format": "int32",
+		"enum":   []interface{}{10, 20},
+	}
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("got %#v, expected %#v", schema, expected)
+	}
+	if actual := SQLEnumCheck("status", status); actual != "CHECK (status IN ('active', 'it''s off'))" {
+		t.Errorf("got %q", actual)
+	}
+	if actual := SQLEnumCheck("level", level); actual != "CHECK (level IN (10, 20))" {
+		t.Errorf("got %q", actual)
+	}
+}
@@ -0,0 +1,136 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+)
+
+// GoErrors returns the Go error model of the design errors: the ErrorResult
+// struct, a sentinel value and a constructor per error, the mapping of the
+// errors to HTTP statuses and gRPC codes, and the decoding of error responses
+// by clients. The error results compare equal with errors.Is if they have the
+// same name so that clients can check the decoded errors against the sentinel
+// values, and errors.As retrieves the decoded error results. Errors with the
+// same name are generated once, an error is returned if they have different
+// types. The generated code uses the crypto/rand, encoding/base64,
+// encoding/json, fmt and io packages.
+func GoErrors(errs []*expr.ErrorExpr) (string, error) {
+	var (
+		b     strings.Builder
+		seen  = make(map[string]*expr.ErrorExpr)
+		uniqs []*expr.ErrorExpr
+	)
+	for _, e := range errs {
+		prev, ok := seen[e.Name]
+		if !ok {
+			seen[e.Name] = e
+			uniqs = append(uniqs, e)
+			continue
+		}
+		if !expr.Equal(prev.Type, e.Type) {
+			return "", fmt.Errorf("error %s is defined with different types: %s and %s",
+				e.Name, expr.QualifiedTypeName(prev.Type), expr.QualifiedTypeName(e.Type))
+		}
+	}
+
+	b.WriteString(goErrorResult)
+
+	b.WriteString("// HTTPStatus returns the HTTP status of the error responses.\n")
+	b.WriteString("func (e *ErrorResult) HTTPStatus() int {\n\tswitch e.Name {\n")
+	for _, e := range uniqs {
+		fmt.Fprintf(&b, "\tcase %q:\n\t\treturn %d\n", e.Name, e.HTTPStatus())
+	}
+	b.WriteString("\t}\n\tif e.Fault {\n\t\treturn 500\n\t}\n\treturn 400\n}\n\n")
+
+	b.WriteString("// GRPCCode returns the gRPC code of the error responses.\n")
+	b.WriteString("func (e *ErrorResult) GRPCCode() uint32 {\n\tswitch e.Name {\n")
+	for _, e := range uniqs {
+		fmt.Fprintf(&b, "\tcase %q:\n\t\treturn %d\n", e.Name, e.GRPCCode())
+	}
+	b.WriteString("\t}\n\tif e.Fault {\n\t\treturn 13\n\t}\n\treturn 2\n}\n")
+
+	if len(uniqs) > 0 {
+		b.WriteString("\nvar (\n")
+		for i, e := range uniqs {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			name := "Err" + Goify(e.Name, true)
+			writeDoc(&b, "\t", e.Description, name+" is the "+e.Name+" error.", e.Meta)
+			b.WriteString("\t// Use errors.Is to check errors against it.\n")
+			fmt.Fprintf(&b, "\t%s = &ErrorResult{Name: %q, Temporary: %t, Timeout: %t, Fault: %t}\n",
+				name, e.Name, e.IsTemporary(), e.IsTimeout(), e.IsFault())
+		}
+		b.WriteString(")\n")
+	}
+	for _, e := range uniqs {
+		name := "Make" + Goify(e.Name, true)
+		fmt.Fprintf(&b, "\n// %s builds a %s error with the message of err.\n", name, e.Name)
+		fmt.Fprintf(&b, "func %s(err error) *ErrorResult {\n", name)
+		fmt.Fprintf(&b, "\treturn newErrorResult(Err%s, err.Error())\n}\n", Goify(e.Name, true))
+	}
+	return b.String(), nil
+}
+
+// goErrorResult is the Go definition of the error results independent of the
+// design errors.
+const goErrorResult = `// ErrorResult is the error returned by the service methods.
+type ErrorResult struct {
+	// Name is the name of this class of errors.
+	Name string ` + "`json:\"name\"`" + `
+	// ID is a unique identifier for this particular occurrence of the problem.
+	ID string ` + "`json:\"id\"`" + `
+	// Message is a human-readable explanation specific to this occurrence of
+	// the problem.
+	Message string ` + "`json:\"message\"`" + `
+	// Temporary is true if the error is temporary (i.e. retryable).
+	Temporary bool ` + "`json:\"temporary\"`" + `
+	// Timeout is true if the error is due to a timeout.
+	Timeout bool ` + "`json:\"timeout\"`" + `
+	// Fault is true if the error is due to a server-side fault.
+	Fault bool ` + "`json:\"fault\"`" + `
+}
+
+// Error returns the error message.
+func (e *ErrorResult) Error() string {
+	return e.Message
+}
+
+// Is returns true if target is an error result with the same name.
+func (e *ErrorResult) Is(target error) bool {
+	t, ok := target.(*ErrorResult)
+	return ok && t.Name == e.Name
+}
+
+// DecodeErrorResult decodes the body of an error response, the returned error
+// is an *ErrorResult if the body is a valid error result.
+func DecodeErrorResult(body []byte) error {
+	var e ErrorResult
+	if err := json.Unmarshal(body, &e); err != nil || e.Name == "" {
+		return fmt.Errorf("invalid error response: %s", body)
+	}
+	return &e
+}
+
+// newErrorResult builds an occurrence of the error with a unique id.
+func newErrorResult(e *ErrorResult, msg string) *ErrorResult {
+	return &ErrorResult{
+		Name:      e.Name,
+		ID:        newErrorID(),
+		Message:   msg,
+		Temporary: e.Temporary,
+		Timeout:   e.Timeout,
+		Fault:     e.Fault,
+	}
+}
+
+// newErrorID returns a random identifier of error occurrences.
+func newErrorID() string {
+	b := make([]byte, 6)
+	io.ReadFull(rand.Reader, b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+`
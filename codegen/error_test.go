@@ -0,0 +1,55 @@
+package codegen
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"go.zoe.im/goser/expr"
+)
+
+func TestGoErrors(t *testing.T) {
+	notFound := expr.NewErrorExpr("not_found", nil)
+	notFound.Description = "The user doesn't exist"
+	notFound.Meta = expr.MetaExpr{expr.ErrorHTTPStatusMeta: {"404"}}
+	unavailable := expr.NewErrorExpr("unavailable", nil)
+	unavailable.Meta = expr.MetaExpr{expr.ErrorTemporaryMeta: nil}
+
+	code, err := GoErrors([]*expr.ErrorExpr{notFound, unavailable, notFound})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := format.Source([]byte("package p\n\n" + code)); err != nil {
+		t.Errorf("invalid Go code: %v\n%s", err, code)
+	}
+	for _, s := range []string{
+		"type ErrorResult struct {",
+		"func (e *ErrorResult) Is(target error) bool {",
+		"func DecodeErrorResult(body []byte) error {",
+		"\tcase \"not_found\":\n\t\treturn 404\n",
+		"\tcase \"unavailable\":\n\t\treturn 503\n",
+		"\tcase \"not_found\":\n\t\treturn 5\n",
+		"\tcase \"unavailable\":\n\t\treturn 14\n",
+		"\t// The user doesn't exist\n\t// Use errors.Is to check errors against it.\n\tErrNotFound = &ErrorResult{Name: \"not_found\", Temporary: false, Timeout: false, Fault: false}\n",
+		"\tErrUnavailable = &ErrorResult{Name: \"unavailable\", Temporary: true, Timeout: false, Fault: false}\n",
+		"func MakeNotFound(err error) *ErrorResult {\n\treturn newErrorResult(ErrNotFound, err.Error())\n}\n",
+	} {
+		if !strings.Contains(code, s) {
+			t.Errorf("got\n%s\nexpected it to contain\n%s", code, s)
+		}
+	}
+	if n := strings.Count(code, "func MakeNotFound("); n != 1 {
+		t.Errorf("got %d MakeNotFound functions, expected 1", n)
+	}
+}
+
+func TestGoErrorsConflict(t *testing.T) {
+	errs := []*expr.ErrorExpr{
+		expr.NewErrorExpr("not_found", nil),
+		expr.NewErrorExpr("not_found", expr.String),
+	}
+	_, err := GoErrors(errs)
+	if err == nil || err.Error() != "error not_found is defined with different types: ErrorResult and string" {
+		t.Errorf("got error %v, expected the conflicting types to be reported", err)
+	}
+}
@@ -0,0 +1,305 @@
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+// GoServiceImpl returns the Go scaffolding of the implementation of the
+// service: a struct with a method stub per service method, the stubs have the
+// runtime.HandlerFunc signature and return runtime.ErrNotImplemented. The
+// generated code uses the context and go.zoe.im/goser/pkg/runtime packages.
+func GoServiceImpl(svc *runtime.Service) string {
+	var (
+		b    strings.Builder
+		name = GoServiceImplName(svc)
+	)
+	doc := fmt.Sprintf("%s implements the %s service.", name, svc.Name)
+	if svc.Description != "" {
+		doc += "\n\n" + svc.Description
+	}
+	writeDoc(&b, "", doc, "", svc.Meta)
+	fmt.Fprintf(&b, "type %s struct{}\n\n", name)
+	fmt.Fprintf(&b, "// New%s returns the %s service implementation.\n", name, svc.Name)
+	fmt.Fprintf(&b, "func New%s() *%s {\n\treturn &%s{}\n}\n", name, name, name)
+	for _, m := range svc.Methods {
+		b.WriteString("\n")
+		writeDoc(&b, "", m.Description, fmt.Sprintf("%s implements %s.", Goify(m.Name, true), m.Name), m.Meta)
+		if m.Payload != nil {
+			fmt.Fprintf(&b, "// The payload is a validated %s.\n", m.Payload.Type.Name())
+		}
+		if m.Result != nil {
+			fmt.Fprintf(&b, "// The result must be a %s.\n", m.Result.Type.Name())
+		}
+		if len(m.Errors) > 0 {
+			names := make([]string, len(m.Errors))
+			for i, e := range m.Errors {
+				names[i] = strconv.Quote(e.Name)
+			}
+			fmt.Fprintf(&b, "// It may return the errors %s.\n", strings.Join(names, ", "))
+		}
+		fmt.Fprintf(&b, "func (s *%s) %s(ctx context.Context, p interface{}) (interface{}, error) {\n", name, Goify(m.Name, true))
+		b.WriteString("\treturn nil, runtime.ErrNotImplemented\n}\n")
+	}
+	return b.String()
+}
+
+// GoServiceImplName returns the name of the struct implementing the service.
+func GoServiceImplName(svc *runtime.Service) string {
+	return Goify(svc.Name, true) + "Service"
+}
+
+// GoAuthImpl returns the Go scaffolding of the implementation of the Auther
+// interface of GoAuther: the AuthService struct with a stub per security
+// scheme returning ErrUnauthorized. The generated code uses the context
+// package.
+func GoAuthImpl(schemes []*expr.SchemeExpr) string {
+	var b strings.Builder
+	b.WriteString("// AuthService implements the Auther interface, it authorizes the requests\n")
+	b.WriteString("// of the security schemes.\n")
+	b.WriteString("type AuthService struct{}\n\n")
+	b.WriteString("// NewAuthService returns the Auther implementation.\n")
+	b.WriteString("func NewAuthService() *AuthService {\n\treturn &AuthService{}\n}\n")
+	for _, s := range schemes {
+		if s.Kind == expr.NoKind {
+			continue
+		}
+		fmt.Fprintf(&b, "\n// %s authorizes the requests of the %s scheme, it returns the context\n", goAuthName(s), s.SchemeName)
+		b.WriteString("// of the authorized requests.\n")
+		if s.Kind == expr.BasicAuthKind {
+			fmt.Fprintf(&b, "func (a *AuthService) %s(ctx context.Context, user, pass string, scopes []string) (context.Context, error) {\n", goAuthName(s))
+		} else {
+			fmt.Fprintf(&b, "func (a *AuthService) %s(ctx context.Context, %s string, scopes []string) (context.Context, error) {\n", goAuthName(s), goCredential(s))
+		}
+		b.WriteString("\treturn nil, ErrUnauthorized\n}\n")
+	}
+	return b.String()
+}
+
+// GoExampleHandlers returns the Go code of the example server main package
+// used by GoServerMain: specs holds the contents of the spec files, newRuntime
+// loads them and handles the methods of the services hosted by the server
+// with the service implementations of GoServiceImpl defined in the package
+// imported with the given name, and newGRPCServer registers the services on
+// a gRPC server calling the methods with the runtime, the gRPC names are
+// those of GoGRPCMethodName and the messages are encoded with JSON. The
+// requests of the methods having security requirements are authorized with
+// the AuthService of GoAuthImpl and the hooks of GoAuther. The code must be
+// regenerated when the specs change. It uses the bytes, context, encoding/json,
+// errors, net/http, google.golang.org/grpc, google.golang.org/grpc/codes,
+// google.golang.org/grpc/encoding, google.golang.org/grpc/status and
+// go.zoe.im/goser/pkg/runtime packages, net/http only if the methods have
+// security requirements.
+func GoExampleHandlers(svr *expr.ServerExpr, services []*runtime.Service, pkg string, specs []string) string {
+	var (
+		b      strings.Builder
+		hosted = HostedServices(svr, services)
+		reqs   [][]*expr.SecurityExpr
+	)
+	for _, svc := range hosted {
+		for _, m := range svc.Methods {
+			reqs = append(reqs, m.Security)
+		}
+	}
+	schemes := expr.Schemes(reqs...)
+
+	b.WriteString("// specs are the contents of the spec files describing the services.\n")
+	b.WriteString("var specs = []string{\n")
+	for _, s := range specs {
+		fmt.Fprintf(&b, "\t%s,\n", strconv.Quote(s))
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// newRuntime returns the runtime of the %s server loaded from the specs,\n", svr.Name)
+	b.WriteString("// the methods are handled by the service implementations.\n")
+	b.WriteString("func newRuntime() (*runtime.Runtime, error) {\n\tr := runtime.New()\n")
+	b.WriteString("\tfor _, s := range specs {\n\t\tspec, err := runtime.ParseSpec([]byte(s))\n\t\tif err != nil {\n\t\t\treturn nil, err\n\t\t}\n")
+	b.WriteString("\t\tif err := r.Load(spec); err != nil {\n\t\t\treturn nil, err\n\t\t}\n\t}\n")
+	for _, svc := range hosted {
+		if len(svc.Methods) == 0 {
+			continue
+		}
+		v := Goify(svc.Name, false) + "Svc"
+		fmt.Fprintf(&b, "\n\t%s := %s.New%s()\n", v, pkg, GoServiceImplName(svc))
+		for _, m := range svc.Methods {
+			fmt.Fprintf(&b, "\tr.Handle(%q, %s.%s)\n", m.Key(), v, Goify(m.Name, true))
+		}
+	}
+	if len(schemes) > 0 {
+		fmt.Fprintf(&b, "\tr.Authorize(authorize(%s.NewAuthService()))\n", pkg)
+	}
+	b.WriteString("\treturn r, nil\n}\n\n")
+	if len(schemes) > 0 {
+		writeGoAuthorize(&b, schemes, pkg)
+	}
+
+	fmt.Fprintf(&b, "// newGRPCServer returns the gRPC server of the services hosted by the %s\n", svr.Name)
+	b.WriteString("// server, the methods are called with the runtime.\n")
+	b.WriteString("func newGRPCServer(r *runtime.Runtime) *grpc.Server {\n\tsrv := grpc.NewServer()\n")
+	auth := "nil"
+	if len(schemes) > 0 {
+		fmt.Fprintf(&b, "\tauth := authorizeGRPC(%s.NewAuthService())\n", pkg)
+		auth = "auth"
+	}
+	for _, svc := range hosted {
+		if len(svc.Methods) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\tsrv.RegisterService(&grpc.ServiceDesc{\n\t\tServiceName: %q,\n", GoGRPCServiceName(svc.Name))
+		b.WriteString("\t\tHandlerType: (*interface{})(nil),\n\t\tMethods: []grpc.MethodDesc{\n")
+		for _, m := range svc.Methods {
+			fmt.Fprintf(&b, "\t\t\t{MethodName: %q, Handler: grpcHandler(r, r.Service(%q).Method(%q), %q, %s)},\n",
+				Goify(m.Name, true), svc.Name, m.Name, GoGRPCMethodName(svc.Name, m.Name), auth)
+		}
+		b.WriteString("\t\t},\n\t}, nil)\n")
+	}
+	b.WriteString("\treturn srv\n}\n")
+	b.WriteString(goGRPCHandler)
+	return b.String()
+}
+
+// writeGoAuthorize writes the authorize and authorizeGRPC functions which
+// authorize the HTTP and gRPC requests with the hooks of GoAuther, the
+// requests must satisfy all the schemes of one of the security requirements
+// of the method.
+func writeGoAuthorize(b *strings.Builder, schemes []*expr.SchemeExpr, pkg string) {
+	b.WriteString("// authorize returns the function authorizing the HTTP requests with the\n")
+	b.WriteString("// Auther.\n")
+	fmt.Fprintf(b, "func authorize(a %s.Auther) runtime.AuthFunc {\n", pkg)
+	b.WriteString("\treturn func(req *http.Request, m *runtime.Method) (context.Context, error) {\n")
+	b.WriteString("\t\treturn satisfy(req.Context(), m, func(ctx context.Context, scheme string, scopes []string) (context.Context, error) {\n")
+	b.WriteString("\t\t\tswitch scheme {\n")
+	for _, s := range schemes {
+		fmt.Fprintf(b, "\t\t\tcase %q:\n\t\t\t\treturn %s.Auth%sHTTP(a, req.WithContext(ctx), scopes)\n", s.SchemeName, pkg, Goify(s.SchemeName, true))
+	}
+	b.WriteString("\t\t\t}\n\t\t\treturn nil, runtime.ErrUnauthorized\n\t\t})\n\t}\n}\n\n")
+
+	b.WriteString("// authorizeGRPC returns the function authorizing the gRPC requests with the\n")
+	b.WriteString("// Auther.\n")
+	fmt.Fprintf(b, "func authorizeGRPC(a %s.Auther) func(context.Context, *runtime.Method) (context.Context, error) {\n", pkg)
+	b.WriteString("\treturn func(ctx context.Context, m *runtime.Method) (context.Context, error) {\n")
+	b.WriteString("\t\treturn satisfy(ctx, m, func(ctx context.Context, scheme string, scopes []string) (context.Context, error) {\n")
+	b.WriteString("\t\t\tswitch scheme {\n")
+	for _, s := range schemes {
+		fmt.Fprintf(b, "\t\t\tcase %q:\n\t\t\t\treturn %s.Auth%sGRPC(ctx, a, scopes)\n", s.SchemeName, pkg, Goify(s.SchemeName, true))
+	}
+	b.WriteString("\t\t\t}\n\t\t\treturn nil, runtime.ErrUnauthorized\n\t\t})\n\t}\n}\n")
+	b.WriteString(goSatisfy)
+}
+
+// goSatisfy is the Go code checking the security requirements of the methods
+// independent of the schemes.
+const goSatisfy = `
+// satisfy returns the context of the request satisfying all the schemes of
+// one of the security requirements of the method, the schemes are checked
+// with check in turn.
+func satisfy(ctx context.Context, m *runtime.Method, check func(ctx context.Context, scheme string, scopes []string) (context.Context, error)) (context.Context, error) {
+	err := runtime.ErrUnauthorized
+	for _, sec := range m.Security {
+		sctx := ctx
+		for _, s := range sec.Schemes {
+			if s.SchemeName == runtime.NoSecurity {
+				err = nil
+				continue
+			}
+			if sctx, err = check(sctx, s.SchemeName, sec.Scopes); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			return sctx, nil
+		}
+	}
+	return nil, err
+}
+
+`
+
+// goGRPCHandler is the Go code of the gRPC handlers calling the methods with
+// the runtime independent of the services.
+const goGRPCHandler = `
+// grpcHandler returns the gRPC handler of the method with the given full
+// name, the requests of the methods having security requirements are
+// authorized with auth if not nil.
+func grpcHandler(r *runtime.Runtime, m *runtime.Method, name string, auth func(context.Context, *runtime.Method) (context.Context, error)) func(interface{}, context.Context, func(interface{}) error, grpc.UnaryServerInterceptor) (interface{}, error) {
+	call := func(ctx context.Context, p interface{}) (interface{}, error) {
+		if auth != nil && len(m.Security) > 0 {
+			var err error
+			if ctx, err = auth(ctx, m); err != nil {
+				return nil, grpcError(err, codes.Unauthenticated)
+			}
+		}
+		res, err := r.Call(ctx, m.Key(), p)
+		if err != nil {
+			return nil, grpcError(err, codes.Unknown)
+		}
+		return res, nil
+	}
+	return func(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		var p interface{}
+		if err := dec(&p); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if interceptor == nil {
+			return call(ctx, p)
+		}
+		return interceptor(ctx, p, &grpc.UnaryServerInfo{FullMethod: name}, call)
+	}
+}
+
+// grpcError returns the gRPC status error of the method error, the code of
+// the errors which are not declared in the specs defaults to code.
+func grpcError(err error, code codes.Code) error {
+	var er *runtime.ErrorResult
+	switch {
+	case errors.As(err, &er):
+		code = codes.Code(er.GRPCCode())
+	case errors.Is(err, runtime.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, runtime.ErrConflict):
+		code = codes.AlreadyExists
+	case errors.Is(err, runtime.ErrNotImplemented):
+		code = codes.Unimplemented
+	}
+	return status.Error(code, err.Error())
+}
+
+// jsonCodec encodes the gRPC messages with JSON, the numbers are decoded as
+// json.Number to keep the precision of the 64-bit integers.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+func (jsonCodec) Name() string                          { return "json" }
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+`
+
+// HostedServices returns the services hosted by the server, all the services
+// if the server doesn't list any.
+func HostedServices(svr *expr.ServerExpr, services []*runtime.Service) []*runtime.Service {
+	if len(svr.Services) == 0 {
+		return services
+	}
+	var res []*runtime.Service
+	for _, svc := range services {
+		for _, n := range svr.Services {
+			if svc.Name == n {
+				res = append(res, svc)
+				break
+			}
+		}
+	}
+	return res
+}
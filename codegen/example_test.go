@@ -0,0 +1,103 @@
+package codegen
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"go.zoe.im/goser/expr"
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+func TestGoExample(t *testing.T) {
+	spec, err := runtime.ParseSpec([]byte(`
+models:
+  User:
+    fields:
+      - name: name
+        type: string
+schemes:
+  jwt:
+    kind: jwt
+services:
+  users:
+    description: Manages the users.
+    methods:
+      create:
+        payload: User
+        result: User
+        security: [jwt]
+        errors:
+          conflict:
+            status: 409
+      ping:
+        description: Ping checks the service health.
+  teams:
+    methods:
+      list:
+        result: array<User>
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := runtime.New()
+	if err := r.Load(spec); err != nil {
+		t.Fatal(err)
+	}
+	svr := &expr.ServerExpr{
+		Name:     "usersvr",
+		Services: []string{"users"},
+		Hosts:    []*expr.HostExpr{{Name: "dev", URIs: []expr.URIExpr{"http://localhost:8080"}}},
+	}
+	impl := GoServiceImpl(r.Service("users"))
+	auth := GoAuthImpl(r.Schemes())
+	main := GoServerMain(svr) + GoHosts(svr) + GoExampleHandlers(svr, r.Services(), "users", []string{"name: `users`"})
+
+	cases := map[string]struct {
+		code        string
+		contains    []string
+		notContains []string
+	}{
+		"impl": {impl, []string{
+			"// UsersService implements the users service.\n//\n// Manages the users.\ntype UsersService struct{}\n",
+			"func NewUsersService() *UsersService {",
+			"// Create implements create.\n// The payload is a validated User.\n// The result must be a User.\n// It may return the errors \"conflict\".\nfunc (s *UsersService) Create(ctx context.Context, p interface{}) (interface{}, error) {\n\treturn nil, runtime.ErrNotImplemented\n}\n",
+			"// Ping checks the service health.\nfunc (s *UsersService) Ping(",
+		}, nil},
+		"auth": {auth, []string{
+			"type AuthService struct{}\n",
+			"func (a *AuthService) JWTAuth(ctx context.Context, token string, scopes []string) (context.Context, error) {\n\treturn nil, ErrUnauthorized\n}\n",
+		}, nil},
+		"main": {main, []string{
+			"\t\"name: `users`\",\n",
+			"func newRuntime() (*runtime.Runtime, error) {",
+			"\tusersSvc := users.NewUsersService()\n\tr.Handle(\"users.create\", usersSvc.Create)\n\tr.Handle(\"users.ping\", usersSvc.Ping)\n",
+			"\tr.Authorize(authorize(users.NewAuthService()))\n\treturn r, nil\n",
+			"signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)",
+			"grpcServer = newGRPCServer(r)",
+			"grpcServer.GracefulStop()",
+			"func authorize(a users.Auther) runtime.AuthFunc {",
+			"\t\t\tcase \"jwt\":\n\t\t\t\treturn users.AuthJWTHTTP(a, req.WithContext(ctx), scopes)\n",
+			"\t\t\tcase \"jwt\":\n\t\t\t\treturn users.AuthJWTGRPC(ctx, a, scopes)\n",
+			"\tauth := authorizeGRPC(users.NewAuthService())\n",
+			"\t\tServiceName: \"users.Users\",\n",
+			"\t\t\t{MethodName: \"Create\", Handler: grpcHandler(r, r.Service(\"users\").Method(\"create\"), \"/users.Users/Create\", auth)},\n",
+			"encoding.RegisterCodec(jsonCodec{})",
+		}, []string{"teams"}},
+	}
+	for k, tc := range cases {
+		if _, err := format.Source([]byte("package p\n\n" + tc.code)); err != nil {
+			t.Errorf("%s: invalid Go code: %v\n%s", k, err, tc.code)
+		}
+		for _, s := range tc.contains {
+			if !strings.Contains(tc.code, s) {
+				t.Errorf("%s: got\n%s\nexpected it to contain\n%s", k, tc.code, s)
+			}
+		}
+		for _, s := range tc.notContains {
+			if strings.Contains(tc.code, s) {
+				t.Errorf("%s: got\n%s\nexpected it not to contain %s", k, tc.code, s)
+			}
+		}
+	}
+}
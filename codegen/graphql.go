@@ -0,0 +1,374 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+// GraphQLField is a field of the GraphQL Query or Mutation type resolved
+// with a service method.
+type GraphQLField struct {
+	// Type is the GraphQL type of the field, "Query" or "Mutation"
+	Type string
+	// Name of the field
+	Name string
+	// Method resolving the field
+	Method *runtime.Method
+	// Input is true if the payload is the input argument, false if the
+	// arguments are the fields of the payload object
+	Input bool
+}
+
+// GraphQLFields returns the Query and Mutation fields of the methods with the
+// graphql:query or graphql:mutation meta. The field names default to the
+// camel case names of the services and methods, e.g. usersCreate. The payload
+// of mutations is the input argument, the payload object fields are the
+// arguments of queries.
+func GraphQLFields(services []*runtime.Service) []*GraphQLField {
+	var fields []*GraphQLField
+	for _, svc := range services {
+		for _, m := range svc.Methods {
+			op, name := expr.GraphQLOperation(m.Meta)
+			if op == "" {
+				continue
+			}
+			if name == "" {
+				name = Goify(svc.Name+"_"+m.Name, false)
+			}
+			f := &GraphQLField{Type: "Query", Name: name, Method: m, Input: true}
+			if op == "mutation" {
+				f.Type = "Mutation"
+			} else if m.Payload == nil || expr.AsObject(m.Payload.Type) != nil {
+				f.Input = false
+			}
+			fields = append(fields, f)
+		}
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields
+}
+
+// GraphQLSchema returns the GraphQL schema of the models, enums and fields:
+// models are object types and Writable models are also input types named
+// <Name>Input, enums are GraphQL enums unless their values aren't valid names.
+// Int64, UInt32 and UInt64 values are Int64 scalars, timestamps Time scalars
+// and maps, inline objects, unions and Any values JSON scalars. Methods
+// without result return a Boolean. An error is returned if an argument refers
+// to a model which is not writable or if there is no query.
+func GraphQLSchema(enums []*expr.EnumTypeExpr, models []*runtime.Model, fields []*GraphQLField) (string, error) {
+	s := &graphQLSchema{writable: map[string]bool{}, scalars: map[string]bool{}, enums: map[string]bool{}}
+	for _, e := range enums {
+		if graphQLEnum(e) {
+			s.enums[e.Name()] = true
+		}
+	}
+	for _, m := range models {
+		if m.Meta.Writable {
+			s.writable[m.Name] = true
+		}
+	}
+
+	var body strings.Builder
+	for _, e := range enums {
+		if !s.enums[e.Name()] {
+			continue
+		}
+		writeGraphQLDoc(&body, "", e.Description)
+		fmt.Fprintf(&body, "enum %s {\n", Goify(e.Name(), true))
+		for _, v := range e.Values {
+			writeGraphQLDoc(&body, "  ", v.Description)
+			fmt.Fprintf(&body, "  %s%s\n", v.Name, graphQLDeprecated(v.Meta))
+		}
+		body.WriteString("}\n\n")
+	}
+	for _, input := range []bool{false, true} {
+		for _, m := range models {
+			if input && !m.Meta.Writable {
+				continue
+			}
+			kind, name := "type", Goify(m.Name, true)
+			if input {
+				kind, name = "input", name+"Input"
+			}
+			writeGraphQLDoc(&body, "", m.Description)
+			fmt.Fprintf(&body, "%s %s {\n", kind, name)
+			obj := expr.AsObject(m.Type)
+			if obj == nil || len(*obj) == 0 {
+				s.scalars["JSON"] = true
+				body.WriteString("  _: JSON\n")
+			} else {
+				for _, nat := range *obj {
+					typ, err := s.typeName(nat.Attribute.Type, input)
+					if err != nil {
+						return "", fmt.Errorf("model %s field %s: %v", m.Name, nat.Name, err)
+					}
+					writeGraphQLDoc(&body, "  ", nat.Attribute.Description)
+					fmt.Fprintf(&body, "  %s: %s%s\n", nat.Name, graphQLRequired(typ, m.Type.IsRequired(nat.Name)), graphQLDeprecated(nat.Attribute.Meta))
+				}
+			}
+			body.WriteString("}\n\n")
+		}
+	}
+
+	var queries int
+	for _, typ := range []string{"Query", "Mutation"} {
+		var b strings.Builder
+		for _, f := range fields {
+			if f.Type != typ {
+				continue
+			}
+			if typ == "Query" {
+				queries++
+			}
+			args, err := s.arguments(f)
+			if err != nil {
+				return "", fmt.Errorf("%s %s: %v", strings.ToLower(typ), f.Name, err)
+			}
+			res := "Boolean"
+			if f.Method.Result != nil {
+				if res, err = s.typeName(f.Method.Result.Type, false); err != nil {
+					return "", fmt.Errorf("%s %s result: %v", strings.ToLower(typ), f.Name, err)
+				}
+			}
+			writeGraphQLDoc(&b, "  ", f.Method.Description)
+			fmt.Fprintf(&b, "  %s%s: %s%s\n", f.Name, args, res, graphQLDeprecated(f.Method.Meta))
+		}
+		if b.Len() > 0 {
+			fmt.Fprintf(&body, "type %s {\n%s}\n\n", typ, b.String())
+		}
+	}
+	if queries == 0 {
+		return "", fmt.Errorf("no method exposed with the %s meta, GraphQL schemas require a query", expr.GraphQLQueryMeta)
+	}
+
+	var b strings.Builder
+	for _, n := range []string{"Int64", "JSON", "Time"} {
+		if s.scalars[n] {
+			fmt.Fprintf(&b, "scalar %s\n", n)
+		}
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString(strings.TrimSuffix(body.String(), "\n"))
+	return b.String(), nil
+}
+
+// graphQLSchema maps the data types to GraphQL types.
+type graphQLSchema struct {
+	// writable models have input types
+	writable map[string]bool
+	// scalars used by the schema
+	scalars map[string]bool
+	// enums defined as GraphQL enums
+	enums map[string]bool
+}
+
+// typeName returns the GraphQL type of the data type, input is true for the
+// types of arguments and input fields.
+func (s *graphQLSchema) typeName(dt expr.DataType, input bool) (string, error) {
+	switch t := dt.(type) {
+	case expr.Primitive:
+		switch t {
+		case expr.Boolean:
+			return "Boolean", nil
+		case expr.Int, expr.Int32, expr.UInt:
+			return "Int", nil
+		case expr.Int64, expr.UInt32, expr.UInt64:
+			s.scalars["Int64"] = true
+			return "Int64", nil
+		case expr.Float32, expr.Float64:
+			return "Float", nil
+		case expr.Timestamp:
+			s.scalars["Time"] = true
+			return "Time", nil
+		case expr.Any:
+			s.scalars["JSON"] = true
+			return "JSON", nil
+		}
+		return "String", nil
+	case *expr.Array:
+		elem, err := s.typeName(t.ElemType.Type, input)
+		if err != nil {
+			return "", err
+		}
+		return "[" + elem + "!]", nil
+	case *expr.EnumTypeExpr:
+		if s.enums[t.Name()] {
+			return Goify(t.Name(), true), nil
+		}
+		return s.typeName(t.Type, input)
+	case expr.UserType:
+		if expr.AsObject(t) == nil {
+			return s.typeName(t.Attribute().Type, input)
+		}
+		if !input {
+			return Goify(t.Name(), true), nil
+		}
+		if !s.writable[t.Name()] {
+			return "", fmt.Errorf("model %s is not writable", t.Name())
+		}
+		return Goify(t.Name(), true) + "Input", nil
+	}
+	s.scalars["JSON"] = true
+	return "JSON", nil
+}
+
+// arguments returns the arguments of the field including the parentheses,
+// empty if the method has no payload.
+func (s *graphQLSchema) arguments(f *GraphQLField) (string, error) {
+	m := f.Method
+	if m.Payload == nil {
+		return "", nil
+	}
+	if f.Input {
+		typ, err := s.typeName(m.Payload.Type, true)
+		if err != nil {
+			return "", err
+		}
+		return "(input: " + typ + "!)", nil
+	}
+	obj := expr.AsObject(m.Payload.Type)
+	args := make([]string, len(*obj))
+	for i, nat := range *obj {
+		typ, err := s.typeName(nat.Attribute.Type, true)
+		if err != nil {
+			return "", fmt.Errorf("argument %s: %v", nat.Name, err)
+		}
+		args[i] = nat.Name + ": " + graphQLRequired(typ, m.Payload.IsRequired(nat.Name))
+	}
+	if len(args) == 0 {
+		return "", nil
+	}
+	return "(" + strings.Join(args, ", ") + ")", nil
+}
+
+// graphQLEnum returns true if the values of the enum are valid GraphQL enum
+// values, i.e. string values which are valid names.
+func graphQLEnum(e *expr.EnumTypeExpr) bool {
+	for _, v := range e.Values {
+		s, ok := v.Value.(string)
+		if !ok || s != v.Name || !graphQLName(s) || s == "true" || s == "false" || s == "null" {
+			return false
+		}
+	}
+	return len(e.Values) > 0
+}
+
+// graphQLName returns true if s is a valid GraphQL name.
+func graphQLName(s string) bool {
+	for i, r := range s {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// graphQLRequired returns the type non-null if required.
+func graphQLRequired(typ string, required bool) string {
+	if required {
+		return typ + "!"
+	}
+	return typ
+}
+
+// graphQLDeprecated returns the deprecated directive of the element with the
+// given meta, empty if the element is not deprecated.
+func graphQLDeprecated(meta expr.MetaExpr) string {
+	d := expr.Deprecation(meta)
+	if d == nil {
+		return ""
+	}
+	if d.Reason == "" {
+		return " @deprecated"
+	}
+	return fmt.Sprintf(" @deprecated(reason: %s)", tsString(d.Reason))
+}
+
+// writeGraphQLDoc writes the description as a GraphQL block string.
+func writeGraphQLDoc(b *strings.Builder, indent, desc string) {
+	desc = strings.Replace(strings.TrimSpace(desc), `"""`, `\"""`, -1)
+	if desc == "" {
+		return
+	}
+	if !strings.Contains(desc, "\n") {
+		fmt.Fprintf(b, "%s\"\"\"%s\"\"\"\n", indent, desc)
+		return
+	}
+	fmt.Fprintf(b, "%s\"\"\"\n", indent)
+	for _, l := range strings.Split(desc, "\n") {
+		b.WriteString(strings.TrimRight(indent+l, " ") + "\n")
+	}
+	fmt.Fprintf(b, "%s\"\"\"\n", indent)
+}
+
+// GoGraphQLResolvers returns the Go code of the resolvers of the fields: the
+// QueryResolver and MutationResolver interfaces with a method per field, and
+// the Resolver implementing them by calling the methods with the runtime, the
+// methods are thus handled by the same service implementations as the HTTP
+// and gRPC requests. Resolver.Resolve dispatches the fields by name to be
+// used with GraphQL libraries. The generated code uses the context, fmt and
+// go.zoe.im/goser/pkg/runtime packages.
+func GoGraphQLResolvers(fields []*GraphQLField) string {
+	var b strings.Builder
+	for _, typ := range []string{"Query", "Mutation"} {
+		fmt.Fprintf(&b, "// %sResolver resolves the fields of the GraphQL %s type.\n", typ, typ)
+		fmt.Fprintf(&b, "type %sResolver interface {\n", typ)
+		for _, f := range fields {
+			if f.Type != typ {
+				continue
+			}
+			fmt.Fprintf(&b, "\t// %s resolves the %s field with %s.\n", Goify(f.Name, true), f.Name, f.Method.Key())
+			fmt.Fprintf(&b, "\t%s(ctx context.Context, args map[string]interface{}) (interface{}, error)\n", Goify(f.Name, true))
+		}
+		b.WriteString("}\n\n")
+	}
+
+	b.WriteString("// Resolver implements QueryResolver and MutationResolver by calling the\n")
+	b.WriteString("// service methods with the runtime, the payloads are validated and the\n")
+	b.WriteString("// methods handled by the handlers registered with the runtime.\n")
+	b.WriteString("type Resolver struct {\n\tr *runtime.Runtime\n}\n\n")
+	b.WriteString("// NewResolver returns the resolver calling the methods with r.\n")
+	b.WriteString("func NewResolver(r *runtime.Runtime) *Resolver {\n\treturn &Resolver{r: r}\n}\n\n")
+
+	b.WriteString("// Resolve resolves the field of the Query or Mutation type with the given\n// arguments.\n")
+	b.WriteString("func (res *Resolver) Resolve(ctx context.Context, typ, field string, args map[string]interface{}) (interface{}, error) {\n")
+	if len(fields) > 0 {
+		b.WriteString("\tswitch typ + \".\" + field {\n")
+		for _, f := range fields {
+			fmt.Fprintf(&b, "\tcase %q:\n\t\treturn res.%s(ctx, args)\n", f.Type+"."+f.Name, Goify(f.Name, true))
+		}
+		b.WriteString("\t}\n")
+	}
+	b.WriteString("\treturn nil, fmt.Errorf(\"unknown field %s.%s\", typ, field)\n}\n")
+
+	for _, f := range fields {
+		m := f.Method
+		name := Goify(f.Name, true)
+		payload := "nil"
+		if m.Payload != nil {
+			payload = "args"
+			if f.Input {
+				payload = `args["input"]`
+			}
+		}
+		fmt.Fprintf(&b, "\n// %s implements %sResolver.\n", name, f.Type)
+		fmt.Fprintf(&b, "func (res *Resolver) %s(ctx context.Context, args map[string]interface{}) (interface{}, error) {\n", name)
+		if m.Result == nil {
+			fmt.Fprintf(&b, "\tif _, err := res.r.Call(ctx, %q, %s); err != nil {\n\t\treturn nil, err\n\t}\n\treturn true, nil\n}\n", m.Key(), payload)
+			continue
+		}
+		fmt.Fprintf(&b, "\treturn res.r.Call(ctx, %q, %s)\n}\n", m.Key(), payload)
+	}
+
+	b.WriteString("\nvar (\n\t_ QueryResolver    = (*Resolver)(nil)\n\t_ MutationResolver = (*Resolver)(nil)\n)\n")
+	return b.String()
+}
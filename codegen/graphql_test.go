@@ -0,0 +1,128 @@
+package codegen
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+func TestGraphQL(t *testing.T) {
+	spec, err := runtime.ParseSpec([]byte(`
+enums:
+  Role:
+    values: [admin, member]
+  Level:
+    type: int
+    values: [1, 2]
+models:
+  User:
+    description: User is a member of a team.
+    _:
+      writable: true
+    fields:
+      - name: id
+        type: int64
+        required: true
+      - name: name
+        type: string
+        required: true
+        deprecated: use display_name
+      - name: role
+        type: Role
+      - name: level
+        type: Level
+      - name: labels
+        type: map<string, string>
+  UserQuery:
+    _:
+      writable: true
+    fields:
+      - name: id
+        type: int64
+        required: true
+  Team:
+    fields:
+      - name: members
+        type: array<User>
+services:
+  users:
+    methods:
+      get:
+        description: Get a user by ID.
+        payload: UserQuery
+        result: User
+        meta:
+          graphql:query: []
+      create:
+        payload: User
+        result: User
+        meta:
+          graphql:mutation: [addUser]
+      delete:
+        payload: UserQuery
+        meta:
+          graphql:mutation: []
+      ping: {}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := runtime.New()
+	if err := r.Load(spec); err != nil {
+		t.Fatal(err)
+	}
+	fields := GraphQLFields(r.Services())
+	if len(fields) != 3 {
+		t.Fatalf("got %d fields, expected 3", len(fields))
+	}
+	schema, err := GraphQLSchema(r.Enums(), r.Models(), fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolvers := GoGraphQLResolvers(fields)
+	if _, err := format.Source([]byte("package p\n\n" + resolvers)); err != nil {
+		t.Errorf("invalid Go code: %v\n%s", err, resolvers)
+	}
+
+	cases := map[string]struct {
+		code        string
+		contains    []string
+		notContains []string
+	}{
+		"schema": {schema, []string{
+			"scalar Int64\nscalar JSON\n\n",
+			"enum Role {\n  admin\n  member\n}\n",
+			"\"\"\"User is a member of a team.\"\"\"\ntype User {\n  id: Int64!\n  name: String! @deprecated(reason: \"use display_name\")\n  role: Role\n  level: Int\n  labels: JSON\n}\n",
+			"type Team {\n  members: [User!]\n}\n",
+			"input UserInput {\n  id: Int64!\n",
+			"type Query {\n  \"\"\"Get a user by ID.\"\"\"\n  usersGet(id: Int64!): User\n}\n",
+			"type Mutation {\n  addUser(input: UserInput!): User\n  usersDelete(input: UserQueryInput!): Boolean\n}",
+		}, []string{"enum Level", "input TeamInput", "ping"}},
+		"resolvers": {resolvers, []string{
+			"type QueryResolver interface {\n\t// UsersGet resolves the usersGet field with users.get.\n\tUsersGet(ctx context.Context, args map[string]interface{}) (interface{}, error)\n}\n",
+			"\tcase \"Mutation.addUser\":\n\t\treturn res.AddUser(ctx, args)\n",
+			"func (res *Resolver) UsersGet(ctx context.Context, args map[string]interface{}) (interface{}, error) {\n\treturn res.r.Call(ctx, \"users.get\", args)\n}\n",
+			"\treturn res.r.Call(ctx, \"users.create\", args[\"input\"])\n",
+			"\tif _, err := res.r.Call(ctx, \"users.delete\", args[\"input\"]); err != nil {\n\t\treturn nil, err\n\t}\n\treturn true, nil\n",
+		}, nil},
+	}
+	r.Model("UserQuery").Meta.Writable = false
+	if _, err := GraphQLSchema(r.Enums(), r.Models(), fields); err == nil {
+		t.Errorf("expected an error for the mutation input which is not writable")
+	}
+
+	for k, tc := range cases {
+		for _, s := range tc.contains {
+			if !strings.Contains(tc.code, s) {
+				t.Errorf("%s: got\n%s\nexpected it to contain\n%s", k, tc.code, s)
+			}
+		}
+		for _, s := range tc.notContains {
+			if strings.Contains(tc.code, s) {
+				t.Errorf("%s: got\n%s\nexpected it not to contain %s", k, tc.code, s)
+			}
+		}
+	}
+}
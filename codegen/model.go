@@ -0,0 +1,238 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+)
+
+// GoModel returns the Go struct of the object user type, the fields are
+// tagged with their JSON names and omitted if empty unless required. The
+// fields of object user types are pointers so that recursive types can be
+// defined, the optional timestamp and union fields are pointers so that they
+// can be omitted. The generated code uses the packages of the field types,
+// e.g. time or math/big.
+func GoModel(ut expr.UserType) string {
+	var (
+		b    strings.Builder
+		name = Goify(ut.Name(), true)
+		att  = ut.Attribute()
+	)
+	writeDoc(&b, "", att.Description, name+" is the "+ut.Name()+" model.", att.Meta)
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	if obj := expr.AsObject(ut); obj != nil {
+		for _, nat := range *obj {
+			writeDoc(&b, "\t", nat.Attribute.Description, "", nat.Attribute.Meta)
+			tag := nat.Name
+			if !att.IsRequired(nat.Name) {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&b, "\t%s %s `json:%q`\n", Goify(nat.Name, true), goFieldType(nat.Attribute.Type, att.IsRequired(nat.Name)), tag)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// goFieldType returns the Go type of the struct fields of the data type,
+// omitempty has no effect on the struct types of the optional fields.
+func goFieldType(dt expr.DataType, required bool) string {
+	if _, ok := dt.(expr.UserType); ok && expr.IsObject(dt) {
+		return "*" + GoTypeName(dt)
+	}
+	if !required && (dt == expr.Timestamp || expr.AsUnion(dt) != nil && GoTypeName(dt) != "interface{}") {
+		return "*" + GoTypeName(dt)
+	}
+	return GoTypeName(dt)
+}
+
+// ProtoMessage returns the proto3 message of the object user type, the field
+// numbers are given by the "rpc:tag" meta of the fields and default to their
+// position starting at 1.
+func ProtoMessage(ut expr.UserType) string {
+	var (
+		b   strings.Builder
+		att = ut.Attribute()
+	)
+	writeComment(&b, "", att.Description, "")
+	fmt.Fprintf(&b, "message %s {\n", Goify(ut.Name(), true))
+	if expr.Deprecation(att.Meta) != nil {
+		b.WriteString("  option deprecated = true;\n")
+	}
+	if obj := expr.AsObject(ut); obj != nil {
+		for i, nat := range *obj {
+			tag := fmt.Sprintf("%d", i+1)
+			if t, ok := nat.Attribute.Meta["rpc:tag"]; ok && len(t) > 0 {
+				tag = t[0]
+			}
+			typ := ProtoTypeName(nat.Attribute.Type)
+			if expr.IsArray(nat.Attribute.Type) {
+				typ = "repeated " + typ
+			}
+			writeComment(&b, "  ", nat.Attribute.Description, "")
+			fmt.Fprintf(&b, "  %s %s = %s%s;\n", typ, SnakeCase(nat.Name), tag, ProtoDeprecatedOption(nat.Attribute.Meta))
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// ProtoFile returns the content of the proto3 file of the package with the
+// given definitions, the well-known types used by the definitions are
+// imported.
+func ProtoFile(pkg string, defs ...string) string {
+	var (
+		b       strings.Builder
+		content = strings.Join(defs, "\n")
+	)
+	b.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&b, "package %s;\n\n", pkg)
+	var imports []string
+	for _, wkt := range []string{"Any", "Duration", "Struct", "Timestamp"} {
+		if strings.Contains(content, "google.protobuf."+wkt) {
+			imports = append(imports, "google/protobuf/"+strings.ToLower(wkt)+".proto")
+		}
+	}
+	for _, imp := range imports {
+		fmt.Fprintf(&b, "import %q;\n", imp)
+	}
+	if len(imports) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString(content)
+	return b.String()
+}
+
+// OpenAPIModelSchema returns the OpenAPI object schema of the user type,
+// the user types of the fields are referred to in the components section.
+func OpenAPIModelSchema(ut expr.UserType) map[string]interface{} {
+	var (
+		att   = ut.Attribute()
+		props = make(map[string]interface{})
+	)
+	if obj := expr.AsObject(ut); obj != nil {
+		for _, nat := range *obj {
+			props[nat.Name] = openAPIFieldSchema(nat.Attribute)
+		}
+	}
+	schema := map[string]interface{}{"type": "object", "properties": props}
+	if req := att.AllRequired(); len(req) > 0 {
+		schema["required"] = req
+	}
+	if att.Description != "" {
+		schema["description"] = att.Description
+	}
+	if expr.Deprecation(att.Meta) != nil {
+		schema["deprecated"] = true
+	}
+	return schema
+}
+
+// openAPIFieldSchema returns the OpenAPI schema of the field with its
+// description, default value, example and validations. The schemas of user
+// types are references which cannot be combined with other properties.
+func openAPIFieldSchema(att *expr.AttributeExpr) map[string]interface{} {
+	schema := openAPISchemaRef(att.Type)
+	if _, ok := schema["$ref"]; ok {
+		return schema
+	}
+	if att.Description != "" {
+		schema["description"] = att.Description
+	}
+	if expr.Deprecation(att.Meta) != nil {
+		schema["deprecated"] = true
+	}
+	if att.DefaultValue != nil {
+		schema["default"] = att.DefaultValue
+	}
+	if ex := att.ExtractUserExamples(); len(ex) > 0 {
+		schema["example"] = ex[len(ex)-1].Value
+	}
+	v := att.Validation
+	if v == nil {
+		return schema
+	}
+	if len(v.Values) > 0 {
+		schema["enum"] = v.Values
+	}
+	if v.Format != "" {
+		schema["format"] = string(v.Format)
+	}
+	if v.Pattern != "" {
+		schema["pattern"] = v.Pattern
+	}
+	if v.Minimum != nil {
+		schema["minimum"] = *v.Minimum
+	}
+	if v.Maximum != nil {
+		schema["maximum"] = *v.Maximum
+	}
+	min, max := "minLength", "maxLength"
+	if expr.IsArray(att.Type) {
+		min, max = "minItems", "maxItems"
+	} else if expr.IsMap(att.Type) {
+		min, max = "minProperties", "maxProperties"
+	}
+	if v.MinLength != nil {
+		schema[min] = *v.MinLength
+	}
+	if v.MaxLength != nil {
+		schema[max] = *v.MaxLength
+	}
+	return schema
+}
+
+// SQLTable returns the SQL table of the object user type, the table and the
+// columns are named after the type and the fields in snake case and quoted so
+// that reserved words such as "order" can be used. The required fields are NOT
+// NULL, the id field is the primary key and the enum fields are checked
+// against the enum values. The deprecation of the type is given in the comment
+// of the table.
+func SQLTable(ut expr.UserType) string {
+	var (
+		b    strings.Builder
+		att  = ut.Attribute()
+		cols []string
+	)
+	if obj := expr.AsObject(ut); obj != nil {
+		for _, nat := range *obj {
+			col := sqlIdent(SnakeCase(nat.Name)) + " " + SQLColumnType(nat.Attribute)
+			if nat.Name == "id" {
+				col += " PRIMARY KEY"
+			} else if att.IsRequired(nat.Name) {
+				col += " NOT NULL"
+			}
+			if e := expr.AsEnum(nat.Attribute.Type); e != nil {
+				col += " " + SQLEnumCheck(sqlIdent(SnakeCase(nat.Name)), e)
+			}
+			cols = append(cols, col)
+		}
+	}
+	desc := att.Description
+	if dep := GoDeprecation(att.Meta); dep != "" {
+		desc = strings.TrimSpace(desc + "\n" + dep)
+	}
+	writeSQLComment(&b, desc)
+	fmt.Fprintf(&b, "CREATE TABLE %s (\n", sqlIdent(SnakeCase(ut.Name())))
+	if len(cols) > 0 {
+		b.WriteString("  " + strings.Join(cols, ",\n  ") + "\n")
+	}
+	b.WriteString(");\n")
+	return b.String()
+}
+
+// sqlIdent returns the quoted SQL identifier.
+func sqlIdent(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// writeSQLComment writes the description as a SQL comment.
+func writeSQLComment(b *strings.Builder, desc string) {
+	if desc == "" {
+		return
+	}
+	for _, l := range strings.Split(strings.TrimSpace(desc), "\n") {
+		b.WriteString(strings.TrimRight("-- "+l, " ") + "\n")
+	}
+}
@@ -0,0 +1,169 @@
+package codegen
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+func TestModels(t *testing.T) {
+	spec, err := runtime.ParseSpec([]byte(`
+name: shop
+version: v1
+enums:
+  Status:
+    values: [open, "won't ship"]
+unions:
+  Payment:
+    values:
+      - name: item
+        type: Item
+      - name: code
+        type: string
+models:
+  Order:
+    description: Order of a customer.
+    fields:
+      - name: id
+        type: string
+        required: true
+      - name: created_at
+        type: timestamp
+        required: true
+      - name: day
+        type: date
+      - name: delay
+        type: duration
+      - name: total
+        type: decimal
+        minimum: 0
+      - name: count
+        type: bigint
+      - name: items
+        type: array<Item>
+      - name: note
+        type: string
+        deprecated: use comment
+      - name: status
+        type: Status
+        required: true
+      - name: payment
+        type: Payment
+      - name: shipped_at
+        type: timestamp
+  Item:
+    fields:
+      - name: sku
+        type: string
+        required: true
+schemes:
+  jwt:
+    kind: jwt
+    scopes:
+      read: Read the orders
+services:
+  orders:
+    methods:
+      get:
+        payload: Order
+        result: Order
+        http: GET /orders/{id}
+        security:
+          - schemes: [jwt]
+            scopes: [read]
+      create:
+        payload: Order
+        http: POST /orders
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := runtime.New()
+	if err := r.Load(spec); err != nil {
+		t.Fatal(err)
+	}
+	order := r.Model("Order").Type
+
+	cases := map[string]struct {
+		actual   string
+		expected []string
+	}{
+		"go": {GoModel(order), []string{
+			"// Order of a customer.\ntype Order struct {",
+			"ID string `json:\"id\"`",
+			"CreatedAt time.Time `json:\"created_at\"`",
+			"Day string `json:\"day,omitempty\"`",
+			"Delay string `json:\"delay,omitempty\"`",
+			"Total string `json:\"total,omitempty\"`",
+			"Count *big.Int `json:\"count,omitempty\"`",
+			"Items []Item `json:\"items,omitempty\"`",
+			"// Deprecated: use comment\n\tNote string",
+			"Status Status `json:\"status\"`",
+			"Payment *Payment `json:\"payment,omitempty\"`",
+			"ShippedAt *time.Time `json:\"shipped_at,omitempty\"`",
+		}},
+		"proto": {ProtoFile("shop", ProtoMessage(order)), []string{
+			`import "google/protobuf/duration.proto";`,
+			`import "google/protobuf/timestamp.proto";`,
+			"message Order {",
+			"  google.protobuf.Timestamp created_at = 2;",
+			"  google.protobuf.Duration delay = 4;",
+			"  repeated Item items = 7;",
+			"  string note = 8 [deprecated = true];",
+			"  Status status = 9;",
+			"  Payment payment = 10;",
+		}},
+		"sql": {SQLTable(order), []string{
+			"-- Order of a customer.\nCREATE TABLE \"order\" (",
+			"  \"id\" TEXT PRIMARY KEY,",
+			"  \"created_at\" TIMESTAMP NOT NULL,",
+			"  \"day\" DATE,",
+			"  \"delay\" INTERVAL,",
+			"  \"total\" NUMERIC,",
+			"  \"status\" TEXT NOT NULL CHECK (\"status\" IN ('open', 'won''t ship')),",
+			"  \"payment\" JSON,\n  \"shipped_at\" TIMESTAMP\n);",
+		}},
+	}
+	for k, tc := range cases {
+		for _, e := range tc.expected {
+			if !strings.Contains(tc.actual, e) {
+				t.Errorf("%s: %q not found in\n%s", k, e, tc.actual)
+			}
+		}
+	}
+
+	schemas := map[string]interface{}{
+		"Order":   OpenAPIModelSchema(order),
+		"Status":  OpenAPIEnumSchema(r.Enum("Status")),
+		"Payment": OpenAPIUnionSchema(r.Union("Payment")),
+	}
+	data, err := json.Marshal(OpenAPIDocument(r.API(), "v1", r.AtVersion("v1").Services(), schemas))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := string(data)
+	for _, e := range []string{
+		`"/v1/orders/{id}":{"get":`,
+		`"/v1/orders":{"post":`,
+		`"operationId":"orders.get"`,
+		`{"in":"path","name":"id","required":true,"schema":{"type":"string"}}`,
+		`"created_at":{"format":"date-time","type":"string"}`,
+		`"delay":{"format":"duration","type":"string"}`,
+		`"total":{"format":"decimal","minimum":0,"type":"string"}`,
+		`"count":{"format":"bigint","type":"string"}`,
+		`"items":{"items":{"$ref":"#/components/schemas/Item"},"type":"array"}`,
+		`"204":{"description":"No Content"}`,
+		`"status":{"$ref":"#/components/schemas/Status"}`,
+		`"Status":{"enum":["open","won't ship"],"type":"string"}`,
+		`"payment":{"$ref":"#/components/schemas/Payment"}`,
+		`"Payment":{"oneOf":[{"$ref":"#/components/schemas/Item"},{"type":"string"}]}`,
+		`"security":[{"jwt":["read"]}]`,
+		`"securitySchemes":{"jwt":{"bearerFormat":"JWT","scheme":"bearer","type":"http"}}`,
+	} {
+		if !strings.Contains(doc, e) {
+			t.Errorf("%s not found in\n%s", e, doc)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package codegen
+
+import (
+	"strings"
+	"unicode"
+)
+
+// acronyms lists the common initialisms which are kept in upper case by
+// Goify, see https://github.com/golang/lint/blob/master/lint.go
+var acronyms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true,
+	"DNS": true, "EOF": true, "GUID": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "JWT": true,
+	"LHS": true, "QPS": true, "RAM": true, "RHS": true, "RPC": true,
+	"SLA": true, "SMTP": true, "SQL": true, "SSH": true, "TCP": true,
+	"TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true,
+	"UUID": true, "URI": true, "URL": true, "UTF8": true, "VM": true,
+	"XML": true, "XMPP": true, "XSRF": true, "XSS": true,
+}
+
+// Goify makes a valid Go identifier out of any string. It does that by
+// removing any non letter and non digit character and by making sure the
+// first character is a letter or "_". Goify produces a "CamelCase" version of
+// the string, if firstUpper is true the first character of the identifier is
+// uppercase otherwise it's lowercase.
+func Goify(str string, firstUpper bool) string {
+	words := splitWords(str)
+	if len(words) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for i, w := range words {
+		upper := strings.ToUpper(w)
+		switch {
+		case i == 0 && !firstUpper:
+			b.WriteString(strings.ToLower(w))
+		case acronyms[upper]:
+			b.WriteString(upper)
+		default:
+			b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+	}
+	res := b.String()
+	if unicode.IsDigit(rune(res[0])) {
+		res = "_" + res
+	}
+	return res
+}
+
+// SnakeCase produces the snake_case version of the given CamelCase string.
+func SnakeCase(str string) string {
+	words := splitWords(str)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, "_")
+}
+
+// splitWords splits the string on non letter and non digit characters and
+// on case changes, e.g. "userID" and "user_id" both give "user" and "ID".
+func splitWords(str string) []string {
+	var (
+		words []string
+		cur   []rune
+	)
+	runes := []rune(str)
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, string(cur))
+			cur = nil
+		}
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(cur) > 0 {
+			prev := cur[len(cur)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return words
+}
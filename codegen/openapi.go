@@ -0,0 +1,165 @@
+package codegen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+// OpenAPIDocument returns the OpenAPI 3 document of the API version with an
+// operation per method and the given schemas as components. The services of a
+// versioned API are the services at the version whose routes are prefixed
+// with it, see runtime.Runtime.AtVersion. The path params and, for GET and
+// HEAD requests, the other scalar fields of the payloads are parameters, the
+// payloads of the other requests are JSON bodies. The security schemes
+// required by the methods are listed in the components.
+func OpenAPIDocument(api *runtime.API, version string, services []*runtime.Service, schemas map[string]interface{}) map[string]interface{} {
+	info := map[string]interface{}{"title": api.Name, "version": version}
+	if version == "" {
+		info["version"] = "1.0"
+	}
+	if api.Description != "" {
+		info["description"] = api.Description
+	}
+	if api.TermsOfService != "" {
+		info["termsOfService"] = api.TermsOfService
+	}
+	if c := api.Contact; c != nil {
+		info["contact"] = openAPIObject("name", c.Name, "email", c.Email, "url", c.URL)
+	}
+	if l := api.License; l != nil {
+		info["license"] = openAPIObject("name", l.Name, "url", l.URL)
+	}
+
+	var (
+		paths = make(map[string]interface{})
+		reqs  [][]*expr.SecurityExpr
+	)
+	for _, svc := range services {
+		for _, m := range svc.Methods {
+			reqs = append(reqs, m.Security)
+			item, ok := paths[m.Route.Path].(map[string]interface{})
+			if !ok {
+				item = make(map[string]interface{})
+				paths[m.Route.Path] = item
+			}
+			item[strings.ToLower(m.Route.Method)] = openAPIOperation(m)
+		}
+	}
+
+	components := map[string]interface{}{"schemas": schemas}
+	if schemes := expr.Schemes(reqs...); len(schemes) > 0 {
+		components["securitySchemes"] = OpenAPISecuritySchemes(schemes)
+	}
+	doc := map[string]interface{}{
+		"openapi":    "3.0.3",
+		"info":       info,
+		"paths":      paths,
+		"components": components,
+	}
+	if api.Docs != nil {
+		doc["externalDocs"] = openAPIObject("description", api.Docs.Description, "url", api.Docs.URL)
+	}
+	return doc
+}
+
+// pathParams matches the params of the route paths.
+var pathParams = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// openAPIOperation returns the OpenAPI operation of the method.
+func openAPIOperation(m *runtime.Method) map[string]interface{} {
+	op := map[string]interface{}{
+		"operationId": m.Key(),
+		"tags":        []string{m.Service},
+	}
+	if m.Description != "" {
+		op["description"] = m.Description
+	}
+	if expr.Deprecation(m.Meta) != nil {
+		op["deprecated"] = true
+	}
+	if len(m.Security) > 0 {
+		op["security"] = OpenAPISecurity(m.Security)
+	}
+
+	var params []interface{}
+	inPath := make(map[string]bool)
+	for _, match := range pathParams.FindAllStringSubmatch(m.Route.Path, -1) {
+		name := match[1]
+		inPath[name] = true
+		schema := map[string]interface{}{"type": "string"}
+		if m.Payload != nil {
+			if att := m.Payload.Find(name); att != nil {
+				schema = openAPIFieldSchema(att)
+			}
+		}
+		params = append(params, map[string]interface{}{
+			"name": name, "in": "path", "required": true, "schema": schema,
+		})
+	}
+	if m.Payload != nil {
+		if m.Route.Method == "GET" || m.Route.Method == "HEAD" {
+			if obj := expr.AsObject(m.Payload.Type); obj != nil {
+				for _, nat := range *obj {
+					if inPath[nat.Name] || !expr.IsPrimitive(nat.Attribute.Type) {
+						continue
+					}
+					params = append(params, map[string]interface{}{
+						"name": nat.Name, "in": "query", "required": m.Payload.IsRequired(nat.Name),
+						"schema": openAPIFieldSchema(nat.Attribute),
+					})
+				}
+			}
+		} else {
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  openAPIContent(m.Payload),
+			}
+		}
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	responses := make(map[string]interface{})
+	if m.Result != nil {
+		responses["200"] = map[string]interface{}{"description": "OK", "content": openAPIContent(m.Result)}
+	} else {
+		responses["204"] = map[string]interface{}{"description": "No Content"}
+	}
+	for _, e := range m.Errors {
+		status := fmt.Sprintf("%d", e.HTTPStatus())
+		desc := e.Name
+		if e.Description != "" {
+			desc += ": " + e.Description
+		}
+		if res, ok := responses[status].(map[string]interface{}); ok {
+			desc = res["description"].(string) + "\n" + desc
+		}
+		responses[status] = map[string]interface{}{"description": desc}
+	}
+	op["responses"] = responses
+	return op
+}
+
+// openAPIContent returns the JSON content of the request or response body.
+func openAPIContent(att *expr.AttributeExpr) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{"schema": openAPIFieldSchema(att)},
+	}
+}
+
+// openAPIObject returns the object made of the key value pairs, the empty
+// values are omitted.
+func openAPIObject(kvs ...string) map[string]interface{} {
+	res := make(map[string]interface{}, len(kvs)/2)
+	for i := 0; i+1 < len(kvs); i += 2 {
+		if kvs[i+1] != "" {
+			res[kvs[i]] = kvs[i+1]
+		}
+	}
+	return res
+}
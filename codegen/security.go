@@ -0,0 +1,224 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+)
+
+// OpenAPISecuritySchemes returns the OpenAPI securitySchemes of the
+// components section keyed by scheme name.
+func OpenAPISecuritySchemes(schemes []*expr.SchemeExpr) map[string]interface{} {
+	res := make(map[string]interface{}, len(schemes))
+	for _, s := range schemes {
+		var schema map[string]interface{}
+		switch s.Kind {
+		case expr.BasicAuthKind:
+			schema = map[string]interface{}{"type": "http", "scheme": "basic"}
+		case expr.APIKeyKind:
+			schema = map[string]interface{}{"type": "apiKey", "in": s.In, "name": s.Name}
+		case expr.JWTKind:
+			schema = map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"}
+		case expr.OAuth2Kind:
+			flows := make(map[string]interface{}, len(s.Flows))
+			for _, f := range s.Flows {
+				flows[openAPIFlowName(f)] = openAPIFlow(s, f)
+			}
+			schema = map[string]interface{}{"type": "oauth2", "flows": flows}
+		default:
+			continue
+		}
+		if s.Description != "" {
+			schema["description"] = s.Description
+		}
+		res[s.SchemeName] = schema
+	}
+	return res
+}
+
+// OpenAPISecurity returns the OpenAPI security requirements, each requirement
+// maps the names of its schemes to the required scopes. A NoSecurity
+// requirement is rendered as an empty object which makes the security
+// optional.
+func OpenAPISecurity(reqs []*expr.SecurityExpr) []interface{} {
+	res := make([]interface{}, 0, len(reqs))
+	for _, r := range reqs {
+		req := make(map[string]interface{}, len(r.Schemes))
+		for _, s := range r.Schemes {
+			if s.Kind == expr.NoKind {
+				continue
+			}
+			scopes := []string{}
+			if s.Kind == expr.OAuth2Kind || s.Kind == expr.JWTKind {
+				for _, sc := range r.Scopes {
+					if s.Scope(sc) != nil {
+						scopes = append(scopes, sc)
+					}
+				}
+			}
+			req[s.SchemeName] = scopes
+		}
+		res = append(res, req)
+	}
+	return res
+}
+
+// openAPIFlowName returns the name of the OAuth2 flow in OpenAPI documents.
+func openAPIFlowName(f *expr.FlowExpr) string {
+	switch f.Kind {
+	case expr.AuthorizationCodeFlowKind:
+		return "authorizationCode"
+	case expr.ImplicitFlowKind:
+		return "implicit"
+	case expr.PasswordFlowKind:
+		return "password"
+	}
+	return "clientCredentials"
+}
+
+// openAPIFlow returns the OpenAPI OAuth2 flow object listing the scopes of
+// the scheme.
+func openAPIFlow(s *expr.SchemeExpr, f *expr.FlowExpr) map[string]interface{} {
+	scopes := make(map[string]interface{}, len(s.Scopes))
+	for _, sc := range s.Scopes {
+		scopes[sc.Name] = sc.Description
+	}
+	flow := map[string]interface{}{"scopes": scopes}
+	if f.AuthorizationURL != "" {
+		flow["authorizationUrl"] = f.AuthorizationURL
+	}
+	if f.TokenURL != "" {
+		flow["tokenUrl"] = f.TokenURL
+	}
+	if f.RefreshURL != "" {
+		flow["refreshUrl"] = f.RefreshURL
+	}
+	return flow
+}
+
+// GoAuther returns the Go auth hooks of the security schemes: the Auther
+// interface, implemented by the services, with one authorization function per
+// scheme, and for each scheme the functions reading the credentials of HTTP
+// requests and of the incoming gRPC metadata and calling the Auther. The
+// hooks return ErrUnauthorized if the credentials are missing. The generated
+// code uses the context, encoding/base64, errors, net/http, strings and
+// google.golang.org/grpc/metadata packages.
+func GoAuther(schemes []*expr.SchemeExpr) string {
+	var b strings.Builder
+	b.WriteString("// ErrUnauthorized is returned by the auth hooks if the request has no\n")
+	b.WriteString("// credentials.\n")
+	b.WriteString("var ErrUnauthorized = errors.New(\"unauthorized\")\n\n")
+
+	b.WriteString("// Auther implements the authorization logic of the security schemes, the\n")
+	b.WriteString("// functions return the context of the authorized requests.\n")
+	b.WriteString("type Auther interface {\n")
+	for _, s := range schemes {
+		if s.Kind == expr.NoKind {
+			continue
+		}
+		writeComment(&b, "\t", s.Description,
+			fmt.Sprintf("%s authorizes the requests of the %s scheme.", goAuthName(s), s.SchemeName))
+		if s.Kind == expr.BasicAuthKind {
+			fmt.Fprintf(&b, "\t%s(ctx context.Context, user, pass string, scopes []string) (context.Context, error)\n", goAuthName(s))
+		} else {
+			fmt.Fprintf(&b, "\t%s(ctx context.Context, %s string, scopes []string) (context.Context, error)\n", goAuthName(s), goCredential(s))
+		}
+	}
+	b.WriteString("}\n")
+
+	for _, s := range schemes {
+		if s.Kind == expr.NoKind {
+			continue
+		}
+		name := Goify(s.SchemeName, true)
+		fmt.Fprintf(&b, "\n// Auth%sHTTP authorizes the HTTP request with the %s scheme.\n", name, s.SchemeName)
+		fmt.Fprintf(&b, "func Auth%sHTTP(a Auther, r *http.Request, scopes []string) (context.Context, error) {\n", name)
+		if s.Kind == expr.BasicAuthKind {
+			b.WriteString("\tuser, pass, ok := r.BasicAuth()\n\tif !ok {\n\t\treturn nil, ErrUnauthorized\n\t}\n")
+			fmt.Fprintf(&b, "\treturn a.%s(r.Context(), user, pass, scopes)\n}\n", goAuthName(s))
+		} else {
+			cred := goCredential(s)
+			switch s.In {
+			case "query":
+				fmt.Fprintf(&b, "\t%s := r.URL.Query().Get(%q)\n", cred, s.Name)
+			case "cookie":
+				fmt.Fprintf(&b, "\tvar %s string\n", cred)
+				fmt.Fprintf(&b, "\tif c, err := r.Cookie(%q); err == nil {\n\t\t%s = c.Value\n\t}\n", s.Name, cred)
+			default:
+				fmt.Fprintf(&b, "\t%s := r.Header.Get(%q)\n", cred, s.Name)
+			}
+			writeGoBearer(&b, s, cred)
+			fmt.Fprintf(&b, "\treturn a.%s(r.Context(), %s, scopes)\n}\n", goAuthName(s), cred)
+		}
+
+		fmt.Fprintf(&b, "\n// Auth%sGRPC authorizes the gRPC request of ctx with the %s scheme.\n", name, s.SchemeName)
+		fmt.Fprintf(&b, "func Auth%sGRPC(ctx context.Context, a Auther, scopes []string) (context.Context, error) {\n", name)
+		key := strings.ToLower(s.Name)
+		if s.Kind == expr.BasicAuthKind {
+			key = "authorization"
+		}
+		fmt.Fprintf(&b, "\tvar value string\n\tif md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(%q)) > 0 {\n\t\tvalue = md.Get(%q)[0]\n\t}\n", key, key)
+		if s.Kind == expr.BasicAuthKind {
+			b.WriteString("\tuser, pass, ok := parseBasicAuth(value)\n\tif !ok {\n\t\treturn nil, ErrUnauthorized\n\t}\n")
+			fmt.Fprintf(&b, "\treturn a.%s(ctx, user, pass, scopes)\n}\n", goAuthName(s))
+		} else {
+			fmt.Fprintf(&b, "\t%s := value\n", goCredential(s))
+			writeGoBearer(&b, s, goCredential(s))
+			fmt.Fprintf(&b, "\treturn a.%s(ctx, %s, scopes)\n}\n", goAuthName(s), goCredential(s))
+		}
+	}
+
+	for _, s := range schemes {
+		if s.Kind == expr.BasicAuthKind {
+			b.WriteString(goParseBasicAuth)
+			break
+		}
+	}
+	return b.String()
+}
+
+// goAuthName returns the name of the Auther function of the scheme.
+func goAuthName(s *expr.SchemeExpr) string {
+	return Goify(s.SchemeName, true) + "Auth"
+}
+
+// goCredential returns the name of the variable holding the credential of
+// the scheme.
+func goCredential(s *expr.SchemeExpr) string {
+	if s.Kind == expr.APIKeyKind {
+		return "key"
+	}
+	return "token"
+}
+
+// writeGoBearer writes the code removing the "Bearer" prefix of the tokens
+// read from the Authorization header and returning ErrUnauthorized if the
+// credential is missing.
+func writeGoBearer(b *strings.Builder, s *expr.SchemeExpr, cred string) {
+	if s.Kind != expr.APIKeyKind && s.In == "header" && strings.EqualFold(s.Name, "Authorization") {
+		fmt.Fprintf(b, "\tif len(%s) > 7 && strings.EqualFold(%s[:7], \"bearer \") {\n\t\t%s = %s[7:]\n\t}\n", cred, cred, cred, cred)
+	}
+	fmt.Fprintf(b, "\tif %s == \"\" {\n\t\treturn nil, ErrUnauthorized\n\t}\n", cred)
+}
+
+// goParseBasicAuth is the Go function parsing the basic auth credentials of
+// gRPC metadata.
+const goParseBasicAuth = `
+// parseBasicAuth parses the "Basic" credentials of the authorization value.
+func parseBasicAuth(auth string) (user, pass string, ok bool) {
+	const prefix = "basic "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	c, err := base64.StdEncoding.DecodeString(auth[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+	i := strings.IndexByte(string(c), ':')
+	if i < 0 {
+		return "", "", false
+	}
+	return string(c[:i]), string(c[i+1:]), true
+}
+`
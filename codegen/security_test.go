@@ -0,0 +1,76 @@
+package codegen
+
+import (
+	"go/format"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.zoe.im/goser/expr"
+)
+
+func TestOpenAPISecurity(t *testing.T) {
+	var (
+		basic = &expr.SchemeExpr{Kind: expr.BasicAuthKind, SchemeName: "basic", Description: "Basic auth"}
+		key   = &expr.SchemeExpr{Kind: expr.APIKeyKind, SchemeName: "key", In: "query", Name: "api_key"}
+		jwt   = &expr.SchemeExpr{Kind: expr.JWTKind, SchemeName: "jwt", In: "header", Name: "Authorization"}
+		oauth = &expr.SchemeExpr{Kind: expr.OAuth2Kind, SchemeName: "oauth", In: "header", Name: "Authorization",
+			Scopes: []*expr.ScopeExpr{{Name: "api:read", Description: "Read access"}},
+			Flows:  []*expr.FlowExpr{{Kind: expr.ClientCredentialsFlowKind, TokenURL: "/token"}},
+		}
+	)
+	schemes := OpenAPISecuritySchemes([]*expr.SchemeExpr{basic, key, jwt, oauth})
+	expected := map[string]interface{}{
+		"basic": map[string]interface{}{"type": "http", "scheme": "basic", "description": "Basic auth"},
+		"key":   map[string]interface{}{"type": "apiKey", "in": "query", "name": "api_key"},
+		"jwt":   map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+		"oauth": map[string]interface{}{"type": "oauth2", "flows": map[string]interface{}{
+			"clientCredentials": map[string]interface{}{
+				"tokenUrl": "/token",
+				"scopes":   map[string]interface{}{"api:read": "Read access"},
+			},
+		}},
+	}
+	if !reflect.DeepEqual(schemes, expected) {
+		t.Errorf("got %#v, expected %#v", schemes, expected)
+	}
+
+	reqs := OpenAPISecurity([]*expr.SecurityExpr{
+		{Schemes: []*expr.SchemeExpr{oauth, key}, Scopes: []string{"api:read"}},
+		{Schemes: []*expr.SchemeExpr{{Kind: expr.NoKind}}},
+	})
+	expectedReqs := []interface{}{
+		map[string]interface{}{"oauth": []string{"api:read"}, "key": []string{}},
+		map[string]interface{}{},
+	}
+	if !reflect.DeepEqual(reqs, expectedReqs) {
+		t.Errorf("got %#v, expected %#v", reqs, expectedReqs)
+	}
+}
+
+func TestGoAuther(t *testing.T) {
+	schemes := []*expr.SchemeExpr{
+		{Kind: expr.BasicAuthKind, SchemeName: "basic"},
+		{Kind: expr.APIKeyKind, SchemeName: "api_key", In: "cookie", Name: "session"},
+		{Kind: expr.JWTKind, SchemeName: "jwt", In: "header", Name: "Authorization", Description: "JWTAuth checks the JWT."},
+	}
+	code := GoAuther(schemes)
+	if _, err := format.Source([]byte("package p\n\n" + code)); err != nil {
+		t.Errorf("invalid Go code: %v\n%s", err, code)
+	}
+	for _, s := range []string{
+		"\t// BasicAuth authorizes the requests of the basic scheme.\n\tBasicAuth(ctx context.Context, user, pass string, scopes []string) (context.Context, error)\n",
+		"\tAPIKeyAuth(ctx context.Context, key string, scopes []string) (context.Context, error)\n",
+		"\t// JWTAuth checks the JWT.\n\tJWTAuth(ctx context.Context, token string, scopes []string) (context.Context, error)\n",
+		"func AuthBasicHTTP(a Auther, r *http.Request, scopes []string) (context.Context, error) {\n\tuser, pass, ok := r.BasicAuth()\n",
+		"\tif c, err := r.Cookie(\"session\"); err == nil {\n\t\tkey = c.Value\n\t}\n",
+		"\ttoken := r.Header.Get(\"Authorization\")\n\tif len(token) > 7 && strings.EqualFold(token[:7], \"bearer \") {\n",
+		"func AuthJWTGRPC(ctx context.Context, a Auther, scopes []string) (context.Context, error) {\n",
+		"md.Get(\"authorization\")",
+		"func parseBasicAuth(auth string) (user, pass string, ok bool) {",
+	} {
+		if !strings.Contains(code, s) {
+			t.Errorf("got\n%s\nexpected it to contain\n%s", code, s)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+)
+
+// GoHosts returns the Go definition of the hosts of the server: the hosts
+// table, the resolveHost function building the host URIs from the values of
+// the --var flags and addHostFlags which registers the --host and --var flags
+// on a command. The first host of the server is the default one. The
+// generated code uses the fmt, net/url, strings and go.zoe.im/x/cli packages.
+func GoHosts(svr *expr.ServerExpr) string {
+	var b strings.Builder
+	b.WriteString("// host is a server host, the URIs may contain variables.\n")
+	b.WriteString("type host struct {\n\tname  string\n\turis  []string\n\tvars  map[string]string\n\tenums map[string][]string\n}\n\n")
+
+	fmt.Fprintf(&b, "// hosts lists the hosts of the %s server, the first host is the default.\n", svr.Name)
+	b.WriteString("var hosts = []*host{\n")
+	for _, h := range svr.Hosts {
+		fmt.Fprintf(&b, "\t{\n\t\tname: %q,\n\t\turis: []string{", h.Name)
+		for i, u := range h.URIs {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "%q", string(u))
+		}
+		b.WriteString("},\n")
+		defaults := h.DefaultVariableValues()
+		if len(defaults) > 0 {
+			b.WriteString("\t\tvars: map[string]string{")
+			for i, n := range sortedKeys(defaults) {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				fmt.Fprintf(&b, "%q: %q", n, fmt.Sprintf("%v", defaults[n]))
+			}
+			b.WriteString("},\n")
+		}
+		var enums []string
+		for _, n := range sortedKeys(defaults) {
+			att := h.Variable(n)
+			if att == nil || att.Validation == nil || len(att.Validation.Values) == 0 {
+				continue
+			}
+			values := make([]string, len(att.Validation.Values))
+			for i, v := range att.Validation.Values {
+				values[i] = fmt.Sprintf("%q", fmt.Sprintf("%v", v))
+			}
+			enums = append(enums, fmt.Sprintf("%q: {%s}", n, strings.Join(values, ", ")))
+		}
+		if len(enums) > 0 {
+			fmt.Fprintf(&b, "\t\tenums: map[string][]string{%s},\n", strings.Join(enums, ", "))
+		}
+		b.WriteString("\t},\n")
+	}
+	b.WriteString("}\n")
+	b.WriteString(goResolveHost)
+	return b.String()
+}
+
+// GoServerMain returns the Go main package code of the server: the command
+// accepting the --host and --var flags which listens on the URIs of the
+// selected host and shuts the servers down gracefully on SIGINT or SIGTERM.
+// The HTTP URIs are served by the handler of the runtime returned by
+// newRuntime, with signature func() (*runtime.Runtime, error), and the gRPC
+// URIs by the server returned by newGRPCServer, with signature
+// func(*runtime.Runtime) *grpc.Server, both must be defined in the package,
+// as well as the code generated by GoHosts. The generated code uses the context, fmt,
+// net, net/http, net/url, os, os/signal, syscall, time, go.zoe.im/x/cli and
+// google.golang.org/grpc packages.
+func GoServerMain(svr *expr.ServerExpr) string {
+	var b strings.Builder
+	desc := svr.Description
+	if desc == "" {
+		desc = svr.Name + " server"
+	}
+	b.WriteString("func main() {\n")
+	b.WriteString("\tvar flags hostFlags\n")
+	fmt.Fprintf(&b, "\tcmd := cli.New(\n\t\tcli.Name(%q),\n\t\tcli.Short(%q),\n", svr.Name, desc)
+	b.WriteString("\t\tcli.Run(func(c *cli.Command, args ...string) {\n")
+	b.WriteString("\t\t\turis, err := resolveHost(flags.host, flags.vars)\n")
+	b.WriteString("\t\t\tif err != nil {\n\t\t\t\tfmt.Fprintln(os.Stderr, err)\n\t\t\t\tos.Exit(1)\n\t\t\t}\n")
+	b.WriteString("\t\t\tif err := serve(uris); err != nil {\n\t\t\t\tfmt.Fprintln(os.Stderr, err)\n\t\t\t\tos.Exit(1)\n\t\t\t}\n")
+	b.WriteString("\t\t}),\n\t)\n")
+	b.WriteString("\taddHostFlags(cmd, &flags)\n")
+	b.WriteString("\tif err := cmd.Run(); err != nil {\n\t\tfmt.Fprintln(os.Stderr, err)\n\t\tos.Exit(1)\n\t}\n}\n")
+	b.WriteString(goServe)
+	return b.String()
+}
+
+// GoClientMain returns the Go main package code of the client of the server:
+// the root command accepting the --host, --var and --transport flags and the
+// endpoint function returning the URI of the selected host for the selected
+// transport. The subcommands calling the service methods are registered with
+// the root command by the functions appended to the commands variable, e.g. in
+// init functions, and accept the client flags registered with addClientFlags.
+// The package must define the code generated by GoHosts.
+// The generated code uses the fmt, net/url, os and go.zoe.im/x/cli packages.
+func GoClientMain(svr *expr.ServerExpr) string {
+	var b strings.Builder
+	desc := svr.Description
+	if desc == "" {
+		desc = svr.Name + " server"
+	}
+	b.WriteString("// flags are the flags of the client selecting the host URI.\n")
+	b.WriteString("var flags struct {\n\thostFlags\n\ttransport string\n}\n\n")
+	b.WriteString("// commands register the subcommands of the client.\n")
+	b.WriteString("var commands []func(root *cli.Command)\n\n")
+	b.WriteString("func main() {\n")
+	fmt.Fprintf(&b, "\tcmd := cli.New(\n\t\tcli.Name(%q),\n\t\tcli.Short(%q),\n\t)\n", svr.Name+"-cli", "Client of the "+desc)
+	b.WriteString("\taddClientFlags(cmd)\n")
+	b.WriteString("\tfor _, register := range commands {\n\t\tregister(cmd)\n\t}\n")
+	b.WriteString("\tif err := cmd.Run(); err != nil {\n\t\tfmt.Fprintln(os.Stderr, err)\n\t\tos.Exit(1)\n\t}\n}\n")
+	b.WriteString("\n// addClientFlags registers the --host, --var and --transport flags on the\n// command, the subcommands calling the methods accept them too.\n")
+	b.WriteString("func addClientFlags(c *cli.Command) {\n\taddHostFlags(c, &flags.hostFlags)\n")
+	fmt.Fprintf(&b, "\tc.Flags().StringVarP(&flags.transport, \"transport\", \"t\", %q, \"transport used to call the server, http or grpc\")\n}\n", defaultTransport(svr))
+	b.WriteString(goEndpoint)
+	return b.String()
+}
+
+// defaultTransport returns the transport of the first URI of the default host
+// of the server.
+func defaultTransport(svr *expr.ServerExpr) string {
+	if len(svr.Hosts) > 0 && len(svr.Hosts[0].URIs) > 0 {
+		if t := svr.Hosts[0].URIs[0].Transport(); t != "" {
+			return t
+		}
+	}
+	return "http"
+}
+
+// sortedKeys returns the keys of the map sorted.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// goResolveHost is the Go code resolving the host URIs and defining the host
+// flags independent of the server.
+const goResolveHost = `
+// hostFlags are the values of the --host and --var flags.
+type hostFlags struct {
+	host string
+	vars []string
+}
+
+// addHostFlags registers the --host and --var flags on the command.
+func addHostFlags(c *cli.Command, f *hostFlags) {
+	names := make([]string, len(hosts))
+	for i, h := range hosts {
+		names[i] = h.name
+	}
+	c.Flags().StringVarP(&f.host, "host", "", hosts[0].name, "server host, one of "+strings.Join(names, ", "))
+	c.Flags().StringSliceVarP(&f.vars, "var", "", nil, "URI variable of the host with format name=value")
+}
+
+// resolveHost returns the URIs of the host with the given name, the
+// variables are set with vars of the form name=value or their default values.
+func resolveHost(name string, vars []string) ([]*url.URL, error) {
+	var h *host
+	for _, hh := range hosts {
+		if hh.name == name {
+			h = hh
+			break
+		}
+	}
+	if h == nil {
+		return nil, fmt.Errorf("unknown host %q", name)
+	}
+	values := make(map[string]string, len(h.vars))
+	for n, v := range h.vars {
+		values[n] = v
+	}
+	for _, v := range vars {
+		i := strings.Index(v, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid variable %q, must be name=value", v)
+		}
+		n, val := v[:i], v[i+1:]
+		if _, ok := h.vars[n]; !ok {
+			return nil, fmt.Errorf("unknown variable %q of host %s", n, h.name)
+		}
+		if enum := h.enums[n]; len(enum) > 0 {
+			found := false
+			for _, e := range enum {
+				found = found || e == val
+			}
+			if !found {
+				return nil, fmt.Errorf("variable %s must be one of %s, got %q", n, strings.Join(enum, ", "), val)
+			}
+		}
+		values[n] = val
+	}
+	uris := make([]*url.URL, len(h.uris))
+	for i, uri := range h.uris {
+		for n, v := range values {
+			uri = strings.Replace(uri, "{"+n+"}", v, -1)
+		}
+		u, err := url.Parse(uri)
+		if err != nil {
+			return nil, fmt.Errorf("host %s: %v", h.name, err)
+		}
+		uris[i] = u
+	}
+	return uris, nil
+}
+`
+
+// goServe is the Go code listening on the host URIs independent of the
+// server.
+const goServe = `
+// serve listens on the URIs until a server fails or the process is
+// interrupted, the servers are then shut down gracefully. The https and grpcs
+// URIs are served without TLS which is expected to be terminated by a proxy.
+func serve(uris []*url.URL) error {
+	r, err := newRuntime()
+	if err != nil {
+		return err
+	}
+	var (
+		handler     = r.Handler()
+		httpServers []*http.Server
+		grpcServer  *grpc.Server
+		errc        = make(chan error, len(uris))
+	)
+	for _, u := range uris {
+		addr := listenAddr(u)
+		switch u.Scheme {
+		case "http", "https":
+			srv := &http.Server{Addr: addr, Handler: handler}
+			httpServers = append(httpServers, srv)
+			fmt.Printf("HTTP server listening on %s\n", addr)
+			go func() { errc <- srv.ListenAndServe() }()
+		case "grpc", "grpcs":
+			l, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			if grpcServer == nil {
+				grpcServer = newGRPCServer(r)
+			}
+			fmt.Printf("gRPC server listening on %s\n", addr)
+			go func() { errc <- grpcServer.Serve(l) }()
+		}
+	}
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err = <-errc:
+	case sig := <-sigc:
+		fmt.Printf("received %s, shutting down\n", sig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, srv := range httpServers {
+		srv.Shutdown(ctx)
+	}
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+	return err
+}
+
+// listenAddr returns the address to listen on for the URI, the port
+// defaults to 80, 443, 8080 or 8443 for the http, https, grpc and grpcs
+// schemes.
+func listenAddr(u *url.URL) string {
+	if u.Port() != "" {
+		return ":" + u.Port()
+	}
+	switch u.Scheme {
+	case "https":
+		return ":443"
+	case "grpc":
+		return ":8080"
+	case "grpcs":
+		return ":8443"
+	}
+	return ":80"
+}
+`
+
+// goEndpoint is the Go code returning the endpoint of the client independent
+// of the server.
+const goEndpoint = `
+// endpoint returns the URI of the selected host for the selected transport.
+func endpoint() (*url.URL, error) {
+	uris, err := resolveHost(flags.host, flags.vars)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range uris {
+		switch {
+		case flags.transport == "http" && (u.Scheme == "http" || u.Scheme == "https"),
+			flags.transport == "grpc" && (u.Scheme == "grpc" || u.Scheme == "grpcs"):
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("host %s has no %s URI", flags.host, flags.transport)
+}
+`
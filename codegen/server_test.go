@@ -0,0 +1,56 @@
+package codegen
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"go.zoe.im/goser/expr"
+)
+
+func TestGoServer(t *testing.T) {
+	vars := expr.Object{
+		{Name: "version", Attribute: &expr.AttributeExpr{Type: expr.String, Validation: &expr.ValidationExpr{Values: []interface{}{"v1", "v2"}}}},
+	}
+	svr := &expr.ServerExpr{
+		Name: "calcsvr",
+		Hosts: []*expr.HostExpr{
+			{Name: "production", URIs: []expr.URIExpr{"https://{version}.goser.zoe.im/calc", "grpcs://{version}.goser.zoe.im"},
+				Variables: &expr.AttributeExpr{Type: &vars}},
+			{Name: "development", URIs: []expr.URIExpr{"grpc://localhost:8080", "http://localhost:80/calc"}},
+		},
+	}
+	hosts := GoHosts(svr)
+	cases := map[string]struct {
+		code     string
+		contains []string
+	}{
+		"hosts": {hosts, []string{
+			"\t\tname: \"production\",\n\t\turis: []string{\"https://{version}.goser.zoe.im/calc\", \"grpcs://{version}.goser.zoe.im\"},\n",
+			"\t\tvars: map[string]string{\"version\": \"v1\"},\n",
+			"\t\tenums: map[string][]string{\"version\": {\"v1\", \"v2\"}},\n",
+			"\t\tname: \"development\",\n",
+			"func resolveHost(name string, vars []string) ([]*url.URL, error) {",
+		}},
+		"server": {GoServerMain(svr) + hosts, []string{
+			"\t\tcli.Name(\"calcsvr\"),\n\t\tcli.Short(\"calcsvr server\"),\n",
+			"\taddHostFlags(cmd, &flags)\n",
+			"func serve(uris []*url.URL) error {",
+		}},
+		"client": {GoClientMain(svr) + hosts, []string{
+			"\t\tcli.Name(\"calcsvr-cli\"),\n",
+			"\"transport\", \"t\", \"http\",",
+			"func endpoint() (*url.URL, error) {",
+		}},
+	}
+	for k, tc := range cases {
+		if _, err := format.Source([]byte("package main\n\n" + tc.code)); err != nil {
+			t.Errorf("%s: invalid Go code: %v\n%s", k, err, tc.code)
+		}
+		for _, s := range tc.contains {
+			if !strings.Contains(tc.code, s) {
+				t.Errorf("%s: got\n%s\nexpected it to contain\n%s", k, tc.code, s)
+			}
+		}
+	}
+}
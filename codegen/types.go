@@ -0,0 +1,221 @@
+package codegen
+
+import (
+	"fmt"
+
+	"go.zoe.im/goser/expr"
+)
+
+var (
+	// GoDecimalType is the Go type of decimal fields, it may be set to a
+	// third party decimal type such as "decimal.Decimal" whose package is
+	// imported by the generated code.
+	GoDecimalType = "string"
+	// GoBigIntType is the Go type of big integer fields.
+	GoBigIntType = "*big.Int"
+	// ProtoDecimalType is the proto type of decimal fields, it may be set
+	// to a well-known decimal message such as "google.type.Decimal".
+	ProtoDecimalType = "string"
+)
+
+// GoTypeName returns the Go type name of the data type, user types are
+// referred to by their Goified names and inline objects are represented as
+// generic maps. Dates and durations are strings such as "2006-01-02" and
+// "1h30m" as time.Time and time.Duration don't marshal to these formats.
+func GoTypeName(dt expr.DataType) string {
+	switch t := dt.(type) {
+	case expr.Primitive:
+		switch t {
+		case expr.Boolean:
+			return "bool"
+		case expr.Int:
+			return "int"
+		case expr.Int32:
+			return "int32"
+		case expr.Int64:
+			return "int64"
+		case expr.UInt:
+			return "uint"
+		case expr.UInt32:
+			return "uint32"
+		case expr.UInt64:
+			return "uint64"
+		case expr.Float32:
+			return "float32"
+		case expr.Float64:
+			return "float64"
+		case expr.String:
+			return "string"
+		case expr.Bytes:
+			return "[]byte"
+		case expr.Timestamp:
+			return "time.Time"
+		case expr.Date, expr.Duration:
+			return "string"
+		case expr.Decimal:
+			return GoDecimalType
+		case expr.BigInt:
+			return GoBigIntType
+		}
+		return "interface{}"
+	case *expr.Array:
+		return "[]" + GoTypeName(t.ElemType.Type)
+	case *expr.Map:
+		return fmt.Sprintf("map[%s]%s", GoTypeName(t.KeyType.Type), GoTypeName(t.ElemType.Type))
+	case *expr.Object:
+		return "map[string]interface{}"
+	case *expr.Union:
+		if t.TypeName != "" {
+			return Goify(t.TypeName, true)
+		}
+		return "interface{}"
+	case expr.UserType:
+		return Goify(t.Name(), true)
+	}
+	return "interface{}"
+}
+
+// ProtoTypeName returns the proto3 type name of the data type, arrays must be
+// declared as repeated fields of the element type by the caller.
+func ProtoTypeName(dt expr.DataType) string {
+	switch t := dt.(type) {
+	case expr.Primitive:
+		switch t {
+		case expr.Boolean:
+			return "bool"
+		case expr.Int, expr.Int32:
+			return "sint32"
+		case expr.Int64:
+			return "sint64"
+		case expr.UInt, expr.UInt32:
+			return "uint32"
+		case expr.UInt64:
+			return "uint64"
+		case expr.Float32:
+			return "float"
+		case expr.Float64:
+			return "double"
+		case expr.String:
+			return "string"
+		case expr.Bytes:
+			return "bytes"
+		case expr.Timestamp, expr.Date:
+			return "google.protobuf.Timestamp"
+		case expr.Duration:
+			return "google.protobuf.Duration"
+		case expr.Decimal:
+			return ProtoDecimalType
+		case expr.BigInt:
+			return "string"
+		}
+		return "google.protobuf.Any"
+	case *expr.Array:
+		return ProtoTypeName(t.ElemType.Type)
+	case *expr.Map:
+		return fmt.Sprintf("map<%s, %s>", ProtoTypeName(t.KeyType.Type), ProtoTypeName(t.ElemType.Type))
+	case *expr.Object:
+		return "google.protobuf.Struct"
+	case *expr.Union:
+		if t.TypeName != "" {
+			return Goify(t.TypeName, true)
+		}
+		return "google.protobuf.Any"
+	case expr.UserType:
+		return Goify(t.Name(), true)
+	}
+	return "google.protobuf.Any"
+}
+
+// SQLTypeName returns the SQL column type of the data type, composite types
+// are stored as JSON.
+func SQLTypeName(dt expr.DataType) string {
+	switch t := dt.(type) {
+	case expr.Primitive:
+		switch t {
+		case expr.Boolean:
+			return "BOOLEAN"
+		case expr.Int, expr.Int32, expr.UInt, expr.UInt32:
+			return "INTEGER"
+		case expr.Int64, expr.UInt64:
+			return "BIGINT"
+		case expr.Float32:
+			return "REAL"
+		case expr.Float64:
+			return "DOUBLE PRECISION"
+		case expr.String:
+			return "TEXT"
+		case expr.Bytes:
+			return "BLOB"
+		case expr.Timestamp:
+			return "TIMESTAMP"
+		case expr.Date:
+			return "DATE"
+		case expr.Duration:
+			return "INTERVAL"
+		case expr.Decimal, expr.BigInt:
+			return "NUMERIC"
+		}
+	case expr.UserType:
+		if expr.IsPrimitive(t) {
+			return SQLTypeName(t.Attribute().Type)
+		}
+	}
+	return "JSON"
+}
+
+// SQLColumnType returns the SQL column type of the attribute, decimal and big
+// integer columns are NUMERIC(precision, scale) if the attribute defines a
+// precision.
+func SQLColumnType(att *expr.AttributeExpr) string {
+	typ := SQLTypeName(att.Type)
+	if typ != "NUMERIC" || att.Validation == nil || att.Validation.Precision == nil {
+		return typ
+	}
+	scale := 0
+	if att.Validation.Scale != nil {
+		scale = *att.Validation.Scale
+	}
+	return fmt.Sprintf("NUMERIC(%d,%d)", *att.Validation.Precision, scale)
+}
+
+// OpenAPIType returns the OpenAPI schema type and format of the data type,
+// the format is empty if the type has no specific format.
+func OpenAPIType(dt expr.DataType) (string, string) {
+	switch t := dt.(type) {
+	case expr.Primitive:
+		switch t {
+		case expr.Boolean:
+			return "boolean", ""
+		case expr.Int, expr.Int32, expr.UInt, expr.UInt32:
+			return "integer", "int32"
+		case expr.Int64, expr.UInt64:
+			return "integer", "int64"
+		case expr.Float32:
+			return "number", "float"
+		case expr.Float64:
+			return "number", "double"
+		case expr.String:
+			return "string", ""
+		case expr.Bytes:
+			return "string", "byte"
+		case expr.Timestamp:
+			return "string", "date-time"
+		case expr.Date:
+			return "string", "date"
+		case expr.Duration:
+			return "string", "duration"
+		case expr.Decimal:
+			return "string", "decimal"
+		case expr.BigInt:
+			return "string", "bigint"
+		}
+		return "", ""
+	case *expr.Array:
+		return "array", ""
+	case *expr.Map, *expr.Object:
+		return "object", ""
+	case expr.UserType:
+		return OpenAPIType(t.Attribute().Type)
+	}
+	return "", ""
+}
@@ -0,0 +1,96 @@
+package codegen
+
+import (
+	"testing"
+
+	"go.zoe.im/goser/expr"
+)
+
+func TestTypeNames(t *testing.T) {
+	var (
+		user = &expr.UserTypeExpr{
+			TypeName:      "user_account",
+			AttributeExpr: &expr.AttributeExpr{Type: &expr.Object{}},
+		}
+		times = &expr.Array{ElemType: &expr.AttributeExpr{Type: expr.Timestamp}}
+		users = &expr.Map{
+			KeyType:  &expr.AttributeExpr{Type: expr.String},
+			ElemType: &expr.AttributeExpr{Type: user},
+		}
+	)
+	cases := map[string]struct {
+		dt      expr.DataType
+		golang  string
+		proto   string
+		sql     string
+		openapi string
+		format  string
+	}{
+		"int32":     {expr.Int32, "int32", "sint32", "INTEGER", "integer", "int32"},
+		"bytes":     {expr.Bytes, "[]byte", "bytes", "BLOB", "string", "byte"},
+		"timestamp": {expr.Timestamp, "time.Time", "google.protobuf.Timestamp", "TIMESTAMP", "string", "date-time"},
+		"date":      {expr.Date, "string", "google.protobuf.Timestamp", "DATE", "string", "date"},
+		"duration":  {expr.Duration, "string", "google.protobuf.Duration", "INTERVAL", "string", "duration"},
+		"decimal":   {expr.Decimal, "string", "string", "NUMERIC", "string", "decimal"},
+		"bigint":    {expr.BigInt, "*big.Int", "string", "NUMERIC", "string", "bigint"},
+		"array":     {times, "[]time.Time", "google.protobuf.Timestamp", "JSON", "array", ""},
+		"map":       {users, "map[string]UserAccount", "map<string, UserAccount>", "JSON", "object", ""},
+		"user type": {user, "UserAccount", "UserAccount", "JSON", "object", ""},
+	}
+	for k, tc := range cases {
+		if actual := GoTypeName(tc.dt); actual != tc.golang {
+			t.Errorf("%s: got Go type %q, expected %q", k, actual, tc.golang)
+		}
+		if actual := ProtoTypeName(tc.dt); actual != tc.proto {
+			t.Errorf("%s: got proto type %q, expected %q", k, actual, tc.proto)
+		}
+		if actual := SQLTypeName(tc.dt); actual != tc.sql {
+			t.Errorf("%s: got SQL type %q, expected %q", k, actual, tc.sql)
+		}
+		if typ, format := OpenAPIType(tc.dt); typ != tc.openapi || format != tc.format {
+			t.Errorf("%s: got OpenAPI type %q %q, expected %q %q", k, typ, format, tc.openapi, tc.format)
+		}
+	}
+}
+
+func TestGoify(t *testing.T) {
+	cases := map[string]struct {
+		firstUpper bool
+		expected   string
+	}{
+		"user_id":      {true, "UserID"},
+		"user-account": {false, "userAccount"},
+		"HTTPServer":   {true, "HTTPServer"},
+		"api url":      {true, "APIURL"},
+		"1st":          {true, "_1st"},
+	}
+	for k, tc := range cases {
+		if actual := Goify(k, tc.firstUpper); actual != tc.expected {
+			t.Errorf("%s: got %q, expected %q", k, actual, tc.expected)
+		}
+	}
+	if actual := SnakeCase("UserID"); actual != "user_id" {
+		t.Errorf("got %q, expected %q", actual, "user_id")
+	}
+}
+
+func TestSQLColumnType(t *testing.T) {
+	var (
+		precision = 12
+		scale     = 2
+	)
+	cases := map[string]struct {
+		att      *expr.AttributeExpr
+		expected string
+	}{
+		"decimal":       {&expr.AttributeExpr{Type: expr.Decimal, Validation: &expr.ValidationExpr{Precision: &precision, Scale: &scale}}, "NUMERIC(12,2)"},
+		"bigint":        {&expr.AttributeExpr{Type: expr.BigInt, Validation: &expr.ValidationExpr{Precision: &precision}}, "NUMERIC(12,0)"},
+		"no precision":  {&expr.AttributeExpr{Type: expr.Decimal}, "NUMERIC"},
+		"not a decimal": {&expr.AttributeExpr{Type: expr.String}, "TEXT"},
+	}
+	for k, tc := range cases {
+		if actual := SQLColumnType(tc.att); actual != tc.expected {
+			t.Errorf("%s: got %q, expected %q", k, actual, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,675 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+// TSFormats maps the validation formats to the regular expressions used by
+// the TypeScript validators, the values of the other formats are not checked.
+var TSFormats = map[expr.ValidationFormat]string{
+	expr.FormatDate:     `^\d{4}-\d{2}-\d{2}$`,
+	expr.FormatDateTime: `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$`,
+	expr.FormatUUID:     `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`,
+	expr.FormatEmail:    `^[^@\s]+@[^@\s]+$`,
+	expr.FormatURI:      `^[a-zA-Z][a-zA-Z0-9+.-]*:\S*$`,
+}
+
+// TSTypeName returns the TypeScript type of the data type, user types and
+// named unions are referred to by their Goified names, maps are Records and
+// inline objects are object literal types.
+func TSTypeName(dt expr.DataType) string {
+	return tsType(&expr.AttributeExpr{Type: dt})
+}
+
+// tsType returns the TypeScript type of the attribute, the required fields
+// of inline objects are given by the attribute validation.
+func tsType(att *expr.AttributeExpr) string {
+	switch t := att.Type.(type) {
+	case expr.Primitive:
+		switch t {
+		case expr.Boolean:
+			return "boolean"
+		case expr.Int, expr.Int32, expr.Int64, expr.UInt, expr.UInt32, expr.UInt64,
+			expr.Float32, expr.Float64:
+			return "number"
+		case expr.Any:
+			return "unknown"
+		}
+		return "string"
+	case *expr.Array:
+		elem := tsType(t.ElemType)
+		if strings.Contains(elem, " | ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case *expr.Map:
+		if e := expr.AsEnum(t.KeyType.Type); e != nil {
+			return fmt.Sprintf("Partial<Record<%s, %s>>", Goify(e.Name(), true), tsType(t.ElemType))
+		}
+		return fmt.Sprintf("Record<string, %s>", tsType(t.ElemType))
+	case *expr.Object:
+		if len(*t) == 0 {
+			return "Record<string, unknown>"
+		}
+		fields := make([]string, len(*t))
+		for i, nat := range *t {
+			fields[i] = tsField(att, nat)
+		}
+		return "{ " + strings.Join(fields, "; ") + " }"
+	case *expr.Union:
+		if t.TypeName != "" {
+			return Goify(t.TypeName, true)
+		}
+		return tsUnion(t)
+	case expr.UserType:
+		return Goify(t.Name(), true)
+	}
+	return "unknown"
+}
+
+// tsField returns the TypeScript declaration of the field of the object
+// attribute, the fields which are not required are optional.
+func tsField(parent *expr.AttributeExpr, nat *expr.NamedAttributeExpr) string {
+	name := nat.Name
+	if !tsIdentifier(name) {
+		name = tsString(name)
+	}
+	if !parent.IsRequired(nat.Name) {
+		name += "?"
+	}
+	return name + ": " + tsType(nat.Attribute)
+}
+
+// tsUnion returns the TypeScript union of the alternatives, the alternatives
+// are intersected with the discriminator field if any.
+func tsUnion(u *expr.Union) string {
+	alts := make([]string, len(u.Values))
+	for i, nat := range u.Values {
+		alts[i] = tsType(nat.Attribute)
+		if u.Discriminator != "" {
+			alts[i] = fmt.Sprintf("(%s & { %s: %s })", alts[i], tsString(u.Discriminator), tsString(nat.Name))
+		}
+	}
+	return strings.Join(alts, " | ")
+}
+
+// TSTypes returns the TypeScript module defining the user types and their
+// validators: enums are unions of their values listed by the <Name>Values
+// constants, objects are interfaces whose fields which are not required are
+// optional and the other types are type aliases. The validate<Name> functions
+// return the errors of the values which don't match the type and its
+// validations, an empty array if valid.
+func TSTypes(types []expr.UserType) string {
+	var b strings.Builder
+	for _, ut := range types {
+		name := Goify(ut.Name(), true)
+		att := ut.Attribute()
+		if e := expr.AsEnum(ut); e != nil {
+			values := make([]string, len(e.Values))
+			for i, v := range e.Values {
+				values[i] = tsLiteral(v.Value)
+			}
+			writeTSDoc(&b, "", att.Description, att.Meta)
+			fmt.Fprintf(&b, "export type %s = %s;\n\n", name, strings.Join(values, " | "))
+			fmt.Fprintf(&b, "/** %sValues lists the values of %s. */\n", name, name)
+			fmt.Fprintf(&b, "export const %sValues: %s[] = [%s];\n\n", name, name, strings.Join(values, ", "))
+		} else if obj, ok := att.Type.(*expr.Object); ok {
+			writeTSDoc(&b, "", att.Description, att.Meta)
+			fmt.Fprintf(&b, "export interface %s {\n", name)
+			for _, nat := range *obj {
+				writeTSDoc(&b, "  ", nat.Attribute.Description, nat.Attribute.Meta)
+				fmt.Fprintf(&b, "  %s;\n", tsField(att, nat))
+			}
+			b.WriteString("}\n\n")
+		} else {
+			writeTSDoc(&b, "", att.Description, att.Meta)
+			fmt.Fprintf(&b, "export type %s = %s;\n\n", name, tsType(att))
+		}
+		writeTSValidator(&b, name, att)
+		b.WriteString("\n")
+	}
+	b.WriteString(tsValidatorHelpers)
+	return b.String()
+}
+
+// TSUnion returns the TypeScript definition of the named union and its
+// validator, the module must define the code generated by TSTypes.
+func TSUnion(u *expr.Union) string {
+	var (
+		b    strings.Builder
+		name = Goify(u.Name(), true)
+	)
+	fmt.Fprintf(&b, "/** %s is one of %s. */\n", name, strings.Join(tsAlternatives(u), ", "))
+	fmt.Fprintf(&b, "export type %s = %s;\n\n", name, tsUnion(u))
+	writeTSValidator(&b, name, &expr.AttributeExpr{Type: &expr.Union{Values: u.Values, Discriminator: u.Discriminator}})
+	return b.String()
+}
+
+// tsAlternatives returns the names of the alternatives of the union.
+func tsAlternatives(u *expr.Union) []string {
+	names := make([]string, len(u.Values))
+	for i, nat := range u.Values {
+		names[i] = nat.Name
+	}
+	return names
+}
+
+// writeTSValidator writes the validate<Name> function of the type.
+func writeTSValidator(b *strings.Builder, name string, att *expr.AttributeExpr) {
+	fmt.Fprintf(b, "/** validate%s returns the errors of the %s value, empty if valid. */\n", name, name)
+	fmt.Fprintf(b, "export function validate%s(v: any, path: string = %s): string[] {\n", name, tsString(name))
+	b.WriteString("  const errs: string[] = [];\n")
+	writeTSCheck(b, "  ", att, "v", "${path}", 1)
+	b.WriteString("  return errs;\n}\n")
+}
+
+// writeTSCheck writes the statements validating the value of the attribute,
+// the errors are pushed to errs. v is the expression of the value, path the
+// content of the template literal of its path and depth the nesting level
+// used to name the loop variables.
+func writeTSCheck(b *strings.Builder, indent string, att *expr.AttributeExpr, v, path string, depth int) {
+	switch t := att.Type.(type) {
+	case expr.Primitive:
+		var typ, cond string
+		switch t {
+		case expr.Any:
+			return
+		case expr.Boolean:
+			typ, cond = "a boolean", fmt.Sprintf("typeof %s !== \"boolean\"", v)
+		case expr.Int, expr.Int32, expr.Int64, expr.UInt, expr.UInt32, expr.UInt64:
+			typ, cond = "an integer", fmt.Sprintf("typeof %s !== \"number\" || !Number.isInteger(%s)", v, v)
+		case expr.Float32, expr.Float64:
+			typ, cond = "a number", fmt.Sprintf("typeof %s !== \"number\"", v)
+		default:
+			typ, cond = "a string", fmt.Sprintf("typeof %s !== \"string\"", v)
+		}
+		fmt.Fprintf(b, "%sif (%s) {\n%s  errs.push(`%s must be %s`);\n%s}", indent, cond, indent, path, typ, indent)
+		var checks strings.Builder
+		writeTSValidation(&checks, indent+"  ", att, v, path)
+		if checks.Len() == 0 {
+			b.WriteString("\n")
+			return
+		}
+		fmt.Fprintf(b, " else {\n%s%s}\n", checks.String(), indent)
+	case *expr.Array:
+		fmt.Fprintf(b, "%sif (!Array.isArray(%s)) {\n%s  errs.push(`%s must be an array`);\n%s} else {\n", indent, v, indent, path, indent)
+		writeTSLength(b, indent+"  ", att.Validation, v+".length", path)
+		e, i := fmt.Sprintf("e%d", depth), fmt.Sprintf("i%d", depth)
+		fmt.Fprintf(b, "%s  %s.forEach((%s: any, %s: number) => {\n", indent, v, e, i)
+		writeTSCheck(b, indent+"    ", t.ElemType, e, fmt.Sprintf("%s[${%s}]", path, i), depth+1)
+		fmt.Fprintf(b, "%s  });\n%s}\n", indent, indent)
+	case *expr.Map:
+		fmt.Fprintf(b, "%sif (!isObject(%s)) {\n%s  errs.push(`%s must be an object`);\n%s} else {\n", indent, v, indent, path, indent)
+		writeTSLength(b, indent+"  ", att.Validation, fmt.Sprintf("Object.keys(%s).length", v), path)
+		k := fmt.Sprintf("k%d", depth)
+		fmt.Fprintf(b, "%s  Object.keys(%s).forEach((%s: string) => {\n", indent, v, k)
+		writeTSCheck(b, indent+"    ", t.ElemType, fmt.Sprintf("%s[%s]", v, k), fmt.Sprintf("%s.${%s}", path, k), depth+1)
+		fmt.Fprintf(b, "%s  });\n%s}\n", indent, indent)
+	case *expr.Object:
+		fmt.Fprintf(b, "%sif (!isObject(%s)) {\n%s  errs.push(`%s must be an object`);\n%s} else {\n", indent, v, indent, path, indent)
+		for _, nat := range *t {
+			var (
+				fv     = fmt.Sprintf("%s[%s]", v, tsString(nat.Name))
+				fpath  = path + "." + tsTemplate(nat.Name)
+				checks strings.Builder
+			)
+			writeTSCheck(&checks, indent+"    ", nat.Attribute, fv, fpath, depth)
+			if att.IsRequired(nat.Name) {
+				fmt.Fprintf(b, "%s  if (%s === undefined || %s === null) {\n%s    errs.push(`%s is required`);\n%s  }", indent, fv, fv, indent, fpath, indent)
+				if checks.Len() == 0 {
+					b.WriteString("\n")
+					continue
+				}
+				fmt.Fprintf(b, " else {\n%s%s  }\n", checks.String(), indent)
+			} else if checks.Len() > 0 {
+				fmt.Fprintf(b, "%s  if (%s !== undefined && %s !== null) {\n%s%s  }\n", indent, fv, fv, checks.String(), indent)
+			}
+		}
+		fmt.Fprintf(b, "%s}\n", indent)
+	case *expr.Union:
+		if t.TypeName != "" {
+			fmt.Fprintf(b, "%serrs.push(...validate%s(%s, `%s`));\n", indent, Goify(t.TypeName, true), v, path)
+			return
+		}
+		writeTSUnionCheck(b, indent, t, v, path, depth)
+	case expr.UserType:
+		fmt.Fprintf(b, "%serrs.push(...validate%s(%s, `%s`));\n", indent, Goify(t.Name(), true), v, path)
+	}
+}
+
+// writeTSUnionCheck writes the statements validating the value of the inline
+// union. The alternative is given by the discriminator field if any,
+// otherwise the value must be valid for one of the alternatives.
+func writeTSUnionCheck(b *strings.Builder, indent string, u *expr.Union, v, path string, depth int) {
+	alts := tsTemplate(strings.Join(tsAlternatives(u), ", "))
+	if u.Discriminator != "" {
+		fmt.Fprintf(b, "%sswitch (isObject(%s) ? %s[%s] : undefined) {\n", indent, v, v, tsString(u.Discriminator))
+		for _, nat := range u.Values {
+			fmt.Fprintf(b, "%s  case %s: {\n", indent, tsString(nat.Name))
+			writeTSCheck(b, indent+"    ", nat.Attribute, v, path, depth)
+			fmt.Fprintf(b, "%s    break;\n%s  }\n", indent, indent)
+		}
+		fmt.Fprintf(b, "%s  default:\n%s    errs.push(`%s.%s must be one of %s`);\n%s}\n",
+			indent, indent, path, tsTemplate(u.Discriminator), alts, indent)
+		return
+	}
+	fmt.Fprintf(b, "%sif (![\n", indent)
+	for _, nat := range u.Values {
+		fmt.Fprintf(b, "%s  (v: any): string[] => {\n%s    const errs: string[] = [];\n", indent, indent)
+		writeTSCheck(b, indent+"    ", nat.Attribute, "v", path, depth)
+		fmt.Fprintf(b, "%s    return errs;\n%s  },\n", indent, indent)
+	}
+	fmt.Fprintf(b, "%s].some((alt) => alt(%s).length === 0)) {\n", indent, v)
+	fmt.Fprintf(b, "%s  errs.push(`%s must be one of %s`);\n%s}\n", indent, path, alts, indent)
+}
+
+// writeTSValidation writes the statements checking the validations of the
+// primitive attribute: the enum values, the bounds of numbers and the length,
+// pattern and format of strings.
+func writeTSValidation(b *strings.Builder, indent string, att *expr.AttributeExpr, v, path string) {
+	val := att.Validation
+	if val == nil {
+		return
+	}
+	if len(val.Values) > 0 {
+		values := make([]string, len(val.Values))
+		for i, e := range val.Values {
+			values[i] = tsLiteral(e)
+		}
+		fmt.Fprintf(b, "%sif ([%s].indexOf(%s) < 0) {\n%s  errs.push(`%s must be one of %s`);\n%s}\n",
+			indent, strings.Join(values, ", "), v, indent, path, tsTemplate(tsValues(values)), indent)
+	}
+	if tsType(att) == "number" {
+		if val.Minimum != nil {
+			fmt.Fprintf(b, "%sif (%s < %v) {\n%s  errs.push(`%s must be at least %v`);\n%s}\n",
+				indent, v, *val.Minimum, indent, path, *val.Minimum, indent)
+		}
+		if val.Maximum != nil {
+			fmt.Fprintf(b, "%sif (%s > %v) {\n%s  errs.push(`%s must be at most %v`);\n%s}\n",
+				indent, v, *val.Maximum, indent, path, *val.Maximum, indent)
+		}
+		return
+	}
+	if tsType(att) != "string" {
+		return
+	}
+	writeTSLength(b, indent, val, v+".length", path)
+	if val.Pattern != "" {
+		fmt.Fprintf(b, "%sif (!new RegExp(%s).test(%s)) {\n%s  errs.push(`%s must match the pattern %s`);\n%s}\n",
+			indent, tsString(val.Pattern), v, indent, path, tsTemplate(val.Pattern), indent)
+	}
+	if re, ok := TSFormats[val.Format]; ok {
+		fmt.Fprintf(b, "%sif (!new RegExp(%s).test(%s)) {\n%s  errs.push(`%s must be a valid %s`);\n%s}\n",
+			indent, tsString(re), v, indent, path, val.Format, indent)
+	}
+}
+
+// writeTSLength writes the statements checking the length validations, n is
+// the expression of the length of the value.
+func writeTSLength(b *strings.Builder, indent string, val *expr.ValidationExpr, n, path string) {
+	if val == nil {
+		return
+	}
+	if val.MinLength != nil {
+		fmt.Fprintf(b, "%sif (%s < %d) {\n%s  errs.push(`%s length must be at least %d`);\n%s}\n",
+			indent, n, *val.MinLength, indent, path, *val.MinLength, indent)
+	}
+	if val.MaxLength != nil {
+		fmt.Fprintf(b, "%sif (%s > %d) {\n%s  errs.push(`%s length must be at most %d`);\n%s}\n",
+			indent, n, *val.MaxLength, indent, path, *val.MaxLength, indent)
+	}
+}
+
+// writeTSDoc writes the description and the deprecation of the element with
+// the given meta as a JSDoc comment.
+func writeTSDoc(b *strings.Builder, indent, desc string, meta expr.MetaExpr) {
+	var lines []string
+	if desc != "" {
+		lines = strings.Split(strings.TrimSpace(desc), "\n")
+	}
+	if d := expr.Deprecation(meta); d != nil {
+		lines = append(lines, strings.TrimSpace("@deprecated "+d.Reason))
+	}
+	switch len(lines) {
+	case 0:
+		return
+	case 1:
+		fmt.Fprintf(b, "%s/** %s */\n", indent, lines[0])
+		return
+	}
+	fmt.Fprintf(b, "%s/**\n", indent)
+	for _, l := range lines {
+		b.WriteString(strings.TrimRight(indent+" * "+l, " ") + "\n")
+	}
+	fmt.Fprintf(b, "%s */\n", indent)
+}
+
+// TSClient returns the TypeScript module shared by the service clients: the
+// client options, the errors and the request function sending the HTTP
+// requests with fetch.
+func TSClient() string {
+	return tsClient
+}
+
+// TSServiceClient returns the TypeScript module of the client of the service:
+// a class with a method per service method. The payloads of user types are
+// validated before the requests are sent and a ValidationError is thrown if
+// invalid. The errors declared by the methods are thrown as ServiceErrors,
+// typed with the <Service><Method>Error aliases. The paths of the requests
+// are prefixed with the version if not empty. The module imports the
+// modules generated by TSTypes and TSClient as ./types and ./client.
+func TSServiceClient(svc *runtime.Service, version string) string {
+	var (
+		b       strings.Builder
+		code    strings.Builder
+		name    = Goify(svc.Name, true)
+		imports = map[string]bool{"CallOptions": true, "ClientOptions": true, "request": true}
+		refs    = map[string]bool{}
+	)
+	for _, m := range svc.Methods {
+		if len(m.Errors) == 0 {
+			continue
+		}
+		imports["ServiceError"] = true
+		names := make([]string, len(m.Errors))
+		for i, e := range m.Errors {
+			names[i] = tsString(e.Name)
+		}
+		fmt.Fprintf(&code, "/** %s%sError is an error returned by %s. */\n", name, Goify(m.Name, true), m.Key())
+		fmt.Fprintf(&code, "export type %s%sError = ServiceError<%s>;\n\n", name, Goify(m.Name, true), strings.Join(names, " | "))
+	}
+
+	doc := fmt.Sprintf("%sClient calls the methods of the %s service.", name, svc.Name)
+	if svc.Description != "" {
+		doc += "\n\n" + svc.Description
+	}
+	writeTSDoc(&code, "", doc, svc.Meta)
+	fmt.Fprintf(&code, "export class %sClient {\n", name)
+	code.WriteString("  constructor(private readonly options: ClientOptions) {}\n")
+	for _, m := range svc.Methods {
+		code.WriteString("\n")
+		writeTSMethod(&code, name, m, version, imports, refs)
+	}
+	code.WriteString("}\n")
+
+	fmt.Fprintf(&b, "import { %s } from \"./client\";\n", strings.Join(tsSorted(imports), ", "))
+	if len(refs) > 0 {
+		fmt.Fprintf(&b, "import { %s } from \"./types\";\n", strings.Join(tsSorted(refs), ", "))
+	}
+	b.WriteString("\n")
+	b.WriteString(code.String())
+	return b.String()
+}
+
+// writeTSMethod writes the client method calling the service method at the
+// version, the names used from the client and types modules are added to
+// imports and refs.
+func writeTSMethod(b *strings.Builder, svc string, m *runtime.Method, version string, imports, refs map[string]bool) {
+	doc := m.Description
+	if doc == "" {
+		doc = fmt.Sprintf("%s calls %s.", Goify(m.Name, false), m.Key())
+	}
+	if len(m.Errors) > 0 {
+		doc += fmt.Sprintf("\n@throws %s%sError", svc, Goify(m.Name, true))
+	}
+	writeTSDoc(b, "  ", doc, m.Meta)
+
+	var params []string
+	if m.Payload != nil {
+		params = append(params, "payload: "+tsType(m.Payload))
+		tsRefs(m.Payload.Type, refs)
+	}
+	params = append(params, "opts?: CallOptions")
+	res := "void"
+	if m.Result != nil {
+		res = tsType(m.Result)
+		tsRefs(m.Result.Type, refs)
+	}
+	fmt.Fprintf(b, "  async %s(%s): Promise<%s> {\n", Goify(m.Name, false), strings.Join(params, ", "), res)
+
+	query, body := "undefined", "undefined"
+	path := tsTemplate(docsPath(m.Route.Path, version))
+	if m.Payload != nil {
+		if ut, ok := m.Payload.Type.(expr.UserType); ok && expr.AsEnum(ut) == nil {
+			validate := "validate" + Goify(ut.Name(), true)
+			refs[validate] = true
+			imports["ValidationError"] = true
+			fmt.Fprintf(b, "    const errs = %s(payload);\n", validate)
+			b.WriteString("    if (errs.length > 0) {\n      throw new ValidationError(errs);\n    }\n")
+		}
+		body = "payload"
+		if obj := expr.AsObject(m.Payload.Type); obj != nil {
+			for _, nat := range *obj {
+				param := tsTemplate("{" + nat.Name + "}")
+				if strings.Contains(path, param) {
+					imports["pathParam"] = true
+					path = strings.Replace(path, param, fmt.Sprintf("${pathParam(payload[%s])}", tsString(nat.Name)), -1)
+				}
+			}
+			if m.Route.Method == "GET" || m.Route.Method == "HEAD" {
+				query, body = "payload", "undefined"
+			}
+		}
+	}
+	fmt.Fprintf(b, "    return request(this.options, %s, `%s`, %s, %s, opts);\n  }\n", tsString(m.Route.Method), path, query, body)
+}
+
+// tsRefs adds the names of the user types and named unions referred to by
+// the data type to refs.
+func tsRefs(dt expr.DataType, refs map[string]bool) {
+	switch t := dt.(type) {
+	case *expr.Array:
+		tsRefs(t.ElemType.Type, refs)
+	case *expr.Map:
+		tsRefs(t.KeyType.Type, refs)
+		tsRefs(t.ElemType.Type, refs)
+	case *expr.Object:
+		for _, nat := range *t {
+			tsRefs(nat.Attribute.Type, refs)
+		}
+	case *expr.Union:
+		if t.TypeName != "" {
+			refs[Goify(t.TypeName, true)] = true
+			return
+		}
+		for _, nat := range t.Values {
+			tsRefs(nat.Attribute.Type, refs)
+		}
+	case expr.UserType:
+		refs[Goify(t.Name(), true)] = true
+	}
+}
+
+// tsSorted returns the keys of the set sorted.
+func tsSorted(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// tsIdentifier returns true if the name can be used as a property name
+// without quotes.
+func tsIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// tsString returns the TypeScript string literal of s.
+func tsString(s string) string {
+	js, _ := json.Marshal(s)
+	return string(js)
+}
+
+// tsLiteral returns the TypeScript literal of the value.
+func tsLiteral(v interface{}) string {
+	js, err := json.Marshal(v)
+	if err != nil {
+		return tsString(fmt.Sprintf("%v", v))
+	}
+	return string(js)
+}
+
+// tsValues returns the literals of the values joined for error messages.
+func tsValues(literals []string) string {
+	return strings.Join(literals, ", ")
+}
+
+// tsTemplate escapes s to be used in a template literal.
+func tsTemplate(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${").Replace(s)
+}
+
+// tsValidatorHelpers is the TypeScript code used by the validators.
+const tsValidatorHelpers = `/** isObject returns true if the value is a JSON object. */
+export function isObject(v: any): boolean {
+  return typeof v === "object" && v !== null && !Array.isArray(v);
+}
+`
+
+// tsClient is the TypeScript module shared by the service clients.
+const tsClient = `/** ClientOptions configures the service clients. */
+export interface ClientOptions {
+  /** baseURL is the URL of the HTTP server including the base path. */
+  baseURL: string;
+  /** headers are sent with every request, e.g. Authorization. */
+  headers?: Record<string, string>;
+  /** fetch sends the requests, defaults to the global fetch. */
+  fetch?: typeof fetch;
+}
+
+/** CallOptions configures a method call. */
+export interface CallOptions {
+  /** headers are sent with the request in addition to the client headers. */
+  headers?: Record<string, string>;
+  /** signal aborts the request. */
+  signal?: AbortSignal;
+}
+
+/** ErrorResult is the body of the service error responses. */
+export interface ErrorResult<N extends string = string> {
+  name: N;
+  id: string;
+  message: string;
+  temporary: boolean;
+  timeout: boolean;
+  fault: boolean;
+}
+
+/** ServiceError is an error declared by the service methods. */
+export class ServiceError<N extends string = string> extends Error {
+  readonly name: N;
+  readonly id: string;
+  readonly temporary: boolean;
+  readonly timeout: boolean;
+  readonly fault: boolean;
+
+  constructor(readonly status: number, res: ErrorResult<N>) {
+    super(res.message);
+    Object.setPrototypeOf(this, ServiceError.prototype);
+    this.name = res.name;
+    this.id = res.id;
+    this.temporary = res.temporary;
+    this.timeout = res.timeout;
+    this.fault = res.fault;
+  }
+}
+
+/** HTTPError is an error response which is not a service error. */
+export class HTTPError extends Error {
+  constructor(readonly status: number, message: string) {
+    super(message);
+    Object.setPrototypeOf(this, HTTPError.prototype);
+  }
+}
+
+/** ValidationError is thrown before sending a request with an invalid payload. */
+export class ValidationError extends Error {
+  constructor(readonly errors: string[]) {
+    super(errors.join("; "));
+    Object.setPrototypeOf(this, ValidationError.prototype);
+  }
+}
+
+/** isServiceError returns true if err is a service error with one of the names, any name if none. */
+export function isServiceError<N extends string>(err: unknown, ...names: N[]): err is ServiceError<N> {
+  return err instanceof ServiceError && (names.length === 0 || names.indexOf(err.name as N) >= 0);
+}
+
+/** pathParam returns the encoded value of a route parameter. */
+export function pathParam(v: unknown): string {
+  return encodeURIComponent(String(v));
+}
+
+/** request sends the HTTP request and returns the decoded response body. */
+export async function request(
+  options: ClientOptions,
+  method: string,
+  path: string,
+  query: object | undefined,
+  body: unknown,
+  opts?: CallOptions,
+): Promise<any> {
+  let url = options.baseURL.replace(/\/$/, "") + path;
+  if (query !== undefined) {
+    const params = new URLSearchParams();
+    Object.keys(query).forEach((k) => {
+      const v = (query as any)[k];
+      if (v !== undefined && v !== null && typeof v !== "object") {
+        params.append(k, String(v));
+      }
+    });
+    const qs = params.toString();
+    if (qs !== "") {
+      url += "?" + qs;
+    }
+  }
+  const headers: Record<string, string> = { ...options.headers, ...(opts && opts.headers) };
+  if (body !== undefined) {
+    headers["Content-Type"] = "application/json";
+  }
+  const send = options.fetch || fetch;
+  const resp = await send(url, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+    signal: opts && opts.signal,
+  });
+  const text = await resp.text();
+  let data: any = undefined;
+  if (text !== "") {
+    try {
+      data = JSON.parse(text);
+    } catch (e) {
+      if (resp.ok) {
+        throw e;
+      }
+    }
+  }
+  if (!resp.ok) {
+    if (data && typeof data.name === "string" && typeof data.id === "string") {
+      throw new ServiceError(resp.status, data);
+    }
+    throw new HTTPError(resp.status, (data && data.error) || text || resp.statusText);
+  }
+  return data;
+}
+`
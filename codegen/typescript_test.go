@@ -0,0 +1,149 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"go.zoe.im/goser/expr"
+	"go.zoe.im/goser/pkg/runtime"
+)
+
+func TestTSTypeName(t *testing.T) {
+	color := expr.NewEnumTypeExpr("color", expr.String, &expr.EnumValueExpr{Value: "red"})
+	user := &expr.UserTypeExpr{TypeName: "user", AttributeExpr: &expr.AttributeExpr{Type: &expr.Object{}}}
+	cases := map[string]struct {
+		dt       expr.DataType
+		expected string
+	}{
+		"boolean":   {expr.Boolean, "boolean"},
+		"int64":     {expr.Int64, "number"},
+		"timestamp": {expr.Timestamp, "string"},
+		"any":       {expr.Any, "unknown"},
+		"array":     {&expr.Array{ElemType: &expr.AttributeExpr{Type: user}}, "User[]"},
+		"map":       {&expr.Map{KeyType: &expr.AttributeExpr{Type: expr.String}, ElemType: &expr.AttributeExpr{Type: expr.Int}}, "Record<string, number>"},
+		"enum map":  {&expr.Map{KeyType: &expr.AttributeExpr{Type: color}, ElemType: &expr.AttributeExpr{Type: expr.Int}}, "Partial<Record<Color, number>>"},
+		"object": {&expr.Object{
+			{Name: "id", Attribute: &expr.AttributeExpr{Type: expr.String}},
+			{Name: "first-name", Attribute: &expr.AttributeExpr{Type: expr.String}},
+		}, `{ id?: string; "first-name"?: string }`},
+		"union array": {&expr.Array{ElemType: &expr.AttributeExpr{Type: &expr.Union{Values: []*expr.NamedAttributeExpr{
+			{Name: "s", Attribute: &expr.AttributeExpr{Type: expr.String}},
+			{Name: "i", Attribute: &expr.AttributeExpr{Type: expr.Int}},
+		}}}}, "(string | number)[]"},
+		"named union": {&expr.Union{TypeName: "pet"}, "Pet"},
+	}
+	for k, tc := range cases {
+		if got := TSTypeName(tc.dt); got != tc.expected {
+			t.Errorf("%s: got %q, expected %q", k, got, tc.expected)
+		}
+	}
+}
+
+func TestTSTypes(t *testing.T) {
+	spec, err := runtime.ParseSpec([]byte(`
+enums:
+  Role:
+    description: Role of the user.
+    values: [admin, member]
+models:
+  User:
+    description: User is a member of a team.
+    fields:
+      - name: name
+        type: string
+        required: true
+        min_length: 1
+        pattern: "^[a-z]+$"
+      - name: email
+        type: string
+        format: email
+      - name: age
+        type: int32
+        minimum: 0
+      - name: role
+        type: Role
+      - name: tags
+        type: array<string>
+        max_length: 10
+      - name: labels
+        type: map<string, string>
+services:
+  users:
+    description: Manages the users.
+    methods:
+      create:
+        payload: User
+        result: User
+        http: POST /users/{name}
+        errors:
+          conflict:
+            status: 409
+      list:
+        payload: User
+        result: array<User>
+        http: GET /users
+      ping: {}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := runtime.New()
+	if err := r.Load(spec); err != nil {
+		t.Fatal(err)
+	}
+	var types []expr.UserType
+	for _, e := range r.Enums() {
+		types = append(types, e)
+	}
+	for _, m := range r.Models() {
+		types = append(types, m.Type)
+	}
+
+	cases := map[string]struct {
+		code     string
+		contains []string
+	}{
+		"types": {TSTypes(types), []string{
+			"/** Role of the user. */\nexport type Role = \"admin\" | \"member\";\n",
+			"export const RoleValues: Role[] = [\"admin\", \"member\"];\n",
+			"/** User is a member of a team. */\nexport interface User {\n  name: string;\n  email?: string;\n  age?: number;\n  role?: Role;\n  tags?: string[];\n  labels?: Record<string, string>;\n}\n",
+			"export function validateUser(v: any, path: string = \"User\"): string[] {\n",
+			"  if (typeof v !== \"string\") {\n    errs.push(`${path} must be a string`);\n  } else {\n    if ([\"admin\", \"member\"].indexOf(v) < 0) {\n      errs.push(`${path} must be one of \"admin\", \"member\"`);\n",
+			"    if (v[\"name\"] === undefined || v[\"name\"] === null) {\n      errs.push(`${path}.name is required`);\n    } else {\n",
+			"if (v[\"name\"].length < 1) {",
+			"if (!new RegExp(\"^[a-z]+$\").test(v[\"name\"])) {",
+			"errs.push(`${path}.email must be a valid email`);",
+			"typeof v[\"age\"] !== \"number\" || !Number.isInteger(v[\"age\"])",
+			"if (v[\"age\"] < 0) {",
+			"errs.push(...validateRole(v[\"role\"], `${path}.role`));",
+			"if (v[\"tags\"].length > 10) {",
+			"v[\"tags\"].forEach((e1: any, i1: number) => {\n          if (typeof e1 !== \"string\") {\n            errs.push(`${path}.tags[${i1}] must be a string`);\n",
+			"Object.keys(v[\"labels\"]).forEach((k1: string) => {",
+			"function isObject(v: any): boolean {",
+		}},
+		"client": {TSServiceClient(r.Service("users"), ""), []string{
+			"import { CallOptions, ClientOptions, ServiceError, ValidationError, pathParam, request } from \"./client\";\nimport { User, validateUser } from \"./types\";\n",
+			"/** UsersCreateError is an error returned by users.create. */\nexport type UsersCreateError = ServiceError<\"conflict\">;\n",
+			"export class UsersClient {\n  constructor(private readonly options: ClientOptions) {}\n",
+			"  /**\n   * create calls users.create.\n   * @throws UsersCreateError\n   */\n  async create(payload: User, opts?: CallOptions): Promise<User> {\n    const errs = validateUser(payload);\n",
+			"return request(this.options, \"POST\", `/users/${pathParam(payload[\"name\"])}`, undefined, payload, opts);",
+			"return request(this.options, \"GET\", `/users`, payload, undefined, opts);",
+			"  async ping(opts?: CallOptions): Promise<void> {\n",
+		}},
+		"versioned client": {TSServiceClient(r.Service("users"), "v2"), []string{
+			"return request(this.options, \"POST\", `/v2/users/${pathParam(payload[\"name\"])}`, undefined, payload, opts);",
+			"return request(this.options, \"GET\", `/v2/users`, payload, undefined, opts);",
+		}},
+		"base": {TSClient(), []string{
+			"export class ServiceError<N extends string = string> extends Error {",
+			"export async function request(",
+		}},
+	}
+	for k, tc := range cases {
+		for _, s := range tc.contains {
+			if !strings.Contains(tc.code, s) {
+				t.Errorf("%s: got\n%s\nexpected it to contain\n%s", k, tc.code, s)
+			}
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+)
+
+// GoUnion returns the Go definition of the union: a sealed struct holding the
+// value, the interface implemented by the alternatives, a named type per
+// alternative and the JSON marshaling methods. The name of the alternative is
+// written to the discriminator field if any, otherwise the alternative is
+// inferred by strictly decoding the value in each alternative and the value
+// must match exactly one of them. An unset value is null. The generated code
+// uses the bytes, encoding/json and fmt packages.
+func GoUnion(u *expr.Union) string {
+	var (
+		b      strings.Builder
+		name   = Goify(u.Name(), true)
+		iface  = name + "Value"
+		marker = Goify(u.Name(), false)
+		alts   = make([]string, len(u.Values))
+	)
+	for i, nat := range u.Values {
+		alts[i] = name + Goify(nat.Name, true)
+	}
+
+	fmt.Fprintf(&b, "// %s is one of %s.\n", name, strings.Join(alts, ", "))
+	fmt.Fprintf(&b, "type %s struct {\n\tValue %s\n}\n\n", name, iface)
+	fmt.Fprintf(&b, "// %s is implemented by the alternatives of %s.\n", iface, name)
+	fmt.Fprintf(&b, "type %s interface {\n\t%s()\n}\n\n", iface, marker)
+	for i, nat := range u.Values {
+		writeDoc(&b, "", nat.Attribute.Description, fmt.Sprintf("%s is the %s alternative of %s.", alts[i], nat.Name, name), nat.Attribute.Meta)
+		fmt.Fprintf(&b, "type %s %s\n\n", alts[i], GoTypeName(nat.Attribute.Type))
+		fmt.Fprintf(&b, "func (%s) %s() {}\n\n", alts[i], marker)
+	}
+
+	b.WriteString("// MarshalJSON implements json.Marshaler, an unset value is null.\n")
+	fmt.Fprintf(&b, "func (u %s) MarshalJSON() ([]byte, error) {\n", name)
+	b.WriteString("\tif u.Value == nil {\n\t\treturn []byte(\"null\"), nil\n\t}\n")
+	if u.Discriminator == "" {
+		b.WriteString("\tswitch u.Value.(type) {\n")
+		fmt.Fprintf(&b, "\tcase %s:\n", strings.Join(alts, ", "))
+		b.WriteString("\t\treturn json.Marshal(u.Value)\n\t}\n")
+		fmt.Fprintf(&b, "\treturn nil, fmt.Errorf(\"invalid %s value %%T\", u.Value)\n", name)
+	} else {
+		b.WriteString("\tvar kind string\n\tswitch u.Value.(type) {\n")
+		for i, nat := range u.Values {
+			fmt.Fprintf(&b, "\tcase %s:\n\t\tkind = %q\n", alts[i], nat.Name)
+		}
+		b.WriteString("\tdefault:\n")
+		fmt.Fprintf(&b, "\t\treturn nil, fmt.Errorf(\"invalid %s value %%T\", u.Value)\n\t}\n", name)
+		b.WriteString("\tdata, err := json.Marshal(u.Value)\n\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+		b.WriteString("\tvar fields map[string]json.RawMessage\n")
+		b.WriteString("\tif err := json.Unmarshal(data, &fields); err != nil {\n\t\treturn nil, err\n\t}\n")
+		fmt.Fprintf(&b, "\tfields[%q], _ = json.Marshal(kind)\n", u.Discriminator)
+		b.WriteString("\treturn json.Marshal(fields)\n")
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString("// UnmarshalJSON implements json.Unmarshaler, null unsets the value.")
+	if u.Discriminator == "" {
+		b.WriteString(" The value must\n")
+		b.WriteString("// match exactly one alternative, the objects must have their required\n")
+		b.WriteString("// fields and no unknown fields.")
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "func (u *%s) UnmarshalJSON(data []byte) error {\n", name)
+	b.WriteString("\tif string(data) == \"null\" {\n\t\tu.Value = nil\n\t\treturn nil\n\t}\n")
+	if u.Discriminator == "" {
+		fmt.Fprintf(&b, "\tvar (\n\t\tmatches []%s\n\t\tfields  map[string]json.RawMessage\n\t)\n", iface)
+		b.WriteString("\t// fields stays nil if the value is not an object\n")
+		b.WriteString("\t_ = json.Unmarshal(data, &fields)\n")
+		for i, nat := range u.Values {
+			fmt.Fprintf(&b, "\t{\n\t\tvar v %s\n", alts[i])
+			b.WriteString("\t\tdec := json.NewDecoder(bytes.NewReader(data))\n\t\tdec.DisallowUnknownFields()\n")
+			cond := "dec.Decode(&v) == nil"
+			for _, f := range goRequiredFields(nat.Attribute) {
+				cond += fmt.Sprintf(" && fields[%q] != nil", f)
+			}
+			fmt.Fprintf(&b, "\t\tif %s {\n\t\t\tmatches = append(matches, v)\n\t\t}\n\t}\n", cond)
+		}
+		b.WriteString("\tswitch len(matches) {\n\tcase 0:\n")
+		fmt.Fprintf(&b, "\t\treturn fmt.Errorf(\"invalid %s value %%s\", data)\n", name)
+		b.WriteString("\tcase 1:\n\t\tu.Value = matches[0]\n\t\treturn nil\n\t}\n")
+		fmt.Fprintf(&b, "\treturn fmt.Errorf(\"ambiguous %s value %%s matches %%d alternatives\", data, len(matches))\n", name)
+	} else {
+		fmt.Fprintf(&b, "\tvar disc struct {\n\t\tKind string `json:%q`\n\t}\n", u.Discriminator)
+		b.WriteString("\tif err := json.Unmarshal(data, &disc); err != nil {\n\t\treturn err\n\t}\n")
+		b.WriteString("\tswitch disc.Kind {\n")
+		for i, nat := range u.Values {
+			fmt.Fprintf(&b, "\tcase %q:\n\t\tvar v %s\n", nat.Name, alts[i])
+			b.WriteString("\t\tif err := json.Unmarshal(data, &v); err != nil {\n\t\t\treturn err\n\t\t}\n\t\tu.Value = v\n")
+		}
+		b.WriteString("\tdefault:\n")
+		fmt.Fprintf(&b, "\t\treturn fmt.Errorf(\"invalid %s %s %%q\", disc.Kind)\n\t}\n\treturn nil\n", name, u.Discriminator)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// goRequiredFields returns the names of the required fields of the object
+// attribute or user type, nil if not an object.
+func goRequiredFields(att *expr.AttributeExpr) []string {
+	if ut, ok := att.Type.(expr.UserType); ok {
+		att = ut.Attribute()
+	}
+	obj := expr.AsObject(att.Type)
+	if obj == nil {
+		return nil
+	}
+	var res []string
+	for _, nat := range *obj {
+		if att.IsRequired(nat.Name) {
+			res = append(res, nat.Name)
+		}
+	}
+	return res
+}
+
+// ProtoUnion returns the proto3 message wrapping the union alternatives in a
+// oneof, the field numbers are given by the "rpc:tag" meta of the
+// alternatives and default to their position starting at 1.
+func ProtoUnion(u *expr.Union) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "message %s {\n  oneof value {\n", Goify(u.Name(), true))
+	for i, nat := range u.Values {
+		tag := fmt.Sprintf("%d", i+1)
+		if t, ok := nat.Attribute.Meta["rpc:tag"]; ok && len(t) > 0 {
+			tag = t[0]
+		}
+		writeComment(&b, "    ", nat.Attribute.Description, "")
+		fmt.Fprintf(&b, "    %s %s = %s%s;\n", ProtoTypeName(nat.Attribute.Type), SnakeCase(nat.Name), tag, ProtoDeprecatedOption(nat.Attribute.Meta))
+	}
+	b.WriteString("  }\n}\n")
+	return b.String()
+}
+
+// OpenAPIUnionSchema returns the OpenAPI oneOf schema of the union, user types
+// are referred to in the components section. The discriminator maps the
+// alternative names to their schemas.
+func OpenAPIUnionSchema(u *expr.Union) map[string]interface{} {
+	var (
+		oneOf   = make([]interface{}, len(u.Values))
+		mapping = make(map[string]interface{})
+	)
+	for i, nat := range u.Values {
+		oneOf[i] = openAPISchemaRef(nat.Attribute.Type)
+		if ut, ok := nat.Attribute.Type.(expr.UserType); ok {
+			mapping[nat.Name] = "#/components/schemas/" + Goify(ut.Name(), true)
+		}
+	}
+	schema := map[string]interface{}{"oneOf": oneOf}
+	if u.Discriminator != "" {
+		disc := map[string]interface{}{"propertyName": u.Discriminator}
+		if len(mapping) > 0 {
+			disc["mapping"] = mapping
+		}
+		schema["discriminator"] = disc
+	}
+	return schema
+}
+
+// openAPISchemaRef returns the reference to the schema of user types and
+// named unions and the inline schema of the other types.
+func openAPISchemaRef(dt expr.DataType) map[string]interface{} {
+	if ut, ok := dt.(expr.UserType); ok {
+		return map[string]interface{}{"$ref": "#/components/schemas/" + Goify(ut.Name(), true)}
+	}
+	if u, ok := dt.(*expr.Union); ok {
+		if u.TypeName == "" {
+			return OpenAPIUnionSchema(u)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + Goify(u.TypeName, true)}
+	}
+	typ, format := OpenAPIType(dt)
+	schema := map[string]interface{}{"type": typ}
+	if format != "" {
+		schema["format"] = format
+	}
+	if ar := expr.AsArray(dt); ar != nil {
+		schema["items"] = openAPISchemaRef(ar.ElemType.Type)
+	}
+	if m := expr.AsMap(dt); m != nil {
+		schema["additionalProperties"] = openAPISchemaRef(m.ElemType.Type)
+	}
+	return schema
+}
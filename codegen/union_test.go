@@ -0,0 +1,96 @@
+package codegen
+
+import (
+	"go/format"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.zoe.im/goser/expr"
+)
+
+func testUnion(discriminator string) *expr.Union {
+	obj := func(name, field string) *expr.AttributeExpr {
+		return &expr.AttributeExpr{Type: &expr.UserTypeExpr{
+			TypeName: name,
+			AttributeExpr: &expr.AttributeExpr{
+				Type:       &expr.Object{{Name: field, Attribute: &expr.AttributeExpr{Type: expr.String}}},
+				Validation: &expr.ValidationExpr{Required: []string{field}},
+			},
+		}}
+	}
+	return &expr.Union{
+		TypeName:      "payment_method",
+		Discriminator: discriminator,
+		Values: []*expr.NamedAttributeExpr{
+			{Name: "card", Attribute: obj("Card", "number")},
+			{Name: "bank_account", Attribute: obj("Bank", "iban")},
+		},
+	}
+}
+
+func TestGoUnion(t *testing.T) {
+	cases := map[string]struct {
+		union    *expr.Union
+		contains []string
+	}{
+		"discriminated": {testUnion("type"), []string{
+			"type PaymentMethod struct {\n\tValue PaymentMethodValue\n}",
+			"type PaymentMethodCard Card\n\nfunc (PaymentMethodCard) paymentMethod() {}",
+			"case PaymentMethodBankAccount:\n\t\tkind = \"bank_account\"",
+			"fields[\"type\"], _ = json.Marshal(kind)",
+			"Kind string `json:\"type\"`",
+		}},
+		"inferred": {testUnion(""), []string{
+			"case PaymentMethodCard, PaymentMethodBankAccount:\n\t\treturn json.Marshal(u.Value)",
+			"\tif u.Value == nil {\n\t\treturn []byte(\"null\"), nil\n\t}\n",
+			"\t\tvar v PaymentMethodBankAccount\n\t\tdec := json.NewDecoder(bytes.NewReader(data))\n\t\tdec.DisallowUnknownFields()\n\t\tif dec.Decode(&v) == nil && fields[\"iban\"] != nil {\n\t\t\tmatches = append(matches, v)\n",
+			"return fmt.Errorf(\"ambiguous PaymentMethod value %s matches %d alternatives\", data, len(matches))",
+		}},
+	}
+	for k, tc := range cases {
+		code := GoUnion(tc.union)
+		if _, err := format.Source([]byte("package p\n\n" + code)); err != nil {
+			t.Errorf("%s: invalid Go code: %v\n%s", k, err, code)
+		}
+		for _, s := range tc.contains {
+			if !strings.Contains(code, s) {
+				t.Errorf("%s: got\n%s\nexpected it to contain\n%s", k, code, s)
+			}
+		}
+	}
+}
+
+func TestProtoUnion(t *testing.T) {
+	u := testUnion("type")
+	u.Values[1].Attribute.Meta = expr.MetaExpr{"rpc:tag": []string{"5"}}
+	expected := `message PaymentMethod {
+  oneof value {
+    Card card = 1;
+    Bank bank_account = 5;
+  }
+}
+`
+	if actual := ProtoUnion(u); actual != expected {
+		t.Errorf("got\n%s\nexpected\n%s", actual, expected)
+	}
+}
+
+func TestOpenAPIUnionSchema(t *testing.T) {
+	expected := map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{"$ref": "#/components/schemas/Card"},
+			map[string]interface{}{"$ref": "#/components/schemas/Bank"},
+		},
+		"discriminator": map[string]interface{}{
+			"propertyName": "type",
+			"mapping": map[string]interface{}{
+				"card":         "#/components/schemas/Card",
+				"bank_account": "#/components/schemas/Bank",
+			},
+		},
+	}
+	if actual := OpenAPIUnionSchema(testUnion("type")); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got %#v, expected %#v", actual, expected)
+	}
+}
@@ -0,0 +1,179 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+)
+
+// VersionPackage returns the name of the Go package of the API version, e.g.
+// "v1" for "v1" or "1" and "v1_2" for "1.2".
+func VersionPackage(version string) string {
+	v := strings.ToLower(version)
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	return strings.NewReplacer(".", "_", "-", "_").Replace(v)
+}
+
+// ProtoPackage returns the proto package of the API version, e.g. "foo.v1".
+func ProtoPackage(name, version string) string {
+	return SnakeCase(name) + "." + VersionPackage(version)
+}
+
+// GoConvert returns the Go function converting the type generated in the
+// package of the from version to the type generated in the package of the to
+// version, from and to being the versions of the same user type (see
+// expr.AtVersion). The fields available in both versions with the same types
+// are copied, fields of object user types are converted with the functions
+// generated for them and the other user types, e.g. enums, are converted to
+// the type of the to package, the elements of arrays and maps being converted
+// the same way. The function is named after the type and the
+// versions, e.g. UserV1ToV2.
+func GoConvert(from, to expr.UserType, fromVersion, toVersion string) string {
+	var (
+		b       strings.Builder
+		fromPkg = VersionPackage(fromVersion)
+		toPkg   = VersionPackage(toVersion)
+		name    = goConvertName(to.Name(), fromPkg, toPkg)
+		typ     = Goify(to.Name(), true)
+		fobj    = expr.AsObject(from)
+		tobj    = expr.AsObject(to)
+	)
+	fmt.Fprintf(&b, "// %s converts a %s.%s to a %s.%s, the fields not available in\n", name, fromPkg, typ, toPkg, typ)
+	fmt.Fprintf(&b, "// both versions are ignored.\n")
+	fmt.Fprintf(&b, "func %s(v %s.%s) %s.%s {\n", name, fromPkg, typ, toPkg, typ)
+	fmt.Fprintf(&b, "\tvar res %s.%s\n", toPkg, typ)
+	if fobj != nil && tobj != nil {
+		for _, nat := range *tobj {
+			fatt := fobj.Attribute(nat.Name)
+			if fatt == nil {
+				continue
+			}
+			field := Goify(nat.Name, true)
+			switch {
+			case isConvertible(fatt.Type, nat.Attribute.Type):
+				fmt.Fprintf(&b, "\tif v.%s != nil {\n", field)
+				fmt.Fprintf(&b, "\t\tf := %s(*v.%s)\n", goConvertName(nat.Attribute.Type.Name(), fromPkg, toPkg), field)
+				fmt.Fprintf(&b, "\t\tres.%s = &f\n\t}\n", field)
+			case isConvertibleArray(fatt.Type, nat.Attribute.Type):
+				elem := expr.AsArray(nat.Attribute.Type).ElemType.Type
+				conv, _ := goConvertElem(expr.AsArray(fatt.Type).ElemType.Type, elem, "e", fromPkg, toPkg)
+				fmt.Fprintf(&b, "\tif v.%s != nil {\n", field)
+				fmt.Fprintf(&b, "\t\tres.%s = make([]%s, len(v.%s))\n", field, goVersionTypeName(elem, toPkg), field)
+				fmt.Fprintf(&b, "\t\tfor i, e := range v.%s {\n", field)
+				fmt.Fprintf(&b, "\t\t\tres.%s[i] = %s\n", field, conv)
+				b.WriteString("\t\t}\n\t}\n")
+			case isConvertibleMap(fatt.Type, nat.Attribute.Type):
+				fm, tm := expr.AsMap(fatt.Type), expr.AsMap(nat.Attribute.Type)
+				key, _ := goConvertElem(fm.KeyType.Type, tm.KeyType.Type, "k", fromPkg, toPkg)
+				conv, _ := goConvertElem(fm.ElemType.Type, tm.ElemType.Type, "e", fromPkg, toPkg)
+				fmt.Fprintf(&b, "\tif v.%s != nil {\n", field)
+				fmt.Fprintf(&b, "\t\tres.%s = make(map[%s]%s, len(v.%s))\n", field,
+					goVersionTypeName(tm.KeyType.Type, toPkg), goVersionTypeName(tm.ElemType.Type, toPkg), field)
+				fmt.Fprintf(&b, "\t\tfor k, e := range v.%s {\n", field)
+				fmt.Fprintf(&b, "\t\t\tres.%s[%s] = %s\n", field, key, conv)
+				b.WriteString("\t\t}\n\t}\n")
+			case isPrimitiveUserType(nat.Attribute.Type) && expr.Equal(fatt.Type, nat.Attribute.Type):
+				fmt.Fprintf(&b, "\tres.%s = %s.%s(v.%s)\n", field, toPkg, Goify(nat.Attribute.Type.Name(), true), field)
+			case expr.Equal(fatt.Type, nat.Attribute.Type) && !hasUserType(nat.Attribute.Type):
+				fmt.Fprintf(&b, "\tres.%s = v.%s\n", field, field)
+			default:
+				fmt.Fprintf(&b, "\t// %s has different types in %s and %s\n", field, fromPkg, toPkg)
+			}
+		}
+	}
+	b.WriteString("\treturn res\n}\n")
+	return b.String()
+}
+
+// goConvertName returns the name of the function converting the type between
+// the version packages.
+func goConvertName(name, fromPkg, toPkg string) string {
+	return Goify(name, true) + Goify(fromPkg, true) + "To" + Goify(toPkg, true)
+}
+
+// isConvertible returns true if the types are versions of the same object
+// user type.
+func isConvertible(from, to expr.DataType) bool {
+	fu, ok := from.(expr.UserType)
+	if !ok {
+		return false
+	}
+	tu, ok := to.(expr.UserType)
+	return ok && fu.Name() == tu.Name() && expr.IsObject(tu)
+}
+
+// isConvertibleArray returns true if the types are arrays whose elements are
+// versions of the same user type, the elements being converted with
+// goConvertElem.
+func isConvertibleArray(from, to expr.DataType) bool {
+	fa, ta := expr.AsArray(from), expr.AsArray(to)
+	if fa == nil || ta == nil || !hasUserType(ta.ElemType.Type) {
+		return false
+	}
+	_, ok := goConvertElem(fa.ElemType.Type, ta.ElemType.Type, "", "", "")
+	return ok
+}
+
+// isConvertibleMap returns true if the types are maps whose keys or elements
+// are versions of the same user types, the keys and elements being converted
+// with goConvertElem.
+func isConvertibleMap(from, to expr.DataType) bool {
+	fm, tm := expr.AsMap(from), expr.AsMap(to)
+	if fm == nil || tm == nil || !hasUserType(to) {
+		return false
+	}
+	_, kok := goConvertElem(fm.KeyType.Type, tm.KeyType.Type, "", "", "")
+	_, eok := goConvertElem(fm.ElemType.Type, tm.ElemType.Type, "", "", "")
+	return kok && eok
+}
+
+// goConvertElem returns the Go expression converting the array element, map
+// key or map element v of the from type to the to type, false if the types
+// differ. The elements of object user types are converted with the functions
+// generated for them, the other user types with a type conversion.
+func goConvertElem(from, to expr.DataType, v, fromPkg, toPkg string) (string, bool) {
+	switch {
+	case isConvertible(from, to):
+		return goConvertName(to.Name(), fromPkg, toPkg) + "(" + v + ")", true
+	case isPrimitiveUserType(to) && expr.Equal(from, to):
+		return toPkg + "." + Goify(to.Name(), true) + "(" + v + ")", true
+	case expr.Equal(from, to) && !hasUserType(to):
+		return v, true
+	}
+	return "", false
+}
+
+// goVersionTypeName returns the Go type name of the array element, map key or
+// map element type in the version package.
+func goVersionTypeName(dt expr.DataType, pkg string) string {
+	if _, ok := dt.(expr.UserType); ok {
+		return pkg + "." + GoTypeName(dt)
+	}
+	return GoTypeName(dt)
+}
+
+// isPrimitiveUserType returns true if the type is a user type of a primitive
+// type, e.g. an enum.
+func isPrimitiveUserType(dt expr.DataType) bool {
+	_, ok := dt.(expr.UserType)
+	return ok && expr.IsPrimitive(dt)
+}
+
+// hasUserType returns true if the type is or contains a user type or a named
+// union, their Go types differ from a version package to another.
+func hasUserType(dt expr.DataType) bool {
+	switch t := dt.(type) {
+	case expr.UserType:
+		return true
+	case *expr.Union:
+		return t.TypeName != ""
+	case *expr.Array:
+		return hasUserType(t.ElemType.Type)
+	case *expr.Map:
+		return hasUserType(t.KeyType.Type) || hasUserType(t.ElemType.Type)
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package codegen
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"go.zoe.im/goser/expr"
+)
+
+func TestVersionPackage(t *testing.T) {
+	cases := map[string]string{
+		"v1":  "v1",
+		"2":   "v2",
+		"1.2": "v1_2",
+		"V3":  "v3",
+	}
+	for version, expected := range cases {
+		if actual := VersionPackage(version); actual != expected {
+			t.Errorf("%s: got %q, expected %q", version, actual, expected)
+		}
+	}
+	if actual := ProtoPackage("UserService", "v1"); actual != "user_service.v1" {
+		t.Errorf("got %q, expected %q", actual, "user_service.v1")
+	}
+}
+
+func TestGoConvert(t *testing.T) {
+	var (
+		v2    = expr.MetaExpr{expr.VersionSinceMeta: {"v2"}}
+		until = expr.MetaExpr{expr.VersionUntilMeta: {"v1"}}
+		role  = expr.NewEnumTypeExpr("role", expr.String, &expr.EnumValueExpr{Value: "admin"})
+		team  = &expr.UserTypeExpr{TypeName: "team", AttributeExpr: &expr.AttributeExpr{Type: &expr.Object{
+			{Name: "name", Attribute: &expr.AttributeExpr{Type: expr.String}},
+		}}}
+		user = &expr.UserTypeExpr{TypeName: "user", AttributeExpr: &expr.AttributeExpr{Type: &expr.Object{
+			{Name: "id", Attribute: &expr.AttributeExpr{Type: expr.String}},
+			{Name: "login", Attribute: &expr.AttributeExpr{Type: expr.String, Meta: until}},
+			{Name: "email", Attribute: &expr.AttributeExpr{Type: expr.String, Meta: v2}},
+			{Name: "age", Attribute: &expr.AttributeExpr{Type: expr.Int32, Meta: until}},
+			{Name: "age", Attribute: &expr.AttributeExpr{Type: expr.Int64, Meta: v2}},
+			{Name: "team", Attribute: &expr.AttributeExpr{Type: team}},
+			{Name: "role", Attribute: &expr.AttributeExpr{Type: role}},
+			{Name: "teams", Attribute: &expr.AttributeExpr{Type: &expr.Array{ElemType: &expr.AttributeExpr{Type: team}}}},
+			{Name: "byname", Attribute: &expr.AttributeExpr{Type: &expr.Map{KeyType: &expr.AttributeExpr{Type: expr.String}, ElemType: &expr.AttributeExpr{Type: team}}}},
+			{Name: "roles", Attribute: &expr.AttributeExpr{Type: &expr.Map{KeyType: &expr.AttributeExpr{Type: role}, ElemType: &expr.AttributeExpr{Type: expr.Int}}}},
+			{Name: "tags", Attribute: &expr.AttributeExpr{Type: &expr.Map{KeyType: &expr.AttributeExpr{Type: expr.String}, ElemType: &expr.AttributeExpr{Type: expr.String}}}},
+		}}}
+	)
+	// the duplicated age field is only available in one version each
+	from, to := expr.AtVersion(user, "v1").(expr.UserType), expr.AtVersion(user, "v2").(expr.UserType)
+	code := GoConvert(from, to, "v1", "v2")
+	if _, err := format.Source([]byte("package p\n\n" + code)); err != nil {
+		t.Errorf("invalid Go code: %v\n%s", err, code)
+	}
+	for _, s := range []string{
+		"func UserV1ToV2(v v1.User) v2.User {",
+		"\tres.ID = v.ID\n",
+		"\t// Age has different types in v1 and v2\n",
+		"\tif v.Team != nil {\n\t\tf := TeamV1ToV2(*v.Team)\n\t\tres.Team = &f\n\t}\n",
+		"\tres.Role = v2.Role(v.Role)\n",
+		"\t\t\tres.Teams[i] = TeamV1ToV2(e)\n",
+		"\t\tres.Byname = make(map[string]v2.Team, len(v.Byname))\n\t\tfor k, e := range v.Byname {\n\t\t\tres.Byname[k] = TeamV1ToV2(e)\n",
+		"\t\t\tres.Roles[v2.Role(k)] = e\n",
+		"\tres.Tags = v.Tags\n",
+	} {
+		if !strings.Contains(code, s) {
+			t.Errorf("got\n%s\nexpected it to contain\n%s", code, s)
+		}
+	}
+	for _, s := range []string{"Login", "Email", "Byname has different types"} {
+		if strings.Contains(code, s) {
+			t.Errorf("got\n%s\nexpected it not to contain %s", code, s)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package dsl
 import (
 	"goa.design/goa/v3/eval"
 	"goa.design/goa/v3/expr"
+
+	goser "go.zoe.im/goser/expr"
 )
 
 // API defines a network service API. It provides the API name, description and other global
@@ -35,13 +37,6 @@ import (
 //            Description("doc description"),
 //            URL("doc URL"),
 //        ),
-//        Server("addersvr",
-//            Host(
-//                "development",
-//                URI("http://localhost:80"),
-//                URI("grpc://localhost:8080"),
-//            ),
-//        ),
 //    )
 //
 func NewAPI(name string, opts ...Option) *expr.APIExpr {
@@ -86,7 +81,8 @@ func Title(val string) Option {
 	}
 }
 
-// Version specifies the API version. One design describes one version.
+// Version specifies the API version. One design describes one version, use
+// Versions to maintain several versions side by side.
 //
 // Version must appear in a API expression.
 //
@@ -201,11 +197,12 @@ func TermsOfService(terms string) Option {
 	}
 }
 
-// Name sets the contact or license name.
+// Name sets the contact, license or enum value name.
 //
-// Name must appear in a Contact or License expression.
+// Name must appear in a Contact, License or EnumValue expression.
 //
-// Name takes a single argument which is the contact or license name.
+// Name takes a single argument which is the contact, license or enum value
+// name.
 //
 // Example:
 //
@@ -224,6 +221,8 @@ func Name(name string) Option {
 			def.Name = name
 		case *expr.LicenseExpr:
 			def.Name = name
+		case *goser.EnumValueExpr:
+			def.Name = name
 		default:
 			// TODO: warning
 		}
@@ -5,6 +5,8 @@ import (
 
 	"goa.design/goa/v3/eval"
 	"goa.design/goa/v3/expr"
+
+	goser "go.zoe.im/goser/expr"
 )
 
 // Attribute describes a field of an object.
@@ -142,14 +144,17 @@ func Field(tag interface{}, name string, opts ...Option) Option {
 // Default must appear in an Attribute DSL.
 //
 // Default takes one parameter: the default value.
+//
+// The default value must satisfy the attribute type and validations (enum,
+// format, pattern, length and range), this is checked when the design is
+// validated.
 func Default(def interface{}) Option {
 	return func(v eval.Expression) {
 		switch e := v.(type) {
-		case *expr.AttributeExpr:
+		case *goser.AttributeExpr:
 			if e.Type != nil && !e.Type.IsCompatible(def) {
-				// TODO:
-				// eval.ReportError("default value %#v is incompatible with attribute of type %s",
-				// 	def, expr.QualifiedTypeName(e.Type))
+				eval.ReportError("default value %#v is incompatible with attribute of type %s",
+					def, goser.QualifiedTypeName(e.Type))
 				return
 			}
 			e.SetDefault(def)
@@ -159,66 +164,78 @@ func Default(def interface{}) Option {
 	}
 }
 
-// Example provides an example value for a type, a parameter, a header or any
-// attribute. Example supports two syntaxes: one syntax accepts two arguments
-// where the first argument is a summary describing the example and the second a
-// value provided directly or via a DSL which may also specify a long
-// description. The other syntax accepts a single argument and is equivalent to
-// using the first syntax where the summary is the string "default". When using
-// a DSL the Value function can be used to provide the example value.
+// Example provides an example value for an attribute. Example takes a
+// summary describing the example, "default" if empty, and the options of the
+// example: Value provides the example value and Description a long
+// description.
 //
 // If no example is explicitly provided in an attribute expression then a random
 // example is generated unless the "swagger:example" meta is set to "false".
 // See Meta.
 //
-// Example must appear in a Attributes, Attribute, Params, Param, Headers or
-// Header DSL.
+// Example must appear in an Attribute.
 //
-// Example takes one or two arguments: an optional summary and the example value
-// or defining DSL.
+// The example value must satisfy the attribute type and validations, this is
+// checked when the design is validated.
 //
 // Examples:
 //
-//    Params(func() {
-//        Param("ZipCode:zip-code", String, "Zip code filter", func() {
-//            Example("Santa Barbara", "93111")
-//            Example("93117") // same as Example("default", "93117")
-//        })
-//    })
-//
-//    Attributes(func() {
-//        Attribute("ID", Int64, "ID is the unique bottle identifier")
-//        Example("The first bottle", func() {
-//            Description("This bottle has an ID set to 1")
-//            Value(Val{"ID": 1})
-//        })
-//        Example("Another bottle", func() {
-//            Description("This bottle has an ID set to 5")
-//            Value(Val{"ID": 5})
-//        })
-//    })
+//    Attribute("zip_code", Type(String),
+//        Example("Santa Barbara", Value("93111")),
+//        Example("", Value("93117")), // same as Example("default", ...)
+//    )
+//
+//    Attribute("bottle", Type(Bottle),
+//        Example("The first bottle",
+//            Description("This bottle has an ID set to 1"),
+//            Value(Val{"ID": 1}),
+//        ),
+//    )
 //
 func Example(summary string, opts ...Option) Option {
-	
 	if summary == "" {
 		summary = "default"
 	}
 
-	ex := &expr.ExampleExpr{Summary: summary}
+	ex := &goser.ExampleExpr{Summary: summary}
 
 	for _, o := range opts {
 		o(ex)
 	}
 
-	// TODO: finish value
-	if ex.Value == nil {
-		// TODO:
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *goser.AttributeExpr:
+			if e.Type != nil && ex.Value != nil && !e.Type.IsCompatible(ex.Value) {
+				eval.ReportError("example value %#v is incompatible with attribute of type %s",
+					ex.Value, goser.QualifiedTypeName(e.Type))
+				return
+			}
+			e.UserExamples = append(e.UserExamples, ex)
+		default:
+			// TODO: warning
+		}
 	}
+}
 
+// Value sets the example value.
+//
+// Value must appear in Example.
+//
+// Value takes one argument: the example value.
+//
+// Example:
+//
+//    Example("The first bottle",
+//        Description("This bottle has an ID set to 1"),
+//        Value(Val{"ID": 1}),
+//    )
+//
+func Value(val interface{}) Option {
 	return func(v eval.Expression) {
 		switch e := v.(type) {
-		case *expr.AttributeExpr:
-			e.UserExamples = append(e.UserExamples, ex)
+		case *goser.ExampleExpr:
+			e.Value = val
 		default:
 			// TODO: warning
 		}
@@ -287,3 +304,77 @@ func parseAttributeArgs(baseAttr *expr.AttributeExpr, args ...interface{}) (expr
 
 	return dataType, description, fn
 }
+
+// Extend adds the parameter type attributes to the type using Extend. The
+// parameter type must be an object.
+//
+// Extend may be used in Type or Attribute. The attributes of the extended type
+// override the attributes with the same names, the required fields and
+// validations are merged.
+//
+// Example:
+//
+//     // Define a type with common attributes
+//     var PaginatedParams = Type("PaginatedParams",
+//         Attribute("page", Int),
+//         Attribute("size", Int),
+//     )
+//
+//     // Define a type which extends the type above
+//     var ListParams = Type("ListParams",
+//         Attribute("filter", String),
+//         Extend(PaginatedParams),
+//     )
+//
+func Extend(t goser.DataType) Option {
+	return func(v eval.Expression) {
+		if !goser.IsObject(t) {
+			eval.ReportError("cannot extend %s: only object types can be extended", goser.QualifiedTypeName(t))
+			return
+		}
+		switch e := v.(type) {
+		case *goser.AttributeExpr:
+			e.Bases = append(e.Bases, t)
+		case *goser.UserTypeExpr:
+			e.Bases = append(e.Bases, t)
+		default:
+			// TODO: warning
+		}
+	}
+}
+
+// Reference sets a type or result type reference. The value of the attributes
+// of the reference type are used as defaults for the attributes with the same
+// names: the description, validations, default value and meta are inherited if
+// not set. The reference type must be an object.
+//
+// Reference may be used in Type or Attribute. Multiple references may be set,
+// the first one wins when several define the same property.
+//
+// Example:
+//
+//     var Bottle = Type("bottle",
+//         Attribute("name", String, Description("The bottle name")),
+//     )
+//
+//     var UpdateBottlePayload = Type("UpdateBottlePayload",
+//         Reference(Bottle),
+//         Attribute("name"), // inherits type, description and validations from Bottle
+//     )
+//
+func Reference(t goser.DataType) Option {
+	return func(v eval.Expression) {
+		if !goser.IsObject(t) {
+			eval.ReportError("cannot reference %s: only object types can be referenced", goser.QualifiedTypeName(t))
+			return
+		}
+		switch e := v.(type) {
+		case *goser.AttributeExpr:
+			e.References = append(e.References, t)
+		case *goser.UserTypeExpr:
+			e.References = append(e.References, t)
+		default:
+			// TODO: warning
+		}
+	}
+}
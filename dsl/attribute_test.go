@@ -0,0 +1,63 @@
+package dsl
+
+import (
+	"strings"
+	"testing"
+
+	"go.zoe.im/goser/expr"
+)
+
+func TestExtendReference(t *testing.T) {
+	var (
+		page = &expr.UserTypeExpr{TypeName: "Page", AttributeExpr: &expr.AttributeExpr{Type: &expr.Object{
+			{Name: "size", Attribute: &expr.AttributeExpr{Type: expr.Int, Description: "Size of the page"}},
+		}}}
+		list = &expr.UserTypeExpr{TypeName: "List", AttributeExpr: &expr.AttributeExpr{Type: &expr.Object{
+			{Name: "filter", Attribute: &expr.AttributeExpr{Type: expr.String}},
+		}}}
+	)
+	Extend(page)(list)
+	if len(list.Bases) != 1 || list.Bases[0] != page {
+		t.Fatalf("got bases %v, expected Page", list.Bases)
+	}
+	if verr := list.Validate("", nil); len(verr.Errors) > 0 {
+		t.Errorf("unexpected validation errors: %v", verr.Errors)
+	}
+	list.Finalize()
+	if att := expr.AsObject(list).Attribute("size"); att == nil || att.Description != "Size of the page" {
+		t.Errorf("got size %#v, expected it to be extended from Page", att)
+	}
+
+	// a cycle through the DSL is reported by the validation
+	Reference(list)(page)
+	verr := page.Validate("", nil)
+	if len(verr.Errors) == 0 || verr.Errors[0].Error() != "inheritance cycle Page -> List -> Page" {
+		t.Errorf("got errors %v, expected inheritance cycle", verr.Errors)
+	}
+}
+
+func TestDefaultExample(t *testing.T) {
+	max := 3
+	att := &expr.AttributeExpr{Type: expr.String, Validation: &expr.ValidationExpr{MaxLength: &max}}
+	Default("abc")(att)
+	Example("short", Value("ab"), Description("A short code"))(att)
+	Example("", Value("abcd"))(att)
+	if att.DefaultValue != "abc" {
+		t.Errorf("got default %#v, expected \"abc\"", att.DefaultValue)
+	}
+	if len(att.UserExamples) != 2 {
+		t.Fatalf("got %d examples, expected 2", len(att.UserExamples))
+	}
+	if ex := att.UserExamples[0]; ex.Summary != "short" || ex.Value != "ab" || ex.Description != "A short code" {
+		t.Errorf("got example %#v", ex)
+	}
+
+	// the examples set with the DSL are validated against the validations
+	verr := att.Validate("", nil)
+	if len(verr.Errors) != 1 {
+		t.Fatalf("got errors %v, expected 1", verr.Errors)
+	}
+	if actual := verr.Errors[0].Error(); !strings.Contains(actual, `example "default" value "abcd" is invalid`) {
+		t.Errorf("got error %q, expected invalid default example", actual)
+	}
+}
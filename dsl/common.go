@@ -3,6 +3,8 @@ package dsl
 import (
 	"goa.design/goa/v3/eval"
 	"goa.design/goa/v3/expr"
+
+	goser "go.zoe.im/goser/expr"
 )
 
 // Docs provides external documentation URLs. It is used by the generated
@@ -50,7 +52,7 @@ func Docs(opts ...Option) Option {
 // Description sets the expression description.
 //
 // Description may appear in API, Docs, Type or Attribute.
-// Description may also appear in Response and FileServer.
+// Description may also appear in Response, FileServer, EnumType and EnumValue.
 //
 // Description accepts one arguments: the description string.
 //
@@ -66,10 +68,6 @@ func Description(d string) Option {
 		switch e := v.(type) {
 		case *expr.APIExpr:
 			e.Description = d
-		case *expr.ServerExpr:
-			e.Description = d
-		case *expr.HostExpr:
-			e.Description = d
 		case *expr.ServiceExpr:
 			e.Description = d
 		case *expr.ResultTypeExpr:
@@ -80,8 +78,6 @@ func Description(d string) Option {
 			e.Description = d
 		case *expr.MethodExpr:
 			e.Description = d
-		case *expr.ExampleExpr:
-			e.Description = d
 		case *expr.SchemeExpr:
 			e.Description = d
 		case *expr.HTTPResponseExpr:
@@ -90,6 +86,16 @@ func Description(d string) Option {
 			e.Description = d
 		case *expr.GRPCResponseExpr:
 			e.Description = d
+		case *goser.AttributeExpr:
+			e.Description = d
+		case *goser.EnumValueExpr:
+			e.Description = d
+		case *goser.ExampleExpr:
+			e.Description = d
+		case *goser.ServerExpr:
+			e.Description = d
+		case *goser.HostExpr:
+			e.Description = d
 		default:
 			// TODO: warning
 		}
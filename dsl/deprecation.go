@@ -0,0 +1,56 @@
+package dsl
+
+import (
+	"goa.design/goa/v3/eval"
+	"goa.design/goa/v3/expr"
+
+	goser "go.zoe.im/goser/expr"
+)
+
+// Deprecated marks a design element as deprecated. The generated Go code
+// documents the element with a "Deprecated:" comment, the proto definitions
+// use the deprecated option, the OpenAPI schemas set the deprecated flag and
+// the generated servers warn the clients using the element.
+//
+// Deprecated may appear in attributes, types, result types, methods, services
+// and enum values.
+//
+// Deprecated accepts two arguments: the reason of the deprecation, e.g. the
+// element to use instead, and the version since which the element is
+// deprecated. Both may be empty.
+//
+// Example:
+//
+//     var User = Type(
+//         "User",
+//         Attribute("login", String, Deprecated("use email instead", "v2")),
+//         Attribute("email", String),
+//     )
+//
+func Deprecated(reason, since string) Option {
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.AttributeExpr:
+			e.Meta = deprecate(e.Meta, reason, since)
+		case *expr.MethodExpr:
+			e.Meta = deprecate(e.Meta, reason, since)
+		case *expr.ServiceExpr:
+			e.Meta = deprecate(e.Meta, reason, since)
+		case expr.CompositeExpr:
+			att := e.Attribute()
+			att.Meta = deprecate(att.Meta, reason, since)
+		case *goser.AttributeExpr:
+			e.Meta = goser.Deprecate(e.Meta, reason, since)
+		case *goser.EnumValueExpr:
+			e.Meta = goser.Deprecate(e.Meta, reason, since)
+		default:
+			// TODO: warning
+		}
+	}
+}
+
+// deprecate sets the deprecation meta of the goser expressions on the meta of
+// the design expressions.
+func deprecate(meta expr.MetaExpr, reason, since string) expr.MetaExpr {
+	return expr.MetaExpr(goser.Deprecate(goser.MetaExpr(meta), reason, since))
+}
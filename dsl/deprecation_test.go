@@ -0,0 +1,32 @@
+package dsl
+
+import (
+	"testing"
+
+	"go.zoe.im/goser/expr"
+)
+
+func TestDeprecated(t *testing.T) {
+	att := &expr.AttributeExpr{Type: expr.String}
+	Deprecated("use email instead", "v2")(att)
+	value := EnumValue("guest", Deprecated("", ""))
+
+	cases := map[string]struct {
+		meta   expr.MetaExpr
+		reason string
+		since  string
+	}{
+		"attribute":  {att.Meta, "use email instead", "v2"},
+		"enum value": {value.Meta, "", ""},
+	}
+	for k, tc := range cases {
+		d := expr.Deprecation(tc.meta)
+		if d == nil {
+			t.Errorf("%s: expected deprecation", k)
+			continue
+		}
+		if d.Reason != tc.reason || d.Since != tc.since {
+			t.Errorf("%s: got %q since %q, expected %q since %q", k, d.Reason, d.Since, tc.reason, tc.since)
+		}
+	}
+}
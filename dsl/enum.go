@@ -0,0 +1,112 @@
+package dsl
+
+import (
+	"goa.design/goa/v3/eval"
+
+	"go.zoe.im/goser/expr"
+)
+
+// EnumType defines a named enumeration which can be used as the type of
+// attributes. The generated code contains typed constants, a proto enum, an
+// OpenAPI enum schema and a SQL check constraint.
+//
+// EnumType is a top level DSL.
+//
+// EnumType takes the name of the enum as first argument followed by the
+// values, a value is either the value itself (a string or an integer) or an
+// EnumValue expression. The type of the enum is the type of the first value.
+// Description may also be given to describe the enum.
+//
+// Example:
+//
+//     var Status = EnumType("Status",
+//         Description("Status of user"),
+//         "active",
+//         EnumValue("inactive", Name("disabled"), Tag(3)),
+//     )
+//
+//     Attribute("status", Type(Status))
+//
+func EnumType(name string, args ...interface{}) *expr.EnumTypeExpr {
+	if name == "" {
+		eval.ReportError("enum name cannot be empty")
+		return nil
+	}
+	var (
+		values []*expr.EnumValueExpr
+		opts   []Option
+	)
+	for _, arg := range args {
+		switch a := arg.(type) {
+		case Option:
+			opts = append(opts, a)
+		case *expr.EnumValueExpr:
+			values = append(values, a)
+		case string, int, int32, int64, uint, uint32, uint64:
+			values = append(values, &expr.EnumValueExpr{Value: a})
+		default:
+			eval.ReportError("invalid value %#v for enum %s, must be a string, an integer or an EnumValue", arg, name)
+			return nil
+		}
+	}
+	if len(values) == 0 {
+		eval.ReportError("enum %s must define at least one value", name)
+		return nil
+	}
+	base := expr.String
+	if _, ok := values[0].Value.(string); !ok {
+		base = expr.Int
+		if _, ok := values[0].Value.(int32); ok {
+			base = expr.Int32
+		}
+	}
+	enum := expr.NewEnumTypeExpr(name, base, values...)
+	for _, o := range opts {
+		o(enum.AttributeExpr)
+	}
+	return enum
+}
+
+// EnumValue defines a value of an enum with its name, description and tag.
+//
+// EnumValue must appear in EnumType.
+//
+// EnumValue takes the value as first argument followed by the Name,
+// Description and Tag options. The name defaults to the value and the tag to
+// the position of the value starting at 1.
+//
+// Example:
+//
+//     EnumValue(2, Name("warning"), Description("Warning level"), Tag(2))
+//
+func EnumValue(val interface{}, opts ...Option) *expr.EnumValueExpr {
+	v := &expr.EnumValueExpr{Value: val}
+	for _, o := range opts {
+		o(v)
+	}
+	return v
+}
+
+// Tag sets the number of an enum value in the generated proto enum, 0 is
+// reserved for the UNSPECIFIED value.
+//
+// Tag must appear in EnumValue.
+//
+// Example:
+//
+//     EnumValue("inactive", Tag(3))
+//
+func Tag(tag int) Option {
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.EnumValueExpr:
+			if tag <= 0 {
+				eval.ReportError("enum value tag must be greater than 0, got %d", tag)
+				return
+			}
+			e.Tag = tag
+		default:
+			// TODO: warning
+		}
+	}
+}
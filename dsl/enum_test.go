@@ -0,0 +1,36 @@
+package dsl
+
+import (
+	"testing"
+
+	"go.zoe.im/goser/expr"
+)
+
+func TestEnumType(t *testing.T) {
+	status := EnumType("Status",
+		Description("Status of user"),
+		"active",
+		EnumValue("inactive", Name("disabled"), Description("Disabled user"), Tag(3)),
+	)
+	if status == nil {
+		t.Fatal("got nil enum")
+	}
+	if status.Name() != "Status" || status.Description != "Status of user" {
+		t.Errorf("got enum %q with description %q", status.Name(), status.Description)
+	}
+	if status.Type != expr.String {
+		t.Errorf("got base %s, expected string", status.Type.Name())
+	}
+	if len(status.Values) != 2 {
+		t.Fatalf("got %d values, expected 2", len(status.Values))
+	}
+	v := status.Values[1]
+	if v.Value != "inactive" || v.Name != "disabled" || v.Description != "Disabled user" || v.Tag != 3 {
+		t.Errorf("got value %#v", v)
+	}
+
+	level := EnumType("Level", int32(1), int32(2))
+	if level == nil || level.Type != expr.Int32 {
+		t.Errorf("got enum %#v, expected int32 enum", level)
+	}
+}
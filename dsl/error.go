@@ -1,8 +1,12 @@
 package dsl
 
 import (
+	"strconv"
+
 	"goa.design/goa/v3/eval"
 	"goa.design/goa/v3/expr"
+
+	goser "go.zoe.im/goser/expr"
 )
 
 // Error describes a method error return value. The description includes a
@@ -78,7 +82,7 @@ func Temporary() Option {
 			if e.Meta == nil {
 				e.Meta = make(expr.MetaExpr)
 			}
-			e.Meta["goa:error:temporary"] = nil
+			e.Meta[goser.ErrorTemporaryMeta] = nil
 		default:
 			// TODO: warning
 		}
@@ -107,7 +111,7 @@ func Timeout() Option {
 			if e.Meta == nil {
 				e.Meta = make(expr.MetaExpr)
 			}
-			e.Meta["goa:error:timeout"] = nil
+			e.Meta[goser.ErrorTimeoutMeta] = nil
 		default:
 			// TODO: warning
 		}
@@ -137,7 +141,81 @@ func Fault() Option {
 			if e.Meta == nil {
 				e.Meta = make(expr.MetaExpr)
 			}
-			e.Meta["goa:error:fault"] = nil
+			e.Meta[goser.ErrorFaultMeta] = nil
+		default:
+			// TODO: warning
+		}
+	}
+}
+
+// HTTPStatus sets the HTTP status of the error responses. The status defaults
+// to 500 for faults, 504 for timeouts, 503 for temporary errors and 400 for
+// the other errors.
+//
+// HTTPStatus must appear in a Error expression.
+//
+// HTTPStatus takes a single argument which is the HTTP status.
+//
+// Example:
+//
+//    var _ = Service(
+//        "divider",
+//        Error(
+//            "not_found",
+//            HTTPStatus(http.StatusNotFound),
+//            GRPCCode(CodeNotFound),
+//        )
+//    )
+//
+func HTTPStatus(status int) Option {
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.AttributeExpr:
+			if status < 400 || status > 599 {
+				eval.ReportError("error HTTP status must be between 400 and 599, got %d", status)
+				return
+			}
+			if e.Meta == nil {
+				e.Meta = make(expr.MetaExpr)
+			}
+			e.Meta[goser.ErrorHTTPStatusMeta] = []string{strconv.Itoa(status)}
+		default:
+			// TODO: warning
+		}
+	}
+}
+
+// GRPCCode sets the gRPC code of the error responses, one of the Code
+// constants. The code defaults to the code matching the HTTP status of the
+// error.
+//
+// GRPCCode must appear in a Error expression.
+//
+// GRPCCode takes a single argument which is the gRPC code.
+//
+// Example:
+//
+//    var _ = Service(
+//        "divider",
+//        Error(
+//            "request_timeout",
+//            Timeout(),
+//            GRPCCode(CodeDeadlineExceeded),
+//        )
+//    )
+//
+func GRPCCode(code int) Option {
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.AttributeExpr:
+			if code <= CodeOK || code > CodeUnauthenticated {
+				eval.ReportError("error gRPC code must be between 1 and 16, got %d", code)
+				return
+			}
+			if e.Meta == nil {
+				e.Meta = make(expr.MetaExpr)
+			}
+			e.Meta[goser.ErrorGRPCCodeMeta] = []string{strconv.Itoa(code)}
 		default:
 			// TODO: warning
 		}
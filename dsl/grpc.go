@@ -100,11 +100,9 @@ func GRPC(opts ...Option) Option {
 	return func(v eval.Expression) {
 		switch e := v.(type) {
 		case *expr.ServiceExpr:
-			res := expr.Root.API.GRPC.ServiceFor(e)
+			expr.Root.API.GRPC.ServiceFor(e)
 		case *expr.MethodExpr:
-			res := expr.Root.API.GRPC.ServiceFor(actual.Service)
-			act := res.EndpointFor(actual.Name, e)
-			
+			expr.Root.API.GRPC.ServiceFor(e.Service).EndpointFor(e.Name, e)
 		default:
 			// TODO: warning
 		}
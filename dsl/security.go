@@ -0,0 +1,401 @@
+package dsl
+
+import (
+	"goa.design/goa/v3/eval"
+	"goa.design/goa/v3/expr"
+
+	goser "go.zoe.im/goser/expr"
+)
+
+// BasicAuthSecurity defines a basic authentication security scheme.
+//
+// BasicAuthSecurity is a top level DSL.
+//
+// BasicAuthSecurity takes a name as first argument and the scheme options,
+// e.g. its description.
+//
+// Example:
+//
+//    var Basic = BasicAuthSecurity(
+//        "basicauth",
+//        Description("Use your own password!"),
+//    )
+//
+func BasicAuthSecurity(name string, opts ...Option) *expr.SchemeExpr {
+	return newScheme(expr.BasicAuthKind, name, "", "", opts...)
+}
+
+// APIKeySecurity defines an API key security scheme where a key must be
+// provided by the client to perform authorization. The key is read from the
+// "Authorization" header unless specified otherwise with Header, Param or
+// Cookie.
+//
+// APIKeySecurity is a top level DSL.
+//
+// APIKeySecurity takes a name as first argument and the scheme options.
+//
+// Example:
+//
+//    var APIKey = APIKeySecurity(
+//        "key",
+//        Description("Shared secret"),
+//        Header("X-API-Key"),
+//    )
+//
+func APIKeySecurity(name string, opts ...Option) *expr.SchemeExpr {
+	return newScheme(expr.APIKeyKind, name, "header", "Authorization", opts...)
+}
+
+// JWTSecurity defines an HTTP security scheme where a JWT is passed in the
+// request Authorization header as a bearer token to perform auth. The scheme
+// may define the scopes the tokens grant with Scope.
+//
+// JWTSecurity is a top level DSL.
+//
+// JWTSecurity takes a name as first argument and the scheme options.
+//
+// Example:
+//
+//    var JWT = JWTSecurity(
+//        "jwt",
+//        Scope("system:write", "Write to the system"),
+//        Scope("system:read", "Read anything in there"),
+//    )
+//
+func JWTSecurity(name string, opts ...Option) *expr.SchemeExpr {
+	return newScheme(expr.JWTKind, name, "header", "Authorization", opts...)
+}
+
+// OAuth2Security defines an OAuth2 security scheme. The scheme must define at
+// least one flow and may define the scopes the access tokens grant.
+//
+// OAuth2Security is a top level DSL.
+//
+// OAuth2Security takes a name as first argument and the scheme options.
+//
+// Example:
+//
+//    var OAuth2 = OAuth2Security(
+//        "googauth",
+//        ImplicitFlow("/authorization", ""),
+//        Scope("api:write", "Write acess"),
+//        Scope("api:read", "Read access"),
+//    )
+//
+func OAuth2Security(name string, opts ...Option) *expr.SchemeExpr {
+	return newScheme(expr.OAuth2Kind, name, "header", "Authorization", opts...)
+}
+
+// newScheme returns the scheme with the given kind, name and default
+// location of the credentials, it reports the validation errors.
+func newScheme(kind expr.SchemeKind, name, in, key string, opts ...Option) *expr.SchemeExpr {
+	s := &expr.SchemeExpr{Kind: kind, SchemeName: name, In: in, Name: key}
+	for _, o := range opts {
+		o(s)
+	}
+	if verr := s.Validate("", nil); verr != nil && len(verr.Errors) > 0 {
+		eval.ReportError("%s", verr.Error())
+	}
+	return s
+}
+
+// Security defines the security requirements of the API, a service or a
+// method. The requirement lists the schemes which must all be satisfied and
+// the scopes the credentials must grant. Use several Security to define
+// alternative requirements, any of which being satisfied allows the request.
+// The requirements of a method override the requirements of its service which
+// override the requirements of the API.
+//
+// Security may appear in API, Service or Method.
+//
+// Security accepts the schemes and the Scope options of the requirement.
+//
+// The method payloads must define the attributes holding the credentials of
+// the schemes, see Username, Password, Token, AccessToken and APIKey.
+//
+// Example:
+//
+//    var _ = Service(
+//        "calculator",
+//        Security(OAuth2, Scope("api:read")),
+//        Method(
+//            "add",
+//            Security(Basic),
+//            Security(JWT, APIKey, Scope("system:write")),
+//        ),
+//    )
+//
+func Security(args ...interface{}) Option {
+	sec := new(expr.SecurityExpr)
+	for _, arg := range args {
+		switch a := arg.(type) {
+		case *expr.SchemeExpr:
+			sec.Schemes = append(sec.Schemes, a)
+		case Option:
+			a(sec)
+		default:
+			eval.ReportError("invalid Security argument %#v, must be a scheme or an option", arg)
+		}
+	}
+	if len(sec.Schemes) == 0 {
+		eval.ReportError("Security must list at least one scheme")
+	}
+	return security(sec)
+}
+
+// NoSecurity removes the need for an endpoint to perform authorization.
+//
+// NoSecurity must appear in Method.
+//
+// Example:
+//
+//    var _ = Service(
+//        "calculator",
+//        Security(Basic),
+//        Method(
+//            "health",
+//            NoSecurity(),
+//        ),
+//    )
+//
+func NoSecurity() Option {
+	return security(&expr.SecurityExpr{
+		Schemes: []*expr.SchemeExpr{{Kind: expr.NoKind, SchemeName: "no-security"}},
+	})
+}
+
+// security returns the Option adding the requirement to the API, services
+// and methods.
+func security(sec *expr.SecurityExpr) Option {
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.APIExpr:
+			e.Requirements = append(e.Requirements, sec)
+		case *expr.ServiceExpr:
+			e.Requirements = append(e.Requirements, sec)
+		case *expr.MethodExpr:
+			e.Requirements = append(e.Requirements, sec)
+		default:
+			// TODO: warning
+		}
+	}
+}
+
+// Scope has two uses: in JWTSecurity or OAuth2Security it defines a scope
+// supported by the scheme. In Security it lists required scopes.
+//
+// Scope must appear in Security, JWTSecurity or OAuth2Security.
+//
+// Scope accepts one or two arguments: the first argument is the scope name and
+// when used in JWTSecurity or OAuth2Security the second argument is the scope
+// description.
+//
+// Example:
+//
+//    var JWT = JWTSecurity(
+//        "jwt",
+//        Scope("api:write", "Write to the API"),
+//        Scope("api:read", "Read from the API"),
+//    )
+//
+//    var _ = Service(
+//        "calculator",
+//        Security(JWT, Scope("api:read")),
+//    )
+//
+func Scope(name string, desc ...string) Option {
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.SchemeExpr:
+			scope := &expr.ScopeExpr{Name: name}
+			if len(desc) > 0 {
+				scope.Description = desc[0]
+			}
+			e.Scopes = append(e.Scopes, scope)
+		case *expr.SecurityExpr:
+			e.Scopes = append(e.Scopes, name)
+		default:
+			// TODO: warning
+		}
+	}
+}
+
+// Header sets the name of the header holding the credentials of APIKeySecurity,
+// JWTSecurity or OAuth2Security.
+//
+// Example:
+//
+//    var APIKey = APIKeySecurity("key", Header("X-API-Key"))
+//
+func Header(name string) Option {
+	return schemeLocation("header", name)
+}
+
+// Param sets the name of the query parameter holding the credentials of
+// APIKeySecurity, JWTSecurity or OAuth2Security.
+//
+// Example:
+//
+//    var APIKey = APIKeySecurity("key", Param("api_key"))
+//
+func Param(name string) Option {
+	return schemeLocation("query", name)
+}
+
+// Cookie sets the name of the cookie holding the credentials of
+// APIKeySecurity, JWTSecurity or OAuth2Security.
+//
+// Example:
+//
+//    var APIKey = APIKeySecurity("key", Cookie("session"))
+//
+func Cookie(name string) Option {
+	return schemeLocation("cookie", name)
+}
+
+// schemeLocation returns the Option setting the location of the scheme
+// credentials.
+func schemeLocation(in, name string) Option {
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.SchemeExpr:
+			e.In = in
+			e.Name = name
+		default:
+			// TODO: warning
+		}
+	}
+}
+
+// AuthorizationCodeFlow defines an authorizationCode OAuth2 flow as described
+// in section 1.3.1 of RFC 6749.
+//
+// AuthorizationCodeFlow must be used in OAuth2Security.
+//
+// AuthorizationCodeFlow accepts three arguments: the authorization, token and
+// refresh URLs.
+func AuthorizationCodeFlow(authorizationURL, tokenURL, refreshURL string) Option {
+	return flow(&expr.FlowExpr{
+		Kind:             expr.AuthorizationCodeFlowKind,
+		AuthorizationURL: authorizationURL,
+		TokenURL:         tokenURL,
+		RefreshURL:       refreshURL,
+	})
+}
+
+// ImplicitFlow defines an implicit OAuth2 flow as described in section 1.3.2
+// of RFC 6749.
+//
+// ImplicitFlow must be used in OAuth2Security.
+//
+// ImplicitFlow accepts two arguments: the authorization and refresh URLs.
+func ImplicitFlow(authorizationURL, refreshURL string) Option {
+	return flow(&expr.FlowExpr{
+		Kind:             expr.ImplicitFlowKind,
+		AuthorizationURL: authorizationURL,
+		RefreshURL:       refreshURL,
+	})
+}
+
+// PasswordFlow defines an Resource Owner Password Credentials OAuth2 flow as
+// described in section 1.3.3 of RFC 6749.
+//
+// PasswordFlow must be used in OAuth2Security.
+//
+// PasswordFlow accepts two arguments: the token and refresh URLs.
+func PasswordFlow(tokenURL, refreshURL string) Option {
+	return flow(&expr.FlowExpr{
+		Kind:       expr.PasswordFlowKind,
+		TokenURL:   tokenURL,
+		RefreshURL: refreshURL,
+	})
+}
+
+// ClientCredentialsFlow defines an clientCredentials OAuth2 flow as described
+// in section 1.3.4 of RFC 6749.
+//
+// ClientCredentialsFlow must be used in OAuth2Security.
+//
+// ClientCredentialsFlow accepts two arguments: the token and refresh URLs.
+func ClientCredentialsFlow(tokenURL, refreshURL string) Option {
+	return flow(&expr.FlowExpr{
+		Kind:       expr.ClientCredentialsFlowKind,
+		TokenURL:   tokenURL,
+		RefreshURL: refreshURL,
+	})
+}
+
+// flow returns the Option adding the flow to OAuth2 schemes.
+func flow(f *expr.FlowExpr) Option {
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.SchemeExpr:
+			e.Flows = append(e.Flows, f)
+		default:
+			// TODO: warning
+		}
+	}
+}
+
+// Username defines the attribute used to provide the username to an endpoint
+// secured with basic authentication. The parameters and usage of Username are
+// the same as the Attribute DSL.
+//
+// Example:
+//
+//    var LoginPayload = Type(
+//        "LoginPayload",
+//        Username("user", Type(String)),
+//        Password("pass", Type(String)),
+//        Required("user", "pass"),
+//    )
+//
+func Username(name string, opts ...Option) Option {
+	return Attribute(name, append(opts, Meta(goser.SecurityUsernameMeta))...)
+}
+
+// Password defines the attribute used to provide the password to an endpoint
+// secured with basic authentication. The parameters and usage of Password are
+// the same as the Attribute DSL.
+func Password(name string, opts ...Option) Option {
+	return Attribute(name, append(opts, Meta(goser.SecurityPasswordMeta))...)
+}
+
+// Token defines the attribute used to provide the JWT to an endpoint secured
+// via JWT. The parameters and usage of Token are the same as the Attribute
+// DSL.
+//
+// Example:
+//
+//    var CreatePayload = Type(
+//        "CreatePayload",
+//        Token("token", Type(String), Description("JWT token used to perform authorization")),
+//        Required("token"),
+//    )
+//
+func Token(name string, opts ...Option) Option {
+	return Attribute(name, append(opts, Meta(goser.SecurityTokenMeta))...)
+}
+
+// AccessToken defines the attribute used to provide the access token to an
+// endpoint secured with OAuth2. The parameters and usage of AccessToken are
+// the same as the Attribute DSL.
+func AccessToken(name string, opts ...Option) Option {
+	return Attribute(name, append(opts, Meta(goser.SecurityAccessTokenMeta))...)
+}
+
+// APIKey defines the attribute used to provide the API key to an endpoint
+// secured with API keys. The parameters and usage of APIKey are the same as
+// the Attribute DSL except that the name of the API key security scheme must
+// be passed as first argument.
+//
+// Example:
+//
+//    var ReadPayload = Type(
+//        "ReadPayload",
+//        APIKey("key", "api_key", Type(String), Description("API key")),
+//    )
+//
+func APIKey(scheme, name string, opts ...Option) Option {
+	return Attribute(name, append(opts, Meta(goser.SecurityAPIKeyMeta+scheme))...)
+}
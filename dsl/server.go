@@ -0,0 +1,192 @@
+package dsl
+
+import (
+	"goa.design/goa/v3/eval"
+
+	"go.zoe.im/goser/expr"
+)
+
+// Server defines an API server. A server has a name, a description, the list
+// of services it hosts and one or more hosts defining the server URIs, e.g.
+// one host for each environment. The server hosts all the services unless
+// Services is used.
+//
+// The generated server main packages and client tools accept a --host flag to
+// select the host by name, the first host being the default, and --var flags
+// to set the URI variables of the host, e.g. --var version=v2.
+//
+// Server is a top level DSL, the validation errors of the server are
+// reported.
+//
+// Server takes the server name as first argument and the server options.
+//
+// Example:
+//
+//    var CalcServer = Server(
+//        "calcsvr",
+//        Description("calculator server"),
+//        Services("calc"),
+//        Host(
+//            "production",
+//            URI("https://{version}.goser.zoe.im/calc"),
+//            URI("grpcs://{version}.goser.zoe.im"),
+//            Variable("version", Default("v1")),
+//        ),
+//        Host(
+//            "development",
+//            URI("http://localhost:80/calc"),
+//            URI("grpc://localhost:8080"),
+//        ),
+//    )
+//
+func Server(name string, opts ...Option) *expr.ServerExpr {
+	server := &expr.ServerExpr{Name: name}
+
+	for _, o := range opts {
+		o(server)
+	}
+
+	for _, h := range server.Hosts {
+		h.ServerName = name
+	}
+
+	if verr := server.Validate("", nil); verr != nil && len(verr.Errors) > 0 {
+		eval.ReportError("%s", verr.Error())
+	}
+	return server
+}
+
+// Services sets the list of services implemented by a server. All the
+// services are hosted by the server if Services is not used.
+//
+// Services must appear in a Server expression.
+//
+// Services takes one or more services names as argument.
+//
+// Example:
+//
+//    var _ = Server(
+//        "calcsvr",
+//        Services("calc", "adder"),
+//        Host("development", URI("http://localhost:80")),
+//    )
+//
+func Services(names ...string) Option {
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.ServerExpr:
+			e.Services = append(e.Services, names...)
+		default:
+			// TODO: warning
+		}
+	}
+}
+
+// Host defines a server host. A host has a name, a description, one or more
+// URIs and the variables used in the URIs.
+//
+// Host must appear in a Server expression.
+//
+// Host takes the host name as first argument and the host options.
+//
+// Example:
+//
+//    var _ = Server(
+//        "calcsvr",
+//        Host(
+//            "development",
+//            Description("Development hosts."),
+//            URI("http://localhost:80/calc"),
+//            URI("grpc://localhost:8080"),
+//        ),
+//    )
+//
+func Host(name string, opts ...Option) Option {
+	host := &expr.HostExpr{
+		Name:      name,
+		Variables: &expr.AttributeExpr{Type: &expr.Object{}},
+	}
+
+	for _, o := range opts {
+		o(host)
+	}
+
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.ServerExpr:
+			host.ServerName = e.Name
+			e.Hosts = append(e.Hosts, host)
+		default:
+			// TODO: warning
+		}
+	}
+}
+
+// URI defines a host URI. The URI must include the scheme, one of "http",
+// "https", "grpc" or "grpcs", and the hostname and may include a port and a
+// base path. The hostname, port and base path may be parameterized using the
+// {param} notation, every parameter must be defined with Variable.
+//
+// URI must appear in a Host expression.
+//
+// URI accepts one argument: the URI.
+//
+// Example:
+//
+//    Host(
+//        "production",
+//        URI("https://{version}.goser.zoe.im/calc"),
+//        Variable("version", Default("v1")),
+//    )
+//
+func URI(uri string) Option {
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.HostExpr:
+			e.URIs = append(e.URIs, expr.URIExpr(uri))
+		default:
+			// TODO: warning
+		}
+	}
+}
+
+// Variable defines a host URI variable of type String. Variables must have a
+// default value, an enum validation or both.
+//
+// Variable must appear in a Host expression.
+//
+// Variable takes the variable name as first argument and the same options as
+// Attribute.
+//
+// Example:
+//
+//    Host(
+//        "production",
+//        URI("https://{region}.goser.zoe.im/calc"),
+//        Variable(
+//            "region",
+//            Description("Region of the host"),
+//            Default("us"),
+//        ),
+//    )
+//
+func Variable(name string, opts ...Option) Option {
+	att := &expr.AttributeExpr{Type: expr.String}
+
+	for _, o := range opts {
+		o(att)
+	}
+
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.HostExpr:
+			if e.Variables == nil {
+				e.Variables = &expr.AttributeExpr{Type: &expr.Object{}}
+			}
+			obj := expr.AsObject(e.Variables.Type)
+			obj.Set(name, att)
+		default:
+			// TODO: warning
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package dsl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	svr := Server("calcsvr",
+		Description("calculator server"),
+		Services("calc"),
+		Host("development",
+			Description("Development hosts."),
+			URI("http://localhost:80/calc"),
+			URI("grpc://localhost:8080"),
+		),
+		Host("production",
+			URI("https://{region}.calc.io"),
+			Variable("region", Description("Region of the host"), Default("us")),
+		),
+	)
+	if svr.Description != "calculator server" || len(svr.Services) != 1 || svr.Services[0] != "calc" {
+		t.Errorf("got server %#v", svr)
+	}
+	h := svr.Host("development")
+	if h == nil {
+		t.Fatal("development host not found")
+	}
+	if h.ServerName != "calcsvr" || h.Description != "Development hosts." || len(h.URIs) != 2 {
+		t.Errorf("got host %#v", h)
+	}
+	if v := svr.Host("production").Variable("region"); v == nil || v.DefaultValue != "us" {
+		t.Errorf("got region variable %#v, expected default \"us\"", v)
+	}
+	if verr := svr.Validate("", nil); len(verr.Errors) > 0 {
+		t.Errorf("unexpected validation errors: %v", verr.Errors)
+	}
+}
+
+func TestServerValidation(t *testing.T) {
+	cases := map[string]struct {
+		opts     []Option
+		expected string
+	}{
+		"no host":    {nil, "must define at least one host"},
+		"no uri":     {[]Option{Host("production")}, "must define at least one URI"},
+		"bad scheme": {[]Option{Host("production", URI("ftp://localhost"))}, "scheme must be one of"},
+		"undefined variable": {[]Option{Host("production", URI("https://{region}.calc.io"))},
+			`parameter "region" is not defined as a variable`},
+		"no default": {[]Option{Host("production", URI("https://{region}.calc.io"), Variable("region"))},
+			`variable "region" must have a default value or an enum validation`},
+	}
+	for k, tc := range cases {
+		verr := Server("calcsvr", tc.opts...).Validate("", nil)
+		if len(verr.Errors) != 1 {
+			t.Errorf("%s: got %d errors, expected 1: %v", k, len(verr.Errors), verr.Errors)
+			continue
+		}
+		if !strings.Contains(verr.Errors[0].Error(), tc.expected) {
+			t.Errorf("%s: got error %q, expected it to contain %q", k, verr.Errors[0], tc.expected)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package dsl
+
+import (
+	"goa.design/goa/v3/eval"
+
+	"go.zoe.im/goser/expr"
+)
+
+// Pick defines a type made of the given fields of an object type. The
+// descriptions, validations and meta (including the rpc:tag of Field) of the
+// fields are kept so that the generated code and docs stay consistent with the
+// source type.
+//
+// Pick is a top level DSL.
+//
+// Pick takes the name of the new type, the source type and the names of the
+// fields to keep.
+//
+// Example:
+//
+//     var UserName = Pick("UserName", User, "id", "name")
+//
+func Pick(name string, t expr.DataType, fields ...string) *expr.UserTypeExpr {
+	ut, err := expr.Pick(name, t, fields...)
+	if err != nil {
+		eval.ReportError(err.Error())
+		return nil
+	}
+	return ut
+}
+
+// Omit defines a type made of the fields of an object type except the given
+// ones. See Pick.
+//
+// Omit is a top level DSL.
+//
+// Example:
+//
+//     var CreateUserPayload = Omit("CreateUserPayload", User, "id")
+//
+func Omit(name string, t expr.DataType, fields ...string) *expr.UserTypeExpr {
+	ut, err := expr.Omit(name, t, fields...)
+	if err != nil {
+		eval.ReportError(err.Error())
+		return nil
+	}
+	return ut
+}
+
+// Partial defines a type made of the fields of an object type, none of them
+// being required. See Pick.
+//
+// Partial is a top level DSL.
+//
+// Example:
+//
+//     var UpdateUserPayload = Partial("UpdateUserPayload", User)
+//
+func Partial(name string, t expr.DataType) *expr.UserTypeExpr {
+	ut, err := expr.Partial(name, t)
+	if err != nil {
+		eval.ReportError(err.Error())
+		return nil
+	}
+	return ut
+}
+
+// RequiredAll defines a type made of the fields of an object type, all of them
+// being required. See Pick.
+//
+// RequiredAll is a top level DSL.
+//
+// Example:
+//
+//     var FullUser = RequiredAll("FullUser", User)
+//
+func RequiredAll(name string, t expr.DataType) *expr.UserTypeExpr {
+	ut, err := expr.RequiredAll(name, t)
+	if err != nil {
+		eval.ReportError(err.Error())
+		return nil
+	}
+	return ut
+}
+
+// RenameFields defines a type made of the fields of an object type with some
+// of them renamed. See Pick.
+//
+// RenameFields is a top level DSL.
+//
+// RenameFields takes the name of the new type, the source type and a map of
+// the old field names to the new ones.
+//
+// Example:
+//
+//     var Person = RenameFields("Person", User, map[string]string{"name": "full_name"})
+//
+func RenameFields(name string, t expr.DataType, names map[string]string) *expr.UserTypeExpr {
+	ut, err := expr.RenameFields(name, t, names)
+	if err != nil {
+		eval.ReportError(err.Error())
+		return nil
+	}
+	return ut
+}
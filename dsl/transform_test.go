@@ -0,0 +1,50 @@
+package dsl
+
+import (
+	"testing"
+
+	"go.zoe.im/goser/expr"
+)
+
+func TestTransform(t *testing.T) {
+	user := &expr.UserTypeExpr{TypeName: "User", AttributeExpr: &expr.AttributeExpr{
+		Type: &expr.Object{
+			{Name: "id", Attribute: &expr.AttributeExpr{Type: expr.String}},
+			{Name: "name", Attribute: &expr.AttributeExpr{Type: expr.String}},
+			{Name: "age", Attribute: &expr.AttributeExpr{Type: expr.Int}},
+		},
+		Validation: &expr.ValidationExpr{Required: []string{"id", "name"}},
+	}}
+
+	cases := map[string]struct {
+		ut       *expr.UserTypeExpr
+		fields   []string
+		required []string
+	}{
+		"pick":         {Pick("UserName", user, "id", "name"), []string{"id", "name"}, []string{"id", "name"}},
+		"omit":         {Omit("UserInfo", user, "id"), []string{"name", "age"}, []string{"name"}},
+		"partial":      {Partial("UserPatch", user), []string{"id", "name", "age"}, nil},
+		"required all": {RequiredAll("FullUser", user), []string{"id", "name", "age"}, []string{"id", "name", "age"}},
+	}
+	for k, tc := range cases {
+		if tc.ut == nil {
+			t.Errorf("%s: got nil type", k)
+			continue
+		}
+		obj := expr.AsObject(tc.ut)
+		if len(*obj) != len(tc.fields) {
+			t.Errorf("%s: got %d fields, expected %d", k, len(*obj), len(tc.fields))
+			continue
+		}
+		for i, nat := range *obj {
+			if nat.Name != tc.fields[i] {
+				t.Errorf("%s: got field %q, expected %q", k, nat.Name, tc.fields[i])
+			}
+		}
+		for _, n := range tc.required {
+			if !tc.ut.IsRequired(n) {
+				t.Errorf("%s: expected %q to be required", k, n)
+			}
+		}
+	}
+}
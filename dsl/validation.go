@@ -0,0 +1,61 @@
+package dsl
+
+import (
+	"goa.design/goa/v3/eval"
+
+	"go.zoe.im/goser/expr"
+)
+
+// Precision sets the maximum number of digits of decimal and big integer
+// values, the generated SQL columns use it as the NUMERIC precision.
+//
+// Precision must appear in an attribute of type Decimal or BigInt.
+//
+// Example:
+//
+//     Attribute("amount", Type(Decimal), Precision(12), Scale(2))
+//
+func Precision(p int) Option {
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.AttributeExpr:
+			if p <= 0 {
+				eval.ReportError("precision must be greater than 0, got %d", p)
+				return
+			}
+			if e.Validation == nil {
+				e.Validation = &expr.ValidationExpr{}
+			}
+			e.Validation.Precision = &p
+		default:
+			// TODO: warning
+		}
+	}
+}
+
+// Scale sets the maximum number of digits after the decimal point of decimal
+// values, the generated SQL columns use it as the NUMERIC scale.
+//
+// Scale must appear in an attribute of type Decimal.
+//
+// Example:
+//
+//     Attribute("amount", Type(Decimal), Precision(12), Scale(2))
+//
+func Scale(s int) Option {
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.AttributeExpr:
+			if s < 0 {
+				eval.ReportError("scale cannot be negative, got %d", s)
+				return
+			}
+			if e.Validation == nil {
+				e.Validation = &expr.ValidationExpr{}
+			}
+			e.Validation.Scale = &s
+		default:
+			// TODO: warning
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package dsl
+
+import (
+	"testing"
+
+	"go.zoe.im/goser/expr"
+)
+
+func TestPrecisionScale(t *testing.T) {
+	att := &expr.AttributeExpr{Type: expr.Decimal}
+	Precision(12)(att)
+	Scale(2)(att)
+	if att.Validation == nil || att.Validation.Precision == nil || att.Validation.Scale == nil {
+		t.Fatalf("got validation %#v, expected precision and scale", att.Validation)
+	}
+	if *att.Validation.Precision != 12 || *att.Validation.Scale != 2 {
+		t.Errorf("got precision %d and scale %d, expected 12 and 2", *att.Validation.Precision, *att.Validation.Scale)
+	}
+}
@@ -0,0 +1,79 @@
+package dsl
+
+import (
+	"goa.design/goa/v3/eval"
+	"goa.design/goa/v3/expr"
+
+	goser "go.zoe.im/goser/expr"
+)
+
+// Versions lists the API versions maintained side by side by the design. The
+// generators produce a package, a proto package (e.g. "foo.v1"), an OpenAPI
+// document and HTTP routes prefixed with the version (e.g. "/v1/users") for
+// each version. The attributes, methods and services available in a subset of
+// the versions are annotated with Since and Until.
+//
+// Versions must appear in a API expression.
+//
+// Example:
+//
+//    var _ = API(
+//        "users",
+//        Versions("v1", "v2"),
+//    )
+//
+func Versions(vers ...string) Option {
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.APIExpr:
+			if e.Meta == nil {
+				e.Meta = make(expr.MetaExpr)
+			}
+			e.Meta[goser.VersionsMeta] = append(e.Meta[goser.VersionsMeta], vers...)
+		default:
+			// TODO: warning
+		}
+	}
+}
+
+// Since sets the first API version the element is available in, see Versions.
+//
+// Since may appear in attributes, types, methods and services.
+//
+// Example:
+//
+//    var User = Type(
+//        "User",
+//        Attribute("email", String, Since("v2")),
+//    )
+//
+func Since(ver string) Option {
+	return versionMeta(goser.VersionSinceMeta, ver)
+}
+
+// Until sets the last API version the element is available in, see Versions.
+//
+// Until may appear in attributes, types, methods and services.
+//
+// Example:
+//
+//    var User = Type(
+//        "User",
+//        Attribute("login", String, Until("v1")),
+//    )
+//
+func Until(ver string) Option {
+	return versionMeta(goser.VersionUntilMeta, ver)
+}
+
+// versionMeta returns the Option setting the version meta of elements.
+func versionMeta(key, ver string) Option {
+	return func(v eval.Expression) {
+		switch e := v.(type) {
+		case *expr.AttributeExpr, *expr.MethodExpr, *expr.ServiceExpr, expr.CompositeExpr:
+			Meta(key, ver)(e)
+		default:
+			// TODO: warning
+		}
+	}
+}
@@ -2,8 +2,9 @@ package expr
 
 import (
 	"fmt"
+	"strings"
 
-	"go.zoe.io/goser/eval"
+	"go.zoe.im/goser/eval"
 )
 
 type (
@@ -83,6 +84,12 @@ type (
 		// described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor26.
 		MaxLength *int
+		// Precision is the maximum number of digits of decimal and
+		// big integer values.
+		Precision *int
+		// Scale is the maximum number of digits after the decimal
+		// point of decimal values.
+		Scale *int
 		// Required list the required fields of object attributes as
 		// described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor61.
@@ -143,8 +150,19 @@ func (a *AttributeExpr) EvalName() string {
 	return "attribute"
 }
 
-// validated keeps track of validated attributes to handle cyclical definitions.
-var validated = make(map[*AttributeExpr]bool)
+// AttributeValidator validates attributes recursively. It keeps track of the
+// validated attributes to handle cyclical definitions, so that an attribute
+// shared by multiple expressions is validated once. An AttributeValidator
+// should be scoped to a single evaluation run and is not safe for concurrent
+// use, distinct runs must use distinct validators.
+type AttributeValidator struct {
+	validated map[*AttributeExpr]bool
+}
+
+// NewAttributeValidator creates a validator for an evaluation run.
+func NewAttributeValidator() *AttributeValidator {
+	return &AttributeValidator{validated: make(map[*AttributeExpr]bool)}
+}
 
 // TaggedAttribute returns the name of the child attribute of a with the given
 // tag if a is an object.
@@ -181,11 +199,32 @@ func (a *AttributeExpr) Prepare() {
 // are unaware of their context, additional context information can be provided
 // to be used in error messages.  The parent definition context is automatically
 // added to error messages.
+//
+// Validate is the root of a validation: it creates the AttributeValidator
+// shared by the nested attributes and user types so that it can be called
+// repeatedly and concurrently, use AttributeValidator.Validate to share the
+// validation state across the expressions of an evaluation run.
 func (a *AttributeExpr) Validate(ctx string, parent eval.Expression) *eval.ValidationErrors {
-	if validated[a] {
+	return NewAttributeValidator().Validate(a, ctx, parent)
+}
+
+// ValidateUserType validates the attribute of the user type if it hasn't been
+// validated by v yet.
+func (v *AttributeValidator) ValidateUserType(ut UserType, ctx string, parent eval.Expression) *eval.ValidationErrors {
+	att := ut.Attribute()
+	if att == nil {
 		return nil
 	}
-	validated[a] = true
+	return v.Validate(att, ctx, parent)
+}
+
+// Validate validates the attribute if it hasn't been validated by v yet. See
+// AttributeExpr.Validate.
+func (v *AttributeValidator) Validate(a *AttributeExpr, ctx string, parent eval.Expression) *eval.ValidationErrors {
+	if v.validated[a] {
+		return nil
+	}
+	v.validated[a] = true
 	verr := new(eval.ValidationErrors)
 	if a.Type == nil {
 		verr.Add(parent, "attribute type is nil")
@@ -194,21 +233,28 @@ func (a *AttributeExpr) Validate(ctx string, parent eval.Expression) *eval.Valid
 	if ctx != "" {
 		ctx += " - "
 	}
-	verr.Merge(a.validateEnumDefault(ctx, parent))
-	if o := AsObject(a.Type); o != nil {
+	verr.Merge(a.validateInheritance(ctx, parent))
+	verr.Merge(a.validatePrecision(ctx, parent))
+	verr.Merge(a.validateDefaultAndExamples(ctx, parent))
+	if ut, ok := a.Type.(UserType); ok {
+		// the attribute of the user type is validated once by the
+		// validator whatever the number of attributes using the type.
+		verr.Merge(v.ValidateUserType(ut, ctx, parent))
+	} else if o := AsObject(a.Type); o != nil {
 		for _, n := range a.AllRequired() {
 			if a.Find(n) == nil {
 				verr.Add(parent, `%srequired field %q does not exist in type %s`, ctx, n, a.Type.Name())
 			}
 		}
 		for _, nat := range *o {
-			ctx = fmt.Sprintf("field %s", nat.Name)
-			verr.Merge(nat.Attribute.Validate(ctx, parent))
+			verr.Merge(v.Validate(nat.Attribute, fieldContext(ctx, nat.Name), parent))
 		}
+	} else if u := AsUnion(a.Type); u != nil {
+		verr.Merge(v.validateUnion(u, ctx, parent))
 	} else {
 		if ar := AsArray(a.Type); ar != nil {
 			elemType := ar.ElemType
-			verr.Merge(elemType.Validate(ctx, a))
+			verr.Merge(v.Validate(elemType, ctx, a))
 		}
 	}
 
@@ -219,8 +265,8 @@ func (a *AttributeExpr) Validate(ctx string, parent eval.Expression) *eval.Valid
 		}
 		if name := views[0]; name != "default" && rt != nil {
 			found := false
-			for _, v := range rt.Views {
-				if v.Name == name {
+			for _, view := range rt.Views {
+				if view.Name == name {
 					found = true
 					break
 				}
@@ -234,43 +280,189 @@ func (a *AttributeExpr) Validate(ctx string, parent eval.Expression) *eval.Valid
 	return verr
 }
 
+// validatePrecision checks that the precision and scale validations are
+// positive and apply to decimals or big integers.
+func (a *AttributeExpr) validatePrecision(ctx string, parent eval.Expression) *eval.ValidationErrors {
+	verr := new(eval.ValidationErrors)
+	v := a.Validation
+	if v == nil || (v.Precision == nil && v.Scale == nil) {
+		return verr
+	}
+	kind := a.Type.Kind()
+	if ut, ok := a.Type.(UserType); ok {
+		kind = ut.Attribute().Type.Kind()
+	}
+	switch {
+	case kind != DecimalKind && kind != BigIntKind:
+		verr.Add(parent, "%sprecision and scale can only be set on decimal or bigint attributes, got %s", ctx, a.Type.Name())
+	case v.Scale != nil && kind != DecimalKind:
+		verr.Add(parent, "%sscale can only be set on decimal attributes, got %s", ctx, a.Type.Name())
+	case v.Precision != nil && *v.Precision <= 0:
+		verr.Add(parent, "%sprecision must be greater than 0, got %d", ctx, *v.Precision)
+	case v.Scale != nil && *v.Scale < 0:
+		verr.Add(parent, "%sscale cannot be negative, got %d", ctx, *v.Scale)
+	case v.Precision != nil && v.Scale != nil && *v.Scale > *v.Precision:
+		verr.Add(parent, "%sscale %d cannot be greater than precision %d", ctx, *v.Scale, *v.Precision)
+	}
+	return verr
+}
+
+// validateUnion checks that the union alternatives have unique names and that
+// they are objects if the union has a discriminator.
+func (v *AttributeValidator) validateUnion(u *Union, ctx string, parent eval.Expression) *eval.ValidationErrors {
+	verr := new(eval.ValidationErrors)
+	if len(u.Values) == 0 {
+		verr.Add(parent, "%sunion %s must define at least one alternative", ctx, u.Name())
+	}
+	names := make(map[string]bool)
+	for _, nat := range u.Values {
+		if nat.Name == "" {
+			verr.Add(parent, "%sunion %s alternative name cannot be empty", ctx, u.Name())
+		} else if names[nat.Name] {
+			verr.Add(parent, "%sunion %s defines alternative %q more than once", ctx, u.Name(), nat.Name)
+		}
+		names[nat.Name] = true
+		if u.Discriminator != "" {
+			if !IsObject(nat.Attribute.Type) {
+				verr.Add(parent, "%sunion %s alternative %q must be an object to use discriminator %q, got %s",
+					ctx, u.Name(), nat.Name, u.Discriminator, QualifiedTypeName(nat.Attribute.Type))
+			} else if d := nat.Attribute.Find(u.Discriminator); d != nil && d.Type != String {
+				verr.Add(parent, "%sunion %s alternative %q field %q must be a string to be used as discriminator",
+					ctx, u.Name(), nat.Name, u.Discriminator)
+			}
+		}
+		verr.Merge(v.Validate(nat.Attribute, fieldContext(ctx, nat.Name), parent))
+	}
+	return verr
+}
+
 // Finalize merges base and reference type attributes and finalizes the Type
 // attribute.
 func (a *AttributeExpr) Finalize() {
 	if ut, ok := a.Type.(UserType); ok {
 		ut.Finalize()
 	}
-	if IsObject(a.Type) {
-		for _, ref := range a.References {
-			ru, ok := ref.(UserType)
-			if !ok {
-				continue
+	// errors are reported by Validate
+	a.resolveBases(make(map[*AttributeExpr]int))
+}
+
+// ResolveBases inherits from the references and merges the bases of the
+// attribute the same way Finalize does. The references and bases are resolved
+// first so that inheritance is transitive. It returns an error if the
+// attribute, a base or a reference is not an object or if the inheritance has
+// a cycle.
+func (a *AttributeExpr) ResolveBases() error {
+	return a.resolveBases(make(map[*AttributeExpr]int))
+}
+
+// resolveBases implements ResolveBases, state records the attributes being
+// resolved (1) and resolved (2) to detect cycles.
+func (a *AttributeExpr) resolveBases(state map[*AttributeExpr]int) error {
+	if state[a] == 2 || (len(a.Bases) == 0 && len(a.References) == 0) {
+		return nil
+	}
+	state[a] = 1
+	defer func() { state[a] = 2 }()
+	var errs []string
+	resolve := func(dt DataType, fn func(*AttributeExpr) error) {
+		ut, ok := dt.(UserType)
+		if !ok {
+			if err := fn(&AttributeExpr{Type: dt}); err != nil {
+				errs = append(errs, err.Error())
 			}
-			a.Inherit(ru.Attribute())
+			return
 		}
-		for _, base := range a.Bases {
-			ru, ok := base.(UserType)
-			if !ok {
-				continue
-			}
-			a.Merge(ru.Attribute())
+		if state[ut.Attribute()] == 1 {
+			errs = append(errs, fmt.Sprintf("inheritance cycle through type %s", ut.Name()))
+			return
+		}
+		if err := ut.Attribute().resolveBases(state); err != nil {
+			errs = append(errs, fmt.Sprintf("type %s: %v", ut.Name(), err))
+			return
+		}
+		if err := fn(ut.Attribute()); err != nil {
+			errs = append(errs, err.Error())
 		}
 	}
+	for _, ref := range a.References {
+		resolve(ref, a.Inherit)
+	}
+	for _, base := range a.Bases {
+		resolve(base, a.Merge)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// validateInheritance checks that the attribute and the types it extends or
+// references are objects and that the inheritance has no cycle.
+func (a *AttributeExpr) validateInheritance(ctx string, parent eval.Expression) *eval.ValidationErrors {
+	verr := new(eval.ValidationErrors)
+	if len(a.Bases) == 0 && len(a.References) == 0 {
+		return verr
+	}
+	if !IsObject(a.Type) {
+		verr.Add(parent, "%sonly object types can extend or reference other types, got %s", ctx, QualifiedTypeName(a.Type))
+		return verr
+	}
+	for _, base := range a.Bases {
+		if !IsObject(base) {
+			verr.Add(parent, "%scannot extend %s: only object types can be extended", ctx, QualifiedTypeName(base))
+		}
+	}
+	for _, ref := range a.References {
+		if !IsObject(ref) {
+			verr.Add(parent, "%scannot reference %s: only object types can be referenced", ctx, QualifiedTypeName(ref))
+		}
+	}
+	if cycle := a.inheritanceCycle(a, nil, make(map[*AttributeExpr]bool)); cycle != nil {
+		verr.Add(parent, "%sinheritance cycle %s", ctx, strings.Join(append([]string{cycle[len(cycle)-1]}, cycle...), " -> "))
+	}
+	return verr
+}
+
+// inheritanceCycle returns the names of the types leading from a back to root
+// through bases and references, nil if there is none.
+func (a *AttributeExpr) inheritanceCycle(root *AttributeExpr, path []string, visited map[*AttributeExpr]bool) []string {
+	if visited[a] {
+		return nil
+	}
+	visited[a] = true
+	for _, dt := range append(append([]DataType{}, a.Bases...), a.References...) {
+		ut, ok := dt.(UserType)
+		if !ok {
+			continue
+		}
+		p := append(append([]string{}, path...), ut.Name())
+		if ut.Attribute() == root {
+			return p
+		}
+		if cycle := ut.Attribute().inheritanceCycle(root, p, visited); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
 }
 
 // Merge merges other's attributes into a overriding attributes of a with
-// attributes of other with identical names.
+// attributes of other with identical names. The merged attributes are deep
+// copies so that a can be modified without affecting other.
 //
-// This only applies to attributes of type Object and Merge panics if the
-// argument or the target is not of type Object.
-func (a *AttributeExpr) Merge(other *AttributeExpr) {
+// This only applies to attributes of type Object and Merge returns an error if
+// the argument or the target is not of type Object.
+func (a *AttributeExpr) Merge(other *AttributeExpr) error {
 	if other == nil {
-		return
+		return nil
 	}
 	left := AsObject(a.Type)
 	right := AsObject(other.Type)
-	if left == nil || right == nil {
-		panic("cannot merge non object attributes") // bug
+	if left == nil {
+		return fmt.Errorf("cannot merge into %s: only objects can be extended", typeName(a.Type))
+	}
+	if right == nil {
+		return fmt.Errorf("cannot extend %s: only object types can be extended", typeName(other.Type))
 	}
 	if a.Type == Empty && len(*right) > 0 {
 		a.Type = &Object{}
@@ -284,16 +476,24 @@ func (a *AttributeExpr) Merge(other *AttributeExpr) {
 		}
 	}
 	for _, nat := range *right {
-		left.Set(nat.Name, nat.Attribute)
+		left.Set(nat.Name, DupAtt(nat.Attribute))
 	}
+	return nil
 }
 
 // Inherit merges the properties of existing target type attributes with the
 // argument's. The algorithm is recursive so that child attributes are also
-// merged.
-func (a *AttributeExpr) Inherit(parent *AttributeExpr) {
-	if !a.shouldInherit(parent) {
-		return
+// merged. It returns an error if the argument or the target is not of type
+// Object.
+func (a *AttributeExpr) Inherit(parent *AttributeExpr) error {
+	if parent == nil {
+		return nil
+	}
+	if !IsObject(a.Type) {
+		return fmt.Errorf("cannot inherit into %s: only objects can reference other types", typeName(a.Type))
+	}
+	if !IsObject(parent.Type) {
+		return fmt.Errorf("cannot reference %s: only object types can be referenced", typeName(parent.Type))
 	}
 	pobj := AsObject(parent.Type)
 	if a.Type == Empty && len(*pobj) > 0 {
@@ -301,6 +501,15 @@ func (a *AttributeExpr) Inherit(parent *AttributeExpr) {
 	}
 	a.inheritValidations(parent)
 	a.inheritRecursive(parent, make(map[*AttributeExpr]struct{}))
+	return nil
+}
+
+// typeName returns the qualified name of the type used in error messages.
+func typeName(dt DataType) string {
+	if dt == nil {
+		return "attribute with no type"
+	}
+	return QualifiedTypeName(dt)
 }
 
 // AllRequired returns the list of all required fields from the underlying
@@ -490,35 +699,35 @@ func (a *AttributeExpr) debug(prefix string, seen map[*AttributeExpr]int, indent
 	}
 }
 
-// validateEnumDefault makes sure that the attribute default value is one of the
-// enum values.
-func (a *AttributeExpr) validateEnumDefault(ctx string, parent eval.Expression) *eval.ValidationErrors {
-	//TODO: We only do the default value and enum check just for primitive types.
-	if _, ok := a.Type.(Primitive); !ok {
-		return nil
-	}
+// validateDefaultAndExamples makes sure that the attribute default value and
+// the user examples satisfy the attribute type and validations.
+func (a *AttributeExpr) validateDefaultAndExamples(ctx string, parent eval.Expression) *eval.ValidationErrors {
 	verr := new(eval.ValidationErrors)
-	if a.DefaultValue != nil && a.Validation != nil && a.Validation.Values != nil {
-		var found bool
-		for _, e := range a.Validation.Values {
-			if e == a.DefaultValue {
-				found = true
-				break
-			}
+	if a.DefaultValue != nil {
+		if err := Validate(a, a.DefaultValue); err != nil {
+			verr.Add(parent, "%sdefault value %#v is invalid: %s", ctx, a.DefaultValue, err)
 		}
-		if !found {
-			verr.Add(
-				parent,
-				"%sdefault value %#v is not one of the accepted values: %#v",
-				ctx,
-				a.DefaultValue,
-				a.Validation.Values,
-			)
+	}
+	for _, ex := range a.UserExamples {
+		if ex.Value == nil {
+			continue
+		}
+		if err := Validate(a, ex.Value); err != nil {
+			verr.Add(parent, "%sexample %q value %#v is invalid: %s", ctx, ex.Summary, ex.Value, err)
 		}
 	}
 	return verr
 }
 
+// fieldContext returns the context of the child attribute with the given
+// name used in error messages, e.g. "field user.address.zip".
+func fieldContext(ctx, name string) string {
+	if strings.HasPrefix(ctx, "field ") {
+		return strings.TrimSuffix(ctx, " - ") + "." + name
+	}
+	return "field " + name
+}
+
 func (a *AttributeExpr) inheritRecursive(parent *AttributeExpr, seen map[*AttributeExpr]struct{}) {
 	if !a.shouldInherit(parent) {
 		return
@@ -533,6 +742,14 @@ func (a *AttributeExpr) inheritRecursive(parent *AttributeExpr, seen map[*Attrib
 			if att.DefaultValue == nil {
 				att.DefaultValue = patt.DefaultValue
 			}
+			for k, v := range patt.Meta {
+				if _, ok := att.Meta[k]; !ok {
+					if att.Meta == nil {
+						att.Meta = MetaExpr{}
+					}
+					att.Meta[k] = v
+				}
+			}
 			if att.Type == nil {
 				att.Type = patt.Type
 			} else if att.shouldInherit(patt) {
@@ -551,14 +768,43 @@ func (a *AttributeExpr) inheritRecursive(parent *AttributeExpr, seen map[*Attrib
 	}
 }
 
+// inheritValidations sets the validations of parent which are not set on a.
 func (a *AttributeExpr) inheritValidations(parent *AttributeExpr) {
-	if parent.Validation == nil {
+	if parent == nil || parent.Validation == nil {
 		return
 	}
 	if a.Validation == nil {
 		a.Validation = &ValidationExpr{}
 	}
-	a.Validation.AddRequired(parent.Validation.Required...)
+	v, pv := a.Validation, parent.Validation
+	if v.Values == nil {
+		v.Values = pv.Values
+	}
+	if v.Format == "" {
+		v.Format = pv.Format
+	}
+	if v.Pattern == "" {
+		v.Pattern = pv.Pattern
+	}
+	if v.Minimum == nil {
+		v.Minimum = pv.Minimum
+	}
+	if v.Maximum == nil {
+		v.Maximum = pv.Maximum
+	}
+	if v.MinLength == nil {
+		v.MinLength = pv.MinLength
+	}
+	if v.MaxLength == nil {
+		v.MaxLength = pv.MaxLength
+	}
+	if v.Precision == nil {
+		v.Precision = pv.Precision
+	}
+	if v.Scale == nil {
+		v.Scale = pv.Scale
+	}
+	v.AddRequired(pv.Required...)
 }
 
 func (a *AttributeExpr) shouldInherit(parent *AttributeExpr) bool {
@@ -594,6 +840,12 @@ func (v *ValidationExpr) Merge(other *ValidationExpr) {
 	if v.MaxLength == nil || (other.MaxLength != nil && *v.MaxLength < *other.MaxLength) {
 		v.MaxLength = other.MaxLength
 	}
+	if v.Precision == nil {
+		v.Precision = other.Precision
+	}
+	if v.Scale == nil {
+		v.Scale = other.Scale
+	}
 	v.AddRequired(other.Required...)
 }
 
@@ -635,6 +887,9 @@ func (v *ValidationExpr) HasRequiredOnly() bool {
 	if (v.Minimum != nil) || (v.Maximum != nil) || (v.MinLength != nil) || (v.MaxLength != nil) {
 		return false
 	}
+	if v.Precision != nil || v.Scale != nil {
+		return false
+	}
 	return true
 }
 
@@ -653,6 +908,8 @@ func (v *ValidationExpr) Dup() *ValidationExpr {
 		Maximum:   v.Maximum,
 		MinLength: v.MinLength,
 		MaxLength: v.MaxLength,
+		Precision: v.Precision,
+		Scale:     v.Scale,
 		Required:  req,
 	}
 }
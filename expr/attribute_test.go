@@ -0,0 +1,289 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAttributeExprValidateDefaultAndExamples(t *testing.T) {
+	var (
+		two  = 2
+		zero = 0.0
+	)
+	cases := map[string]struct {
+		att      *AttributeExpr
+		expected []string
+	}{
+		"valid": {
+			att: &AttributeExpr{
+				Type:         String,
+				Validation:   &ValidationExpr{Values: []interface{}{"a", "b"}},
+				DefaultValue: "a",
+				UserExamples: []*ExampleExpr{{Summary: "default", Value: "b"}},
+			},
+		},
+		"default not in enum": {
+			att: &AttributeExpr{
+				Type:         String,
+				Validation:   &ValidationExpr{Values: []interface{}{"a", "b"}},
+				DefaultValue: "c",
+			},
+			expected: []string{`default value "c" is invalid: value "c" must be one of "a", "b"`},
+		},
+		"example too short": {
+			att: &AttributeExpr{
+				Type:         String,
+				Validation:   &ValidationExpr{MinLength: &two},
+				UserExamples: []*ExampleExpr{{Summary: "short", Value: "a"}},
+			},
+			expected: []string{`example "short" value "a" is invalid: length must be >= 2`},
+		},
+		"nested field": {
+			att: &AttributeExpr{
+				Type: &Object{
+					{"user", &AttributeExpr{
+						Type: &Object{
+							{"age", &AttributeExpr{
+								Type:         Int,
+								Validation:   &ValidationExpr{Minimum: &zero},
+								DefaultValue: -1,
+							}},
+						},
+					}},
+				},
+			},
+			expected: []string{`field user.age - default value -1 is invalid: value -1 must be >= 0`},
+		},
+		"object example": {
+			att: &AttributeExpr{
+				Type: &Object{
+					{"name", &AttributeExpr{Type: String}},
+				},
+				Validation:   &ValidationExpr{Required: []string{"name"}},
+				UserExamples: []*ExampleExpr{{Summary: "default", Value: Val{"name": 1}}},
+			},
+			expected: []string{`example "default" value expr.Val{"name":1} is invalid: name: value 1 must be string`},
+		},
+	}
+
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			verr := tc.att.Validate("", nil)
+			if len(verr.Errors) != len(tc.expected) {
+				t.Fatalf("got %d errors %v, expected %d", len(verr.Errors), verr.Errors, len(tc.expected))
+			}
+			for i, err := range verr.Errors {
+				if !strings.HasSuffix(err.Error(), tc.expected[i]) {
+					t.Errorf("got %q, expected %q", err.Error(), tc.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAttributeExprValidateReentrant(t *testing.T) {
+	node := &UserTypeExpr{TypeName: "Node", AttributeExpr: &AttributeExpr{}}
+	node.Type = &Object{
+		{"name", &AttributeExpr{Type: String, DefaultValue: 1}},
+		{"next", &AttributeExpr{Type: node}},
+	}
+	node.Validation = &ValidationExpr{Required: []string{"missing"}}
+
+	// validating repeatedly and concurrently must report the same errors
+	// and terminate with recursive types, the type is validated once.
+	done := make(chan int)
+	for i := 0; i < 10; i++ {
+		go func() {
+			done <- len(node.AttributeExpr.Validate("", nil).Errors)
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		if n := <-done; n != 2 {
+			t.Errorf("got %d errors, expected 2", n)
+		}
+	}
+
+	// a shared validator validates each attribute once.
+	v := NewAttributeValidator()
+	if n := len(v.Validate(node.AttributeExpr, "", nil).Errors); n != 2 {
+		t.Errorf("got %d errors, expected 2", n)
+	}
+	if verr := v.Validate(node.AttributeExpr, "", nil); verr != nil {
+		t.Errorf("got %v, expected attribute to be validated once", verr.Errors)
+	}
+}
+
+func TestAttributeExprValidateUserType(t *testing.T) {
+	precision := 0
+	amount := &UserTypeExpr{TypeName: "Amount", AttributeExpr: &AttributeExpr{
+		Type:       Decimal,
+		Validation: &ValidationExpr{Precision: &precision},
+	}}
+	order := &UserTypeExpr{TypeName: "Order", AttributeExpr: &AttributeExpr{Type: &Object{
+		{"total", &AttributeExpr{Type: amount}},
+		{"tax", &AttributeExpr{Type: amount}},
+		{"items", &AttributeExpr{Type: &Array{ElemType: &AttributeExpr{Type: amount}}}},
+	}}}
+
+	// the user type is validated once through the attributes using it.
+	if verr := order.Validate("", nil); len(verr.Errors) != 1 {
+		t.Errorf("got %d errors %v, expected 1", len(verr.Errors), verr.Errors)
+	}
+	v := NewAttributeValidator()
+	if verr := v.ValidateUserType(amount, "", nil); len(verr.Errors) != 1 {
+		t.Errorf("got %d errors %v, expected 1", len(verr.Errors), verr.Errors)
+	}
+	if verr := v.ValidateUserType(order, "", nil); len(verr.Errors) != 0 {
+		t.Errorf("got %v, expected the shared user type to be validated once", verr.Errors)
+	}
+}
+
+func TestAttributeExprValidateUnion(t *testing.T) {
+	dup := testUnion("")
+	dup.Values = append(dup.Values, dup.Values[0])
+	cases := map[string]struct {
+		union    *Union
+		expected int
+	}{
+		"valid":                 {testUnion("type"), 0},
+		"duplicated":            {dup, 1},
+		"empty":                 {&Union{}, 1},
+		"discriminated string":  {&Union{Discriminator: "type", Values: []*NamedAttributeExpr{{"name", &AttributeExpr{Type: String}}}}, 1},
+		"discriminator integer": {&Union{Discriminator: "type", Values: []*NamedAttributeExpr{{"obj", &AttributeExpr{Type: &Object{{"type", &AttributeExpr{Type: Int}}}}}}}, 1},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			verr := (&AttributeExpr{Type: tc.union}).Validate("", nil)
+			if actual := len(verr.Errors); actual != tc.expected {
+				t.Errorf("got %d errors, expected %d: %v", actual, tc.expected, verr.Errors)
+			}
+		})
+	}
+}
+
+func TestAttributeExprValidatePrecision(t *testing.T) {
+	var (
+		two  = 2
+		five = 5
+	)
+	cases := map[string]struct {
+		att      *AttributeExpr
+		expected int
+	}{
+		"decimal":           {&AttributeExpr{Type: Decimal, Validation: &ValidationExpr{Precision: &five, Scale: &two}}, 0},
+		"bigint":            {&AttributeExpr{Type: BigInt, Validation: &ValidationExpr{Precision: &five}}, 0},
+		"bigint scale":      {&AttributeExpr{Type: BigInt, Validation: &ValidationExpr{Scale: &two}}, 1},
+		"string":            {&AttributeExpr{Type: String, Validation: &ValidationExpr{Precision: &five}}, 1},
+		"scale > precision": {&AttributeExpr{Type: Decimal, Validation: &ValidationExpr{Precision: &two, Scale: &five}}, 1},
+	}
+	for k, tc := range cases {
+		if actual := len(tc.att.Validate("", nil).Errors); actual != tc.expected {
+			t.Errorf("%s: got %d errors, expected %d", k, actual, tc.expected)
+		}
+	}
+}
+
+func TestAttributeExprFinalizeInheritance(t *testing.T) {
+	var (
+		max  = 100
+		base = &UserTypeExpr{TypeName: "Base", AttributeExpr: &AttributeExpr{
+			Type: &Object{{"id", &AttributeExpr{Type: String}}},
+		}}
+		named = &UserTypeExpr{TypeName: "Named", AttributeExpr: &AttributeExpr{
+			Type:  &Object{{"title", &AttributeExpr{Type: String}}},
+			Bases: []DataType{base},
+		}}
+		ref = &UserTypeExpr{TypeName: "Ref", AttributeExpr: &AttributeExpr{
+			Type: &Object{{"name", &AttributeExpr{
+				Type:         String,
+				Description:  "The name",
+				DefaultValue: "zoe",
+				Validation:   &ValidationExpr{MaxLength: &max},
+				Meta:         MetaExpr{"struct:tag:json": []string{"name"}},
+			}}},
+		}}
+		att = &AttributeExpr{
+			Type:       &Object{{"name", &AttributeExpr{Type: String}}},
+			Bases:      []DataType{named},
+			References: []DataType{ref},
+		}
+	)
+	att.Finalize()
+
+	// bases are merged transitively
+	for _, n := range []string{"id", "title"} {
+		if att.Find(n) == nil {
+			t.Errorf("got no attribute %q, expected it to be merged from bases", n)
+		}
+	}
+	// same-name attributes inherit from the references
+	name := AsObject(att.Type).Attribute("name")
+	if name.Description != "The name" || name.DefaultValue != "zoe" {
+		t.Errorf("got description %q and default %#v, expected them to be inherited", name.Description, name.DefaultValue)
+	}
+	if name.Validation == nil || name.Validation.MaxLength == nil || *name.Validation.MaxLength != max {
+		t.Errorf("got validation %#v, expected max length to be inherited", name.Validation)
+	}
+	if _, ok := name.Meta["struct:tag:json"]; !ok {
+		t.Errorf("got meta %#v, expected it to be inherited", name.Meta)
+	}
+}
+
+func TestAttributeExprValidateInheritance(t *testing.T) {
+	var (
+		a = &UserTypeExpr{TypeName: "A", AttributeExpr: &AttributeExpr{Type: &Object{}}}
+		b = &UserTypeExpr{TypeName: "B", AttributeExpr: &AttributeExpr{Type: &Object{}, Bases: []DataType{a}}}
+		c = &UserTypeExpr{TypeName: "C", AttributeExpr: &AttributeExpr{Type: &Object{}}}
+	)
+	a.References = []DataType{b}
+	cases := map[string]struct {
+		att      *AttributeExpr
+		expected string
+	}{
+		"valid":         {&AttributeExpr{Type: &Object{}, Bases: []DataType{c}}, ""},
+		"cycle":         {a.AttributeExpr, "inheritance cycle A -> B -> A"},
+		"extend string": {&AttributeExpr{Type: &Object{}, Bases: []DataType{String}}, "cannot extend string: only object types can be extended"},
+		"non object":    {&AttributeExpr{Type: String, Bases: []DataType{c}}, "only object types can extend or reference other types, got string"},
+		"reference map": {&AttributeExpr{Type: &Object{}, References: []DataType{&Map{KeyType: &AttributeExpr{Type: String}, ElemType: &AttributeExpr{Type: String}}}}, "cannot reference map<string, string>: only object types can be referenced"},
+	}
+	for k, tc := range cases {
+		verr := tc.att.Validate("", nil)
+		var actual string
+		if len(verr.Errors) > 0 {
+			actual = verr.Errors[0].Error()
+		}
+		if actual != tc.expected {
+			t.Errorf("%s: got %q, expected %q", k, actual, tc.expected)
+		}
+	}
+
+	// finalizing a cycle terminates
+	a.Finalize()
+}
+
+func TestAttributeExprMergeErrors(t *testing.T) {
+	var (
+		obj = &AttributeExpr{Type: &Object{{"a", &AttributeExpr{Type: String}}}}
+		str = &AttributeExpr{Type: String}
+	)
+	cases := map[string]struct {
+		err      error
+		expected string
+	}{
+		"merge string":    {obj.Merge(str), "cannot extend string: only object types can be extended"},
+		"merge into":      {str.Merge(obj), "cannot merge into string: only objects can be extended"},
+		"inherit string":  {obj.Inherit(str), "cannot reference string: only object types can be referenced"},
+		"inherit into":    {str.Inherit(obj), "cannot inherit into string: only objects can reference other types"},
+		"merge object":    {(&AttributeExpr{Type: &Object{}}).Merge(obj), ""},
+		"resolve invalid": {(&AttributeExpr{Type: &Object{}, Bases: []DataType{Int}}).ResolveBases(), "cannot extend int: only object types can be extended"},
+	}
+	for k, tc := range cases {
+		var actual string
+		if tc.err != nil {
+			actual = tc.err.Error()
+		}
+		if actual != tc.expected {
+			t.Errorf("%s: got %q, expected %q", k, actual, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,466 @@
+package expr
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type (
+	// Change describes a difference between two versions of a design.
+	Change struct {
+		// Path locates the changed element, e.g. "User.name" or
+		// "users.get.payload".
+		Path string `json:"path"`
+		// Kind is the kind of the change.
+		Kind ChangeKind `json:"kind"`
+		// Message describes the change.
+		Message string `json:"message"`
+		// Description is the description of the added, removed or
+		// deprecated element if any.
+		Description string `json:"description,omitempty"`
+		// Breaking lists the transports the change breaks the clients
+		// of, the change is compatible if empty.
+		Breaking []string `json:"breaking,omitempty"`
+	}
+
+	// ChangeKind enumerates the kinds of changes.
+	ChangeKind string
+)
+
+const (
+	// TransportHTTP is the HTTP/JSON transport.
+	TransportHTTP = "http"
+	// TransportGRPC is the gRPC/protobuf transport.
+	TransportGRPC = "grpc"
+)
+
+const (
+	// ChangeTypeAdded is a new user type.
+	ChangeTypeAdded ChangeKind = "type_added"
+	// ChangeTypeRemoved is a removed user type.
+	ChangeTypeRemoved ChangeKind = "type_removed"
+	// ChangeTypeChanged is a change of the type of an attribute.
+	ChangeTypeChanged ChangeKind = "type_changed"
+	// ChangeFieldAdded is a new optional field.
+	ChangeFieldAdded ChangeKind = "field_added"
+	// ChangeFieldRemoved is a removed field.
+	ChangeFieldRemoved ChangeKind = "field_removed"
+	// ChangeRequiredAdded is a new required field or a field which
+	// becomes required.
+	ChangeRequiredAdded ChangeKind = "required_added"
+	// ChangeRequiredRemoved is a required field which becomes optional.
+	ChangeRequiredRemoved ChangeKind = "required_removed"
+	// ChangeEnumNarrowed is an enum which accepts fewer values.
+	ChangeEnumNarrowed ChangeKind = "enum_narrowed"
+	// ChangeEnumWidened is an enum which accepts more values.
+	ChangeEnumWidened ChangeKind = "enum_widened"
+	// ChangeValidationTightened is a validation which accepts fewer
+	// values.
+	ChangeValidationTightened ChangeKind = "validation_tightened"
+	// ChangeValidationLoosened is a validation which accepts more values.
+	ChangeValidationLoosened ChangeKind = "validation_loosened"
+	// ChangeDeprecated is an element which becomes deprecated.
+	ChangeDeprecated ChangeKind = "deprecated"
+	// ChangeTagChanged is a change of the "rpc:tag" of a field or of the
+	// tag of an enum value.
+	ChangeTagChanged ChangeKind = "tag_changed"
+	// ChangeServiceAdded is a new service.
+	ChangeServiceAdded ChangeKind = "service_added"
+	// ChangeServiceRemoved is a removed service.
+	ChangeServiceRemoved ChangeKind = "service_removed"
+	// ChangeMethodAdded is a new method.
+	ChangeMethodAdded ChangeKind = "method_added"
+	// ChangeMethodRemoved is a removed method.
+	ChangeMethodRemoved ChangeKind = "method_removed"
+	// ChangeRouteChanged is a change of the HTTP route of a method.
+	ChangeRouteChanged ChangeKind = "route_changed"
+)
+
+// AllTransports lists the transports changes are classified for.
+var AllTransports = []string{TransportHTTP, TransportGRPC}
+
+// NewChange creates a change breaking the given transports, the message is
+// built from format and args.
+func NewChange(path string, kind ChangeKind, breaking []string, format string, args ...interface{}) *Change {
+	return &Change{
+		Path:     path,
+		Kind:     kind,
+		Message:  fmt.Sprintf(format, args...),
+		Breaking: breaking,
+	}
+}
+
+// Describe sets the description of the change and returns it.
+func (c *Change) Describe(desc string) *Change {
+	c.Description = desc
+	return c
+}
+
+// IsBreaking returns true if the change breaks at least one transport.
+func (c *Change) IsBreaking() bool { return len(c.Breaking) > 0 }
+
+// Breaks returns true if the change breaks the given transport.
+func (c *Change) Breaks(transport string) bool {
+	for _, t := range c.Breaking {
+		if t == transport {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the path and message of the change and the transports it
+// breaks.
+func (c *Change) String() string {
+	if !c.IsBreaking() {
+		return fmt.Sprintf("%s: %s", c.Path, c.Message)
+	}
+	return fmt.Sprintf("%s: %s (breaking %s)", c.Path, c.Message, strings.Join(c.Breaking, ", "))
+}
+
+// BreakingChanges returns the changes which break the given transport, all
+// the breaking changes if transport is empty.
+func BreakingChanges(changes []*Change, transport string) []*Change {
+	var res []*Change
+	for _, c := range changes {
+		if transport == "" && c.IsBreaking() || transport != "" && c.Breaks(transport) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// CompareUserTypes compares the user types of two versions of a design by
+// name, old being the previous version. The attributes of the types with the
+// same name are compared with CompareAttributes.
+func CompareUserTypes(old, new []UserType) []*Change {
+	olds := make(map[string]UserType, len(old))
+	for _, ut := range old {
+		olds[ut.Name()] = ut
+	}
+	news := make(map[string]UserType, len(new))
+	for _, ut := range new {
+		news[ut.Name()] = ut
+	}
+	var changes []*Change
+	for _, name := range sortedTypeNames(old) {
+		ut, ok := news[name]
+		if !ok {
+			changes = append(changes, NewChange(name, ChangeTypeRemoved, AllTransports, "type %s removed", name).
+				Describe(olds[name].Attribute().Description))
+			continue
+		}
+		changes = append(changes, CompareAttributes(name, olds[name].Attribute(), ut.Attribute())...)
+		if c := CompareDeprecation(name, "type "+name, olds[name].Attribute().Meta, ut.Attribute().Meta); c != nil {
+			changes = append(changes, c)
+		}
+		if oe, ok := olds[name].(*EnumTypeExpr); ok {
+			if ne, ok := ut.(*EnumTypeExpr); ok {
+				changes = append(changes, compareEnumValues(name, oe, ne)...)
+			}
+		}
+	}
+	for _, name := range sortedTypeNames(new) {
+		if _, ok := olds[name]; !ok {
+			changes = append(changes, NewChange(name, ChangeTypeAdded, nil, "type %s added", name).
+				Describe(news[name].Attribute().Description))
+		}
+	}
+	return changes
+}
+
+// CompareAttributes compares two versions of an attribute, old being the
+// previous version. Fields of user types are not compared as user types are
+// compared by CompareUserTypes, a user type is changed only if its name
+// changes.
+func CompareAttributes(path string, old, new *AttributeExpr) []*Change {
+	if old == nil && new == nil {
+		return nil
+	}
+	if old == nil || new == nil || old.Type == nil || new.Type == nil {
+		return []*Change{changeType(path, old, new)}
+	}
+	changes := compareTypes(path, old, new)
+	if len(changes) > 0 && changes[0].Kind == ChangeTypeChanged && changes[0].Path == path {
+		// validations of different types cannot be compared
+		return changes
+	}
+	return append(changes, compareValidations(path, old.Validation, new.Validation)...)
+}
+
+// compareTypes compares the types of two versions of an attribute.
+func compareTypes(path string, old, new *AttributeExpr) []*Change {
+	ot, nt := old.Type, new.Type
+	if ou, ok := ot.(UserType); ok {
+		if nu, ok := nt.(UserType); ok && ou.Name() == nu.Name() {
+			return nil
+		}
+		return []*Change{changeType(path, old, new)}
+	}
+	if ot.Kind() != nt.Kind() {
+		return []*Change{changeType(path, old, new)}
+	}
+	switch o := ot.(type) {
+	case Primitive:
+		if o.Name() != nt.Name() {
+			return []*Change{changeType(path, old, new)}
+		}
+	case *Array:
+		return CompareAttributes(path+"[]", o.ElemType, nt.(*Array).ElemType)
+	case *Map:
+		n := nt.(*Map)
+		changes := CompareAttributes(path+"{key}", o.KeyType, n.KeyType)
+		return append(changes, CompareAttributes(path+"{}", o.ElemType, n.ElemType)...)
+	case *Object:
+		return compareObjects(path, old, new)
+	case *Union:
+		return compareUnions(path, o, nt.(*Union))
+	}
+	return nil
+}
+
+// compareObjects compares the fields of two versions of an object attribute.
+func compareObjects(path string, old, new *AttributeExpr) []*Change {
+	var (
+		changes []*Change
+		oobj    = AsObject(old.Type)
+		nobj    = AsObject(new.Type)
+	)
+	for _, nat := range *oobj {
+		fpath := path + "." + nat.Name
+		natt := nobj.Attribute(nat.Name)
+		if natt == nil {
+			changes = append(changes, NewChange(fpath, ChangeFieldRemoved, AllTransports, "field %s removed", nat.Name).
+				Describe(nat.Attribute.Description))
+			continue
+		}
+		changes = append(changes, CompareAttributes(fpath, nat.Attribute, natt)...)
+		if c := CompareDeprecation(fpath, "field "+nat.Name, nat.Attribute.Meta, natt.Meta); c != nil {
+			changes = append(changes, c)
+		}
+		if ot, nt := rpcTag(nat.Attribute), rpcTag(natt); ot != "" && nt != "" && ot != nt {
+			changes = append(changes, NewChange(fpath, ChangeTagChanged, []string{TransportGRPC},
+				"rpc:tag of field %s changed from %s to %s", nat.Name, ot, nt))
+		}
+		switch oreq, nreq := old.IsRequired(nat.Name), new.IsRequired(nat.Name); {
+		case !oreq && nreq:
+			changes = append(changes, NewChange(fpath, ChangeRequiredAdded, AllTransports, "field %s is now required", nat.Name))
+		case oreq && !nreq:
+			changes = append(changes, NewChange(fpath, ChangeRequiredRemoved, nil, "field %s is now optional", nat.Name))
+		}
+	}
+	for _, nat := range *nobj {
+		if oobj.Attribute(nat.Name) != nil {
+			continue
+		}
+		fpath := path + "." + nat.Name
+		if new.IsRequired(nat.Name) {
+			changes = append(changes, NewChange(fpath, ChangeRequiredAdded, AllTransports, "required field %s added", nat.Name).
+				Describe(nat.Attribute.Description))
+		} else {
+			changes = append(changes, NewChange(fpath, ChangeFieldAdded, nil, "field %s added", nat.Name).
+				Describe(nat.Attribute.Description))
+		}
+	}
+	return changes
+}
+
+// compareUnions compares the alternatives of two versions of a union.
+func compareUnions(path string, old, new *Union) []*Change {
+	var changes []*Change
+	if old.Discriminator != new.Discriminator {
+		changes = append(changes, NewChange(path, ChangeTypeChanged, []string{TransportHTTP},
+			"discriminator changed from %q to %q", old.Discriminator, new.Discriminator))
+	}
+	for _, nat := range old.Values {
+		apath := path + "|" + nat.Name
+		natt := new.Alternative(nat.Name)
+		if natt == nil {
+			changes = append(changes, NewChange(apath, ChangeTypeChanged, AllTransports, "alternative %s removed", nat.Name))
+			continue
+		}
+		changes = append(changes, CompareAttributes(apath, nat.Attribute, natt)...)
+		if ot, nt := rpcTag(nat.Attribute), rpcTag(natt); ot != "" && nt != "" && ot != nt {
+			changes = append(changes, NewChange(apath, ChangeTagChanged, []string{TransportGRPC},
+				"rpc:tag of alternative %s changed from %s to %s", nat.Name, ot, nt))
+		}
+	}
+	for _, nat := range new.Values {
+		if old.Alternative(nat.Name) == nil {
+			changes = append(changes, NewChange(path+"|"+nat.Name, ChangeTypeChanged, nil, "alternative %s added", nat.Name))
+		}
+	}
+	return changes
+}
+
+// compareValidations compares two versions of the validations of an
+// attribute, the required fields are compared with the fields of objects.
+func compareValidations(path string, old, new *ValidationExpr) []*Change {
+	if old == nil {
+		old = &ValidationExpr{}
+	}
+	if new == nil {
+		new = &ValidationExpr{}
+	}
+	var changes []*Change
+
+	if len(old.Values) > 0 || len(new.Values) > 0 {
+		var removed, added []string
+		for _, v := range old.Values {
+			if len(new.Values) > 0 && !containsValue(new.Values, v) {
+				removed = append(removed, fmt.Sprintf("%v", v))
+			}
+		}
+		for _, v := range new.Values {
+			if len(old.Values) > 0 && !containsValue(old.Values, v) {
+				added = append(added, fmt.Sprintf("%v", v))
+			}
+		}
+		switch {
+		case len(old.Values) == 0:
+			changes = append(changes, NewChange(path, ChangeEnumNarrowed, AllTransports, "enum validation added"))
+		case len(new.Values) == 0:
+			changes = append(changes, NewChange(path, ChangeEnumWidened, nil, "enum validation removed"))
+		}
+		if len(removed) > 0 {
+			changes = append(changes, NewChange(path, ChangeEnumNarrowed, AllTransports,
+				"enum values %s removed", strings.Join(removed, ", ")))
+		}
+		if len(added) > 0 {
+			changes = append(changes, NewChange(path, ChangeEnumWidened, nil,
+				"enum values %s added", strings.Join(added, ", ")))
+		}
+	}
+	if old.Format != new.Format {
+		changes = append(changes, compareString(path, "format", string(old.Format), string(new.Format)))
+	}
+	if old.Pattern != new.Pattern {
+		changes = append(changes, compareString(path, "pattern", old.Pattern, new.Pattern))
+	}
+	changes = appendBound(changes, path, "minimum", old.Minimum, new.Minimum, true)
+	changes = appendBound(changes, path, "maximum", old.Maximum, new.Maximum, false)
+	changes = appendBound(changes, path, "min length", intBound(old.MinLength), intBound(new.MinLength), true)
+	changes = appendBound(changes, path, "max length", intBound(old.MaxLength), intBound(new.MaxLength), false)
+	changes = appendBound(changes, path, "precision", intBound(old.Precision), intBound(new.Precision), false)
+	changes = appendBound(changes, path, "scale", intBound(old.Scale), intBound(new.Scale), false)
+	return changes
+}
+
+// compareString compares two different values of a string validation, any
+// new value is considered tightening the validation.
+func compareString(path, name, old, new string) *Change {
+	switch {
+	case old == "":
+		return NewChange(path, ChangeValidationTightened, AllTransports, "%s %q added", name, new)
+	case new == "":
+		return NewChange(path, ChangeValidationLoosened, nil, "%s %q removed", name, old)
+	}
+	return NewChange(path, ChangeValidationTightened, AllTransports, "%s changed from %q to %q", name, old, new)
+}
+
+// appendBound compares two values of a bound validation and appends the
+// change if any, lower is true for lower bounds such as minimums.
+func appendBound(changes []*Change, path, name string, old, new *float64, lower bool) []*Change {
+	switch {
+	case old == nil && new == nil:
+		return changes
+	case old == nil:
+		return append(changes, NewChange(path, ChangeValidationTightened, AllTransports, "%s %v added", name, *new))
+	case new == nil:
+		return append(changes, NewChange(path, ChangeValidationLoosened, nil, "%s %v removed", name, *old))
+	case *old == *new:
+		return changes
+	}
+	if (*new > *old) == lower {
+		return append(changes, NewChange(path, ChangeValidationTightened, AllTransports,
+			"%s changed from %v to %v", name, *old, *new))
+	}
+	return append(changes, NewChange(path, ChangeValidationLoosened, nil,
+		"%s changed from %v to %v", name, *old, *new))
+}
+
+// compareEnumValues compares the tags and deprecations of the values of two
+// versions of a named enum, the tags are the protobuf enum numbers.
+func compareEnumValues(path string, old, new *EnumTypeExpr) []*Change {
+	var changes []*Change
+	for _, ov := range old.Values {
+		for _, nv := range new.Values {
+			if !reflect.DeepEqual(ov.Value, nv.Value) {
+				continue
+			}
+			if ov.Tag != nv.Tag {
+				changes = append(changes, NewChange(path, ChangeTagChanged, []string{TransportGRPC},
+					"tag of value %v changed from %d to %d", ov.Value, ov.Tag, nv.Tag))
+			}
+			if c := CompareDeprecation(path, fmt.Sprintf("value %v", ov.Value), ov.Meta, nv.Meta); c != nil {
+				changes = append(changes, c)
+			}
+		}
+	}
+	return changes
+}
+
+// CompareDeprecation returns a change if the element with the given meta
+// becomes deprecated, nil otherwise. what names the element in the message,
+// e.g. "method get", the description of the change is the deprecation reason.
+func CompareDeprecation(path, what string, old, new MetaExpr) *Change {
+	if Deprecation(old) != nil {
+		return nil
+	}
+	d := Deprecation(new)
+	if d == nil {
+		return nil
+	}
+	return NewChange(path, ChangeDeprecated, nil, "%s deprecated", what).Describe(d.String())
+}
+
+// changeType returns a breaking change of the type of an attribute.
+func changeType(path string, old, new *AttributeExpr) *Change {
+	var ot, nt DataType
+	if old != nil {
+		ot = old.Type
+	}
+	if new != nil {
+		nt = new.Type
+	}
+	return NewChange(path, ChangeTypeChanged, AllTransports, "type changed from %s to %s", typeName(ot), typeName(nt))
+}
+
+// rpcTag returns the "rpc:tag" meta of the attribute, empty if not set.
+func rpcTag(att *AttributeExpr) string {
+	if tag, ok := att.Meta["rpc:tag"]; ok && len(tag) > 0 {
+		return tag[0]
+	}
+	return ""
+}
+
+// containsValue returns true if values contains v.
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, val := range values {
+		if reflect.DeepEqual(val, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// intBound converts an optional integer to an optional float.
+func intBound(i *int) *float64 {
+	if i == nil {
+		return nil
+	}
+	f := float64(*i)
+	return &f
+}
+
+// sortedTypeNames returns the names of the user types sorted.
+func sortedTypeNames(uts []UserType) []string {
+	names := make([]string, len(uts))
+	for i, ut := range uts {
+		names[i] = ut.Name()
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,168 @@
+package expr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareAttributes(t *testing.T) {
+	var (
+		one, two = 1.0, 2.0
+		five     = 5
+
+		object = func(required []string, fields ...*NamedAttributeExpr) *AttributeExpr {
+			obj := Object(fields)
+			att := &AttributeExpr{Type: &obj}
+			if required != nil {
+				att.Validation = &ValidationExpr{Required: required}
+			}
+			return att
+		}
+		field = func(name string, t DataType, tag string) *NamedAttributeExpr {
+			att := &AttributeExpr{Type: t}
+			if tag != "" {
+				att.Meta = MetaExpr{"rpc:tag": []string{tag}}
+			}
+			return &NamedAttributeExpr{Name: name, Attribute: att}
+		}
+		user = &UserTypeExpr{TypeName: "User", AttributeExpr: object(nil)}
+		team = &UserTypeExpr{TypeName: "Team", AttributeExpr: object(nil)}
+	)
+	cases := map[string]struct {
+		old, new *AttributeExpr
+		expected []ChangeKind
+		breaking []bool
+	}{
+		"same": {
+			old: object([]string{"id"}, field("id", String, "1")),
+			new: object([]string{"id"}, field("id", String, "1")),
+		},
+		"field removed": {
+			old:      object(nil, field("id", String, ""), field("name", String, "")),
+			new:      object(nil, field("id", String, "")),
+			expected: []ChangeKind{ChangeFieldRemoved},
+			breaking: []bool{true},
+		},
+		"optional field added": {
+			old:      object(nil, field("id", String, "")),
+			new:      object(nil, field("id", String, ""), field("name", String, "")),
+			expected: []ChangeKind{ChangeFieldAdded},
+			breaking: []bool{false},
+		},
+		"required field added": {
+			old:      object(nil, field("id", String, "")),
+			new:      object([]string{"name"}, field("id", String, ""), field("name", String, "")),
+			expected: []ChangeKind{ChangeRequiredAdded},
+			breaking: []bool{true},
+		},
+		"field made optional": {
+			old:      object([]string{"id"}, field("id", String, "")),
+			new:      object(nil, field("id", String, "")),
+			expected: []ChangeKind{ChangeRequiredRemoved},
+			breaking: []bool{false},
+		},
+		"field type changed": {
+			old:      object(nil, field("id", String, "")),
+			new:      object(nil, field("id", Int64, "")),
+			expected: []ChangeKind{ChangeTypeChanged},
+			breaking: []bool{true},
+		},
+		"user type changed": {
+			old:      object(nil, field("owner", user, "")),
+			new:      object(nil, field("owner", team, "")),
+			expected: []ChangeKind{ChangeTypeChanged},
+			breaking: []bool{true},
+		},
+		"tag changed": {
+			old:      object(nil, field("id", String, "1")),
+			new:      object(nil, field("id", String, "2")),
+			expected: []ChangeKind{ChangeTagChanged},
+			breaking: []bool{true},
+		},
+		"array element changed": {
+			old:      &AttributeExpr{Type: &Array{ElemType: &AttributeExpr{Type: String}}},
+			new:      &AttributeExpr{Type: &Array{ElemType: &AttributeExpr{Type: Int}}},
+			expected: []ChangeKind{ChangeTypeChanged},
+			breaking: []bool{true},
+		},
+		"enum narrowed": {
+			old:      &AttributeExpr{Type: String, Validation: &ValidationExpr{Values: []interface{}{"a", "b"}}},
+			new:      &AttributeExpr{Type: String, Validation: &ValidationExpr{Values: []interface{}{"a", "c"}}},
+			expected: []ChangeKind{ChangeEnumNarrowed, ChangeEnumWidened},
+			breaking: []bool{true, false},
+		},
+		"minimum tightened": {
+			old:      &AttributeExpr{Type: Int, Validation: &ValidationExpr{Minimum: &one}},
+			new:      &AttributeExpr{Type: Int, Validation: &ValidationExpr{Minimum: &two}},
+			expected: []ChangeKind{ChangeValidationTightened},
+			breaking: []bool{true},
+		},
+		"maximum loosened": {
+			old:      &AttributeExpr{Type: Int, Validation: &ValidationExpr{Maximum: &one}},
+			new:      &AttributeExpr{Type: Int, Validation: &ValidationExpr{Maximum: &two}},
+			expected: []ChangeKind{ChangeValidationLoosened},
+			breaking: []bool{false},
+		},
+		"max length added": {
+			old:      &AttributeExpr{Type: String},
+			new:      &AttributeExpr{Type: String, Validation: &ValidationExpr{MaxLength: &five}},
+			expected: []ChangeKind{ChangeValidationTightened},
+			breaking: []bool{true},
+		},
+		"pattern removed": {
+			old:      &AttributeExpr{Type: String, Validation: &ValidationExpr{Pattern: "^a"}},
+			new:      &AttributeExpr{Type: String},
+			expected: []ChangeKind{ChangeValidationLoosened},
+			breaking: []bool{false},
+		},
+	}
+	for k, tc := range cases {
+		changes := CompareAttributes("T", tc.old, tc.new)
+		var kinds []ChangeKind
+		var breaking []bool
+		for _, c := range changes {
+			kinds = append(kinds, c.Kind)
+			breaking = append(breaking, c.IsBreaking())
+		}
+		if !reflect.DeepEqual(kinds, tc.expected) {
+			t.Errorf("%s: got %v, expected %v", k, changes, tc.expected)
+			continue
+		}
+		if !reflect.DeepEqual(breaking, tc.breaking) {
+			t.Errorf("%s: got breaking %v, expected %v", k, breaking, tc.breaking)
+		}
+	}
+}
+
+func TestCompareUserTypes(t *testing.T) {
+	var (
+		oldStatus = NewEnumTypeExpr("Status", String,
+			&EnumValueExpr{Value: "active"},
+			&EnumValueExpr{Value: "inactive"})
+		newStatus = NewEnumTypeExpr("Status", String,
+			&EnumValueExpr{Value: "inactive"},
+			&EnumValueExpr{Value: "active"})
+		user = &UserTypeExpr{TypeName: "User", AttributeExpr: &AttributeExpr{Type: &Object{}}}
+		team = &UserTypeExpr{TypeName: "Team", AttributeExpr: &AttributeExpr{Type: &Object{}}}
+	)
+	changes := CompareUserTypes([]UserType{oldStatus, user}, []UserType{newStatus, team})
+	expected := []string{
+		"Status: tag of value active changed from 1 to 2 (breaking grpc)",
+		"Status: tag of value inactive changed from 2 to 1 (breaking grpc)",
+		"User: type User removed (breaking http, grpc)",
+		"Team: type Team added",
+	}
+	var actual []string
+	for _, c := range changes {
+		actual = append(actual, c.String())
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got %#v, expected %#v", actual, expected)
+	}
+	if n := len(BreakingChanges(changes, TransportHTTP)); n != 1 {
+		t.Errorf("got %d changes breaking http, expected 1", n)
+	}
+	if n := len(BreakingChanges(changes, "")); n != 3 {
+		t.Errorf("got %d breaking changes, expected 3", n)
+	}
+}
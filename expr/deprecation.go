@@ -0,0 +1,66 @@
+package expr
+
+import (
+	"fmt"
+)
+
+const (
+	// DeprecatedMeta is the meta key of the reason of deprecated design
+	// elements.
+	DeprecatedMeta = "deprecated"
+	// DeprecatedSinceMeta is the meta key of the version since which design
+	// elements are deprecated.
+	DeprecatedSinceMeta = "deprecated:since"
+)
+
+// DeprecationExpr describes why and since when a design element is
+// deprecated.
+type DeprecationExpr struct {
+	// Reason of the deprecation, e.g. the element to use instead.
+	Reason string
+	// Since is the version since which the element is deprecated.
+	Since string
+}
+
+// Deprecate marks the element with the given meta as deprecated and returns
+// the meta, which is created if nil.
+func Deprecate(meta MetaExpr, reason, since string) MetaExpr {
+	if meta == nil {
+		meta = make(MetaExpr)
+	}
+	meta[DeprecatedMeta] = []string{reason}
+	if since != "" {
+		meta[DeprecatedSinceMeta] = []string{since}
+	}
+	return meta
+}
+
+// Deprecation returns the deprecation of the element with the given meta, nil
+// if the element is not deprecated.
+func Deprecation(meta MetaExpr) *DeprecationExpr {
+	reason, ok := meta[DeprecatedMeta]
+	if !ok {
+		return nil
+	}
+	d := &DeprecationExpr{}
+	if len(reason) > 0 {
+		d.Reason = reason[0]
+	}
+	if since := meta[DeprecatedSinceMeta]; len(since) > 0 {
+		d.Since = since[0]
+	}
+	return d
+}
+
+// String returns the reason and the version of the deprecation.
+func (d *DeprecationExpr) String() string {
+	switch {
+	case d.Reason == "" && d.Since == "":
+		return "deprecated"
+	case d.Since == "":
+		return d.Reason
+	case d.Reason == "":
+		return fmt.Sprintf("deprecated since %s", d.Since)
+	}
+	return fmt.Sprintf("%s (since %s)", d.Reason, d.Since)
+}
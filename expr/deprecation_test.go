@@ -0,0 +1,27 @@
+package expr
+
+import (
+	"testing"
+)
+
+func TestDeprecation(t *testing.T) {
+	cases := map[string]struct {
+		meta     MetaExpr
+		expected string
+	}{
+		"not deprecated": {MetaExpr{"rpc:tag": {"1"}}, ""},
+		"empty":          {Deprecate(nil, "", ""), "deprecated"},
+		"reason":         {Deprecate(nil, "use email", ""), "use email"},
+		"since":          {Deprecate(nil, "", "v2"), "deprecated since v2"},
+		"reason since":   {Deprecate(MetaExpr{"rpc:tag": {"1"}}, "use email", "v2"), "use email (since v2)"},
+	}
+	for k, tc := range cases {
+		var actual string
+		if d := Deprecation(tc.meta); d != nil {
+			actual = d.String()
+		}
+		if actual != tc.expected {
+			t.Errorf("%s: got %q, expected %q", k, actual, tc.expected)
+		}
+	}
+}
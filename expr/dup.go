@@ -0,0 +1,116 @@
+package expr
+
+// Dup creates a deep copy of the given data type. User types are copied once
+// so that recursive types produce recursive copies.
+func Dup(d DataType) DataType {
+	return newDupper().DupType(d)
+}
+
+// DupAtt creates a deep copy of the given attribute. The validations, meta,
+// bases, references and examples are copied so that the copy can be modified
+// without affecting the original.
+func DupAtt(att *AttributeExpr) *AttributeExpr {
+	return newDupper().DupAttribute(att)
+}
+
+// dupper implements recursive and cycle safe copies of data types and
+// attributes.
+type dupper struct {
+	uts  map[UserType]UserType
+	atts map[*AttributeExpr]*AttributeExpr
+}
+
+// newDupper returns a new initialized dupper.
+func newDupper() *dupper {
+	return &dupper{
+		uts:  make(map[UserType]UserType),
+		atts: make(map[*AttributeExpr]*AttributeExpr),
+	}
+}
+
+// DupAttribute creates a copy of the attribute, attributes which have already
+// been copied by d are returned as is.
+func (d *dupper) DupAttribute(att *AttributeExpr) *AttributeExpr {
+	if att == nil {
+		return nil
+	}
+	if dup, ok := d.atts[att]; ok {
+		return dup
+	}
+	dup := &AttributeExpr{
+		DSLFunc:      att.DSLFunc,
+		Description:  att.Description,
+		Docs:         att.Docs,
+		DefaultValue: att.DefaultValue,
+		ZeroValue:    att.ZeroValue,
+	}
+	d.atts[att] = dup
+	if att.Validation != nil {
+		dup.Validation = att.Validation.Dup()
+	}
+	if att.Meta != nil {
+		dup.Meta = make(MetaExpr, len(att.Meta))
+		for k, v := range att.Meta {
+			dup.Meta[k] = append([]string(nil), v...)
+		}
+	}
+	if len(att.UserExamples) > 0 {
+		dup.UserExamples = make([]*ExampleExpr, len(att.UserExamples))
+		for i, ex := range att.UserExamples {
+			cp := *ex
+			dup.UserExamples[i] = &cp
+		}
+	}
+	dup.Bases = append([]DataType(nil), att.Bases...)
+	dup.References = append([]DataType(nil), att.References...)
+	if att.Type != nil {
+		dup.Type = d.DupType(att.Type)
+	}
+	return dup
+}
+
+// DupType creates a copy of the data type. Primitives are returned as is.
+func (d *dupper) DupType(t DataType) DataType {
+	switch actual := t.(type) {
+	case Primitive:
+		return t
+	case *Array:
+		return &Array{ElemType: d.DupAttribute(actual.ElemType)}
+	case *Map:
+		return &Map{
+			KeyType:  d.DupAttribute(actual.KeyType),
+			ElemType: d.DupAttribute(actual.ElemType),
+		}
+	case *Object:
+		res := make(Object, len(*actual))
+		for i, nat := range *actual {
+			res[i] = &NamedAttributeExpr{Name: nat.Name, Attribute: d.DupAttribute(nat.Attribute)}
+		}
+		return &res
+	case *Union:
+		res := &Union{
+			TypeName:      actual.TypeName,
+			Discriminator: actual.Discriminator,
+			Values:        make([]*NamedAttributeExpr, len(actual.Values)),
+		}
+		for i, nat := range actual.Values {
+			res.Values[i] = &NamedAttributeExpr{Name: nat.Name, Attribute: d.DupAttribute(nat.Attribute)}
+		}
+		return res
+	case UserType:
+		if actual == Empty {
+			// Don't dup Empty so that code may check against it.
+			return actual
+		}
+		if u, ok := d.uts[actual]; ok {
+			return u
+		}
+		// register the copy before copying the attribute so that
+		// recursive references resolve to it.
+		dup := actual.Dup(nil)
+		d.uts[actual] = dup
+		dup.SetAttribute(d.DupAttribute(actual.Attribute()))
+		return dup
+	}
+	return t
+}
@@ -0,0 +1,64 @@
+package expr
+
+import "testing"
+
+func TestDupAtt(t *testing.T) {
+	var (
+		min  = 1.0
+		node = &UserTypeExpr{TypeName: "Node", AttributeExpr: &AttributeExpr{}}
+		att  = &AttributeExpr{
+			Type:       node,
+			Validation: &ValidationExpr{Minimum: &min, Required: []string{"name"}},
+			Meta:       MetaExpr{"rpc:tag": []string{"1"}},
+		}
+	)
+	node.Type = &Object{
+		{"name", &AttributeExpr{Type: String}},
+		{"next", &AttributeExpr{Type: node}},
+		{"children", &AttributeExpr{Type: &Array{ElemType: &AttributeExpr{Type: node}}}},
+	}
+
+	dup := DupAtt(att)
+	if !Equal(dup.Type, att.Type) {
+		t.Errorf("got %s, expected equal types", QualifiedTypeName(dup.Type))
+	}
+	dnode := dup.Type.(*UserTypeExpr)
+	if dnode == node {
+		t.Fatalf("got same user type, expected a copy")
+	}
+	if next := dnode.Find("next"); next.Type != dnode {
+		t.Errorf("got %p, expected recursive reference to the copy %p", next.Type, dnode)
+	}
+	if elem := AsArray(dnode.Find("children").Type).ElemType; elem.Type != dnode {
+		t.Errorf("got %p, expected array element to refer to the copy %p", elem.Type, dnode)
+	}
+
+	// modifying the copy doesn't affect the original
+	dup.Validation.AddRequired("next")
+	dup.Meta["rpc:tag"][0] = "2"
+	AsObject(dnode).Set("extra", &AttributeExpr{Type: String})
+	if len(att.Validation.Required) != 1 || att.Meta["rpc:tag"][0] != "1" || node.Find("extra") != nil {
+		t.Errorf("got original attribute modified by changes to the copy")
+	}
+
+	if Dup(Empty) != Empty {
+		t.Errorf("got copy of Empty, expected Empty")
+	}
+}
+
+func TestObjectMerge(t *testing.T) {
+	var (
+		o     = &Object{{"a", &AttributeExpr{Type: String}}}
+		other = &Object{{"a", &AttributeExpr{Type: Int}}, {"b", &AttributeExpr{Type: Int}}}
+	)
+	res := o.Merge(other)
+	if len(*o) != 1 || o.Attribute("a").Type != String {
+		t.Errorf("got receiver modified, expected a new object")
+	}
+	if len(*res) != 2 || res.Attribute("a").Type != Int {
+		t.Errorf("got %s, expected attributes of other to override", res.Hash())
+	}
+	if res.Attribute("b") == other.Attribute("b") {
+		t.Errorf("got shared attribute, expected a copy")
+	}
+}
@@ -0,0 +1,154 @@
+package expr
+
+import (
+	"fmt"
+
+	"go.zoe.im/goser/eval"
+)
+
+type (
+	// EnumTypeExpr describes a named enumeration. It is a user type whose
+	// attribute is a string or integer primitive validated against the
+	// enumerated values so that it can be used anywhere a type is expected.
+	EnumTypeExpr struct {
+		*UserTypeExpr
+		// Values lists the enumerated values in definition order.
+		Values []*EnumValueExpr
+	}
+
+	// EnumValueExpr describes a value of an enumeration.
+	EnumValueExpr struct {
+		// Value is the actual value, a string or an integer.
+		Value interface{}
+		// Name of the value used to generate identifiers, defaults to
+		// the value itself.
+		Name string
+		// Description of the value
+		Description string
+		// Tag is the number of the value in proto enums, 0 is reserved
+		// for the UNSPECIFIED value. Defaults to the value position
+		// starting at 1.
+		Tag int
+		// Meta is a list of key/value pairs, e.g. the deprecation of
+		// the value.
+		Meta MetaExpr
+	}
+)
+
+// NewEnumTypeExpr creates a named enumeration of the given primitive type, the
+// names and tags of the values are initialized with their defaults if not
+// set.
+func NewEnumTypeExpr(name string, base Primitive, values ...*EnumValueExpr) *EnumTypeExpr {
+	enum := make([]interface{}, len(values))
+	for i, v := range values {
+		if v.Name == "" {
+			v.Name = fmt.Sprintf("%v", v.Value)
+		}
+		if v.Tag == 0 {
+			v.Tag = i + 1
+		}
+		enum[i] = v.Value
+	}
+	return &EnumTypeExpr{
+		UserTypeExpr: &UserTypeExpr{
+			TypeName: name,
+			AttributeExpr: &AttributeExpr{
+				Type:       base,
+				Validation: &ValidationExpr{Values: enum},
+			},
+		},
+		Values: values,
+	}
+}
+
+// AsEnum returns the enumeration if the data type is one, nil otherwise.
+func AsEnum(dt DataType) *EnumTypeExpr {
+	e, _ := dt.(*EnumTypeExpr)
+	return e
+}
+
+// Base returns the primitive type of the enumerated values.
+func (e *EnumTypeExpr) Base() Primitive {
+	p, _ := e.Type.(Primitive)
+	return p
+}
+
+// Value returns the enumerated value with the given name, nil if there is
+// none.
+func (e *EnumTypeExpr) Value(name string) *EnumValueExpr {
+	for _, v := range e.Values {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// Dup creates a copy of the enumeration given a deep copy of its attribute.
+func (e *EnumTypeExpr) Dup(att *AttributeExpr) UserType {
+	values := make([]*EnumValueExpr, len(e.Values))
+	for i, v := range e.Values {
+		dup := *v
+		values[i] = &dup
+	}
+	return &EnumTypeExpr{
+		UserTypeExpr: &UserTypeExpr{AttributeExpr: att, TypeName: e.TypeName},
+		Values:       values,
+	}
+}
+
+// Hash returns a unique hash value for e.
+func (e *EnumTypeExpr) Hash() string {
+	return "_enum_+" + e.TypeName
+}
+
+// Example returns one of the enumerated values.
+func (e *EnumTypeExpr) Example(r *Random) interface{} {
+	if len(e.Values) == 0 {
+		return nil
+	}
+	return e.Values[r.Intn(len(e.Values))].Value
+}
+
+// Validate checks that the enumeration has a string or integer type, that the
+// values are compatible with it and that the names and tags are unique.
+func (e *EnumTypeExpr) Validate(ctx string, parent eval.Expression) *eval.ValidationErrors {
+	verr := new(eval.ValidationErrors)
+	if ctx != "" {
+		ctx += " - "
+	}
+	switch e.Type {
+	case String, Int, Int32, Int64, UInt, UInt32, UInt64:
+	default:
+		verr.Add(parent, "%senum %s must be a string or an integer, got %s", ctx, e.TypeName, e.Type.Name())
+		return verr
+	}
+	if len(e.Values) == 0 {
+		verr.Add(parent, "%senum %s must define at least one value", ctx, e.TypeName)
+	}
+	var (
+		names = make(map[string]bool)
+		tags  = make(map[int]bool)
+	)
+	for _, v := range e.Values {
+		if !compatibleValue(e.Base(), v.Value) {
+			verr.Add(parent, "%senum %s value %s must be %s", ctx, e.TypeName, formatValue(v.Value), e.Type.Name())
+		}
+		if names[v.Name] {
+			verr.Add(parent, "%senum %s defines value name %q more than once", ctx, e.TypeName, v.Name)
+		}
+		names[v.Name] = true
+		if v.Tag <= 0 {
+			verr.Add(parent, "%senum %s value %q tag must be greater than 0", ctx, e.TypeName, v.Name)
+		} else if tags[v.Tag] {
+			verr.Add(parent, "%senum %s defines tag %d more than once", ctx, e.TypeName, v.Tag)
+		}
+		tags[v.Tag] = true
+	}
+	return verr
+}
+
+// EvalName returns the name used by the DSL evaluation.
+func (v *EnumValueExpr) EvalName() string {
+	return fmt.Sprintf("enum value %q", v.Name)
+}
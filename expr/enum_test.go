@@ -0,0 +1,66 @@
+package expr
+
+import "testing"
+
+func TestEnumTypeExprValidate(t *testing.T) {
+	cases := map[string]struct {
+		enum     *EnumTypeExpr
+		expected int
+	}{
+		"valid": {
+			enum: NewEnumTypeExpr("Status", String,
+				&EnumValueExpr{Value: "active"},
+				&EnumValueExpr{Value: "inactive", Tag: 5},
+			),
+		},
+		"invalid base": {
+			enum:     NewEnumTypeExpr("Status", Float64, &EnumValueExpr{Value: 1.5}),
+			expected: 1,
+		},
+		"incompatible value": {
+			enum:     NewEnumTypeExpr("Level", Int32, &EnumValueExpr{Value: "high"}),
+			expected: 1,
+		},
+		"duplicated name and tag": {
+			enum: NewEnumTypeExpr("Status", String,
+				&EnumValueExpr{Value: "a", Name: "active"},
+				&EnumValueExpr{Value: "b", Name: "active", Tag: 1},
+			),
+			expected: 2,
+		},
+		"no value": {
+			enum:     NewEnumTypeExpr("Status", String),
+			expected: 1,
+		},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			if actual := len(tc.enum.Validate("", nil).Errors); actual != tc.expected {
+				t.Errorf("got %d errors, expected %d: %v", actual, tc.expected, tc.enum.Validate("", nil).Errors)
+			}
+		})
+	}
+}
+
+func TestEnumTypeExprValues(t *testing.T) {
+	enum := NewEnumTypeExpr("Level", Int,
+		&EnumValueExpr{Value: 10, Name: "low"},
+		&EnumValueExpr{Value: 20, Name: "high"},
+	)
+	att := &AttributeExpr{Type: enum}
+	if v := enum.Value("high"); v == nil || v.Tag != 2 {
+		t.Errorf("got %#v, expected value with tag 2", v)
+	}
+	if err := Validate(att, 20); err != nil {
+		t.Errorf("got error %v, expected none", err)
+	}
+	if err := Validate(att, 30); err == nil {
+		t.Errorf("got no error, expected value to be rejected")
+	}
+	if ex := att.Example(NewRandom("enum")); ex != 10 && ex != 20 {
+		t.Errorf("got example %#v, expected one of the values", ex)
+	}
+	if !IsPrimitive(enum) {
+		t.Errorf("got non primitive enum, expected primitive")
+	}
+}
@@ -0,0 +1,177 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+
+	"go.zoe.im/goser/eval"
+)
+
+const (
+	// ErrorTemporaryMeta is the meta key of temporary (i.e. retryable)
+	// errors.
+	ErrorTemporaryMeta = "goa:error:temporary"
+	// ErrorTimeoutMeta is the meta key of errors due to timeouts.
+	ErrorTimeoutMeta = "goa:error:timeout"
+	// ErrorFaultMeta is the meta key of errors due to server-side faults.
+	ErrorFaultMeta = "goa:error:fault"
+	// ErrorHTTPStatusMeta is the meta key of the HTTP status of errors.
+	ErrorHTTPStatusMeta = "error:http:status"
+	// ErrorGRPCCodeMeta is the meta key of the gRPC code of errors.
+	ErrorGRPCCodeMeta = "error:grpc:code"
+)
+
+type (
+	// ErrorExpr defines an error response. It consists of a named
+	// attribute.
+	ErrorExpr struct {
+		// AttributeExpr is the underlying attribute.
+		*AttributeExpr
+		// Name is the unique name of the error.
+		Name string
+	}
+)
+
+// ErrorResult is the built-in type of errors which don't define a type. The
+// fields are the error name, a unique id, a message and the temporary, timeout
+// and fault flags.
+var ErrorResult = &UserTypeExpr{
+	TypeName: "ErrorResult",
+	AttributeExpr: &AttributeExpr{
+		Description: "Error response result type",
+		Type: &Object{
+			{Name: "name", Attribute: &AttributeExpr{Type: String, Description: "Name is the name of this class of errors."}},
+			{Name: "id", Attribute: &AttributeExpr{Type: String, Description: "ID is a unique identifier for this particular occurrence of the problem."}},
+			{Name: "message", Attribute: &AttributeExpr{Type: String, Description: "Message is a human-readable explanation specific to this occurrence of the problem."}},
+			{Name: "temporary", Attribute: &AttributeExpr{Type: Boolean, Description: "Is the error temporary?"}},
+			{Name: "timeout", Attribute: &AttributeExpr{Type: Boolean, Description: "Is the error a timeout?"}},
+			{Name: "fault", Attribute: &AttributeExpr{Type: Boolean, Description: "Is the error a server-side fault?"}},
+		},
+		Validation: &ValidationExpr{
+			Required: []string{"name", "id", "message", "temporary", "timeout", "fault"},
+		},
+	},
+}
+
+// NewErrorExpr creates an error with the given name and type, the type
+// defaults to ErrorResult.
+func NewErrorExpr(name string, t DataType) *ErrorExpr {
+	if t == nil {
+		t = ErrorResult
+	}
+	return &ErrorExpr{AttributeExpr: &AttributeExpr{Type: t}, Name: name}
+}
+
+// EvalName returns the name used by the DSL evaluation.
+func (e *ErrorExpr) EvalName() string {
+	return "error " + e.Name
+}
+
+// IsTemporary returns true if the error is temporary (i.e. retryable).
+func (e *ErrorExpr) IsTemporary() bool {
+	_, ok := e.Meta[ErrorTemporaryMeta]
+	return ok
+}
+
+// IsTimeout returns true if the error is due to a timeout.
+func (e *ErrorExpr) IsTimeout() bool {
+	_, ok := e.Meta[ErrorTimeoutMeta]
+	return ok
+}
+
+// IsFault returns true if the error is due to a server-side fault.
+func (e *ErrorExpr) IsFault() bool {
+	_, ok := e.Meta[ErrorFaultMeta]
+	return ok
+}
+
+// HTTPStatus returns the HTTP status of the error responses. It defaults to
+// 500 for faults, 504 for timeouts, 503 for temporary errors and 400 for the
+// other errors.
+func (e *ErrorExpr) HTTPStatus() int {
+	if s := e.Meta[ErrorHTTPStatusMeta]; len(s) > 0 {
+		if status, err := strconv.Atoi(s[0]); err == nil {
+			return status
+		}
+	}
+	switch {
+	case e.IsFault():
+		return 500
+	case e.IsTimeout():
+		return 504
+	case e.IsTemporary():
+		return 503
+	}
+	return 400
+}
+
+// GRPCCode returns the gRPC code of the error responses. It defaults to the
+// code matching the HTTP status.
+func (e *ErrorExpr) GRPCCode() int {
+	if c := e.Meta[ErrorGRPCCodeMeta]; len(c) > 0 {
+		if code, err := strconv.Atoi(c[0]); err == nil {
+			return code
+		}
+	}
+	switch e.HTTPStatus() {
+	case 400:
+		return 3 // InvalidArgument
+	case 401:
+		return 16 // Unauthenticated
+	case 403:
+		return 7 // PermissionDenied
+	case 404:
+		return 5 // NotFound
+	case 409:
+		return 6 // AlreadyExists
+	case 412:
+		return 9 // FailedPrecondition
+	case 429:
+		return 8 // ResourceExhausted
+	case 499:
+		return 1 // Canceled
+	case 500:
+		return 13 // Internal
+	case 501:
+		return 12 // Unimplemented
+	case 503:
+		return 14 // Unavailable
+	case 504:
+		return 4 // DeadlineExceeded
+	}
+	return 2 // Unknown
+}
+
+// Validate checks that the error has a name, that the HTTP status is a valid
+// error status and that the gRPC code is a valid error code. It is the root
+// of a validation, see AttributeValidator.ValidateError.
+func (e *ErrorExpr) Validate(ctx string, parent eval.Expression) *eval.ValidationErrors {
+	return NewAttributeValidator().ValidateError(e, ctx, parent)
+}
+
+// ValidateError validates the error, its attribute is validated if it hasn't
+// been validated by v yet so that the types shared by several errors are
+// reported once.
+func (v *AttributeValidator) ValidateError(e *ErrorExpr, ctx string, parent eval.Expression) *eval.ValidationErrors {
+	verr := new(eval.ValidationErrors)
+	if ctx != "" {
+		ctx += " - "
+	}
+	if e.Name == "" {
+		verr.Add(parent, "%serror name cannot be empty", ctx)
+	}
+	if s := e.Meta[ErrorHTTPStatusMeta]; len(s) > 0 {
+		if status, err := strconv.Atoi(s[0]); err != nil || status < 400 || status > 599 {
+			verr.Add(parent, "%serror %s HTTP status must be between 400 and 599, got %s", ctx, e.Name, s[0])
+		}
+	}
+	if c := e.Meta[ErrorGRPCCodeMeta]; len(c) > 0 {
+		if code, err := strconv.Atoi(c[0]); err != nil || code < 1 || code > 16 {
+			verr.Add(parent, "%serror %s gRPC code must be between 1 and 16, got %s", ctx, e.Name, c[0])
+		}
+	}
+	if e.AttributeExpr != nil {
+		verr.Merge(v.Validate(e.AttributeExpr, fmt.Sprintf("%serror %s", ctx, e.Name), parent))
+	}
+	return verr
+}
@@ -0,0 +1,80 @@
+package expr
+
+import (
+	"testing"
+)
+
+func TestErrorExprMapping(t *testing.T) {
+	cases := map[string]struct {
+		meta   MetaExpr
+		status int
+		code   int
+	}{
+		"default":   {nil, 400, 3},
+		"fault":     {MetaExpr{ErrorFaultMeta: nil}, 500, 13},
+		"timeout":   {MetaExpr{ErrorTimeoutMeta: nil}, 504, 4},
+		"temporary": {MetaExpr{ErrorTemporaryMeta: nil}, 503, 14},
+		"status":    {MetaExpr{ErrorHTTPStatusMeta: {"404"}}, 404, 5},
+		"code":      {MetaExpr{ErrorHTTPStatusMeta: {"418"}, ErrorGRPCCodeMeta: {"9"}}, 418, 9},
+		"unmapped":  {MetaExpr{ErrorHTTPStatusMeta: {"418"}}, 418, 2},
+	}
+	for k, tc := range cases {
+		e := NewErrorExpr("error", nil)
+		e.Meta = tc.meta
+		if actual := e.HTTPStatus(); actual != tc.status {
+			t.Errorf("%s: got status %d, expected %d", k, actual, tc.status)
+		}
+		if actual := e.GRPCCode(); actual != tc.code {
+			t.Errorf("%s: got code %d, expected %d", k, actual, tc.code)
+		}
+	}
+}
+
+func TestErrorExprValidate(t *testing.T) {
+	cases := map[string]struct {
+		name string
+		meta MetaExpr
+		errs int
+	}{
+		"valid":        {"not_found", MetaExpr{ErrorHTTPStatusMeta: {"404"}, ErrorGRPCCodeMeta: {"5"}}, 0},
+		"empty name":   {"", nil, 1},
+		"bad status":   {"bad", MetaExpr{ErrorHTTPStatusMeta: {"200"}}, 1},
+		"bad code":     {"bad", MetaExpr{ErrorGRPCCodeMeta: {"0"}}, 1},
+		"invalid code": {"bad", MetaExpr{ErrorGRPCCodeMeta: {"x"}}, 1},
+	}
+	for k, tc := range cases {
+		e := NewErrorExpr(tc.name, nil)
+		e.Meta = tc.meta
+		var n int
+		if verr := e.Validate("", nil); verr != nil {
+			n = len(verr.Errors)
+		}
+		if n != tc.errs {
+			t.Errorf("%s: got %d errors, expected %d", k, n, tc.errs)
+		}
+	}
+}
+
+func TestAttributeValidatorValidateError(t *testing.T) {
+	precision := 0
+	amount := &UserTypeExpr{TypeName: "Amount", AttributeExpr: &AttributeExpr{
+		Type:       Decimal,
+		Validation: &ValidationExpr{Precision: &precision},
+	}}
+	errs := []*ErrorExpr{NewErrorExpr("too_low", amount), NewErrorExpr("too_high", amount)}
+
+	// the type shared by the errors is reported once by a shared validator.
+	var n int
+	v := NewAttributeValidator()
+	for _, e := range errs {
+		n += len(v.ValidateError(e, "", nil).Errors)
+	}
+	if n != 1 {
+		t.Errorf("got %d errors, expected 1", n)
+	}
+	for _, e := range errs {
+		if verr := e.Validate("", nil); len(verr.Errors) != 1 {
+			t.Errorf("%s: got %d errors %v, expected 1", e.Name, len(verr.Errors), verr.Errors)
+		}
+	}
+}
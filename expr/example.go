@@ -0,0 +1,284 @@
+package expr
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Example returns the example set on the attribute or on its user type at
+// design time if any. Otherwise it generates a pseudo-random value using the
+// given random generator, the generated value satisfies the attribute
+// validations: enum, format, pattern, minimum, maximum and length.
+func (a *AttributeExpr) Example(r *Random) interface{} {
+	if a == nil || a.Type == nil {
+		return nil
+	}
+	if ex := a.ExtractUserExamples(); len(ex) > 0 {
+		// Return the last item in the slice so that examples can be
+		// overridden in the DSL.
+		return ex[len(ex)-1].Value
+	}
+	v := a.Validation
+	if v == nil || v.HasRequiredOnly() {
+		return a.Type.Example(r)
+	}
+	if len(v.Values) > 0 {
+		return v.Values[r.Intn(len(v.Values))]
+	}
+	switch a.Type.Kind() {
+	case StringKind:
+		return stringExample(v, r)
+	case BytesKind:
+		return []byte(stringExample(v, r))
+	case IntKind, Int32Kind, Int64Kind, UIntKind, UInt32Kind, UInt64Kind:
+		return intExample(a.Type, v, r)
+	case Float32Kind, Float64Kind:
+		return floatExample(a.Type, v, r)
+	case DecimalKind, BigIntKind:
+		if v.Precision != nil || v.Scale != nil {
+			return decimalExample(a.Type, v, r)
+		}
+	case ArrayKind:
+		ar := AsArray(a.Type)
+		count := lengthExample(v, 2, 4, r)
+		res := make([]interface{}, count)
+		for i := range res {
+			res[i] = ar.ElemType.Example(r)
+			if res[i] == nil {
+				// Handle the case of recursive data structures
+				res[i] = make(map[string]interface{})
+			}
+		}
+		return ar.MakeSlice(res)
+	case MapKind:
+		m := AsMap(a.Type)
+		if IsObject(m.KeyType.Type) || IsArray(m.KeyType.Type) || IsMap(m.KeyType.Type) {
+			// not much we can do for non hashable Go types
+			return nil
+		}
+		count := lengthExample(v, 1, 3, r)
+		pair := map[interface{}]interface{}{}
+		// duplicated keys may be generated, give up after a few tries.
+		for i := 0; len(pair) < count && i < count*10; i++ {
+			key := m.KeyType.Example(r)
+			val := m.ElemType.Example(r)
+			if key != nil && val != nil {
+				pair[key] = val
+			}
+		}
+		return m.MakeMap(pair)
+	}
+	return a.Type.Example(r)
+}
+
+// ExtractUserExamples return the examples defined on the attribute, the
+// examples defined on its user type are used if the attribute has none.
+func (a *AttributeExpr) ExtractUserExamples() []*ExampleExpr {
+	if len(a.UserExamples) > 0 {
+		return a.UserExamples
+	}
+	if ut, ok := a.Type.(UserType); ok {
+		if att := ut.Attribute(); att != nil {
+			return att.ExtractUserExamples()
+		}
+	}
+	return nil
+}
+
+// stringExample produces a string which satisfies the format or the pattern
+// if any, otherwise a string with the length between min and max length. The
+// format and pattern examples are regenerated until their length is between
+// min and max length, the last one being used if none is.
+func stringExample(v *ValidationExpr, r *Random) string {
+	fits := func(s string) bool {
+		n := utf8.RuneCountInString(s)
+		return (v.MinLength == nil || n >= *v.MinLength) && (v.MaxLength == nil || n <= *v.MaxLength)
+	}
+	generate := func(gen func() (string, bool)) (string, bool) {
+		var (
+			s  string
+			ok bool
+		)
+		for i := 0; i < 100; i++ {
+			if s, ok = gen(); !ok || fits(s) {
+				break
+			}
+		}
+		return s, ok
+	}
+	if v.Format != "" {
+		if s, ok := generate(func() (string, bool) { return r.Format(v.Format) }); ok {
+			return s
+		}
+	}
+	if v.Pattern != "" {
+		if s, ok := generate(func() (string, bool) { return r.Pattern(v.Pattern) }); ok {
+			return s
+		}
+	}
+	s := r.String()
+	if v.MinLength == nil && v.MaxLength == nil {
+		return s
+	}
+	n := lengthExample(v, utf8.RuneCountInString(s), utf8.RuneCountInString(s), r)
+	for utf8.RuneCountInString(s) < n {
+		s += " " + r.Word()
+	}
+	return string([]rune(s)[:n])
+}
+
+// decimalExample produces a decimal or big integer string with at most the
+// given precision and scale.
+func decimalExample(t DataType, v *ValidationExpr, r *Random) string {
+	scale := 0
+	if t.Kind() == DecimalKind {
+		scale = 2
+		if v.Scale != nil {
+			scale = *v.Scale
+		}
+	}
+	precision := scale + 4
+	if v.Precision != nil {
+		precision = *v.Precision
+	}
+	digits := func(n int) string {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = byte('0' + r.Intn(10))
+		}
+		return string(b)
+	}
+	s := "0"
+	if n := precision - scale; n > 0 {
+		s = strings.TrimLeft(digits(n), "0")
+		if s == "" {
+			s = "0"
+		}
+	}
+	if scale > 0 {
+		s += "." + digits(scale)
+	}
+	return s
+}
+
+// intExample produces an integer between minimum and maximum using the Go
+// type of the primitive example. The bounds are clamped to the range of the
+// Go type.
+func intExample(t DataType, v *ValidationExpr, r *Random) interface{} {
+	min, max := intRange(t.Kind())
+	lo, hi := clampInt(0, min, max), clampInt(1000, min, max)
+	switch {
+	case v.Minimum != nil && v.Maximum != nil:
+		lo, hi = clampInt(math.Ceil(*v.Minimum), min, max), clampInt(math.Floor(*v.Maximum), min, max)
+	case v.Minimum != nil:
+		lo, hi = clampInt(math.Ceil(*v.Minimum), min, max), max
+		if lo <= max-1000 {
+			hi = lo + 1000
+		}
+	case v.Maximum != nil:
+		lo, hi = min, clampInt(math.Floor(*v.Maximum), min, max)
+		if hi >= min+1000 {
+			lo = hi - 1000
+		}
+	}
+	n := lo
+	if hi > lo {
+		// the span is computed as an unsigned integer so that it doesn't
+		// overflow for the full range of int64.
+		span := uint64(hi) - uint64(lo)
+		off := r.rand.Uint64()
+		if span < math.MaxUint64 {
+			off %= span + 1
+		}
+		n = int64(uint64(lo) + off)
+	}
+	switch t.Kind() {
+	case Int32Kind, UInt32Kind:
+		return int32(n)
+	case Int64Kind, UInt64Kind:
+		return n
+	default:
+		return int(n)
+	}
+}
+
+// intRange returns the range of the Go type of the integer examples of kind,
+// the unsigned integers use the Go type of the signed ones.
+func intRange(kind Kind) (int64, int64) {
+	maxInt := int64(^uint(0) >> 1)
+	switch kind {
+	case Int32Kind:
+		return math.MinInt32, math.MaxInt32
+	case UInt32Kind:
+		return 0, math.MaxInt32
+	case Int64Kind:
+		return math.MinInt64, math.MaxInt64
+	case UInt64Kind:
+		return 0, math.MaxInt64
+	case UIntKind:
+		return 0, maxInt
+	default:
+		return -maxInt - 1, maxInt
+	}
+}
+
+// clampInt converts f to an integer clamped to [min, max].
+func clampInt(f float64, min, max int64) int64 {
+	switch {
+	case f <= float64(min):
+		return min
+	case f >= float64(max):
+		return max
+	}
+	return int64(f)
+}
+
+// floatExample produces a number between minimum and maximum using the Go
+// type of the primitive example.
+func floatExample(t DataType, v *ValidationExpr, r *Random) interface{} {
+	lo, hi := 0.0, 1000.0
+	switch {
+	case v.Minimum != nil && v.Maximum != nil:
+		lo, hi = *v.Minimum, *v.Maximum
+	case v.Minimum != nil:
+		lo = *v.Minimum
+		hi = lo + 1000
+	case v.Maximum != nil:
+		hi = *v.Maximum
+		lo = hi - 1000
+	}
+	f := lo + r.Float64()*(hi-lo)
+	if t.Kind() == Float32Kind {
+		f32 := float32(f)
+		// rounding may go over the limits
+		if float64(f32) < lo || float64(f32) > hi {
+			f32 = float32(lo)
+		}
+		return f32
+	}
+	return f
+}
+
+// lengthExample produces a length between min and max length, lo and hi are
+// used when no length validation is defined.
+func lengthExample(v *ValidationExpr, lo, hi int, r *Random) int {
+	switch {
+	case v.MinLength != nil && v.MaxLength != nil:
+		lo, hi = *v.MinLength, *v.MaxLength
+	case v.MinLength != nil:
+		lo = *v.MinLength
+		if hi < lo {
+			hi = lo + 2
+		}
+	case v.MaxLength != nil:
+		hi = *v.MaxLength
+		if lo > hi {
+			lo = hi
+		}
+	}
+	if hi <= lo {
+		return lo
+	}
+	return lo + r.Intn(hi-lo+1)
+}
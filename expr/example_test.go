@@ -0,0 +1,189 @@
+package expr
+
+import (
+	"math"
+	"net"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestAttributeExprExample(t *testing.T) {
+	var (
+		min      = 10.0
+		max      = 20.0
+		big      = 1e20
+		neg      = -5.0
+		minInt64 = float64(math.MinInt64)
+		minLen   = 5
+		maxLen   = 8
+		ipLen    = 12
+	)
+	cases := map[string]struct {
+		att   *AttributeExpr
+		check func(interface{}) bool
+	}{
+		"user example": {
+			att: &AttributeExpr{Type: String, UserExamples: []*ExampleExpr{{Value: "foo"}}},
+			check: func(v interface{}) bool {
+				return v == "foo"
+			},
+		},
+		"enum": {
+			att: &AttributeExpr{Type: String, Validation: &ValidationExpr{Values: []interface{}{"a", "b"}}},
+			check: func(v interface{}) bool {
+				return v == "a" || v == "b"
+			},
+		},
+		"int range": {
+			att: &AttributeExpr{Type: Int32, Validation: &ValidationExpr{Minimum: &min, Maximum: &max}},
+			check: func(v interface{}) bool {
+				i, ok := v.(int32)
+				return ok && i >= 10 && i <= 20
+			},
+		},
+		"int32 above range": {
+			att: &AttributeExpr{Type: Int32, Validation: &ValidationExpr{Minimum: &big}},
+			check: func(v interface{}) bool {
+				return v == int32(math.MaxInt32)
+			},
+		},
+		"int64 full range": {
+			att: &AttributeExpr{Type: Int64, Validation: &ValidationExpr{Minimum: &minInt64, Maximum: &big}},
+			check: func(v interface{}) bool {
+				_, ok := v.(int64)
+				return ok
+			},
+		},
+		"uint32 maximum": {
+			att: &AttributeExpr{Type: UInt32, Validation: &ValidationExpr{Maximum: &big}},
+			check: func(v interface{}) bool {
+				i, ok := v.(int32)
+				return ok && i >= math.MaxInt32-1000
+			},
+		},
+		"uint64 negative maximum": {
+			att: &AttributeExpr{Type: UInt64, Validation: &ValidationExpr{Maximum: &neg}},
+			check: func(v interface{}) bool {
+				return v == int64(0)
+			},
+		},
+		"float minimum": {
+			att: &AttributeExpr{Type: Float64, Validation: &ValidationExpr{Minimum: &max}},
+			check: func(v interface{}) bool {
+				f, ok := v.(float64)
+				return ok && f >= 20
+			},
+		},
+		"decimal precision": {
+			att: &AttributeExpr{Type: Decimal, Validation: &ValidationExpr{Precision: &maxLen, Scale: &minLen}},
+			check: func(v interface{}) bool {
+				return Validate(&AttributeExpr{Type: Decimal, Validation: &ValidationExpr{Precision: &maxLen, Scale: &minLen}}, v) == nil
+			},
+		},
+		"string length": {
+			att: &AttributeExpr{Type: String, Validation: &ValidationExpr{MinLength: &minLen, MaxLength: &maxLen}},
+			check: func(v interface{}) bool {
+				n := utf8.RuneCountInString(v.(string))
+				return n >= 5 && n <= 8
+			},
+		},
+		"array length": {
+			att: &AttributeExpr{Type: &Array{ElemType: &AttributeExpr{Type: String}}, Validation: &ValidationExpr{MinLength: &minLen}},
+			check: func(v interface{}) bool {
+				return len(v.([]string)) >= 5
+			},
+		},
+		"pattern": {
+			att: &AttributeExpr{Type: String, Validation: &ValidationExpr{Pattern: `^[A-Z]{2}-\d{3,5}(x|y)?$`}},
+			check: func(v interface{}) bool {
+				return regexp.MustCompile(`^[A-Z]{2}-\d{3,5}(x|y)?$`).MatchString(v.(string))
+			},
+		},
+		"pattern length": {
+			att: &AttributeExpr{Type: String, Validation: &ValidationExpr{Pattern: `^[A-Z]{2}-\d{3,5}$`, MinLength: &maxLen}},
+			check: func(v interface{}) bool {
+				return regexp.MustCompile(`^[A-Z]{2}-\d{5}$`).MatchString(v.(string))
+			},
+		},
+		"format length": {
+			att: &AttributeExpr{Type: String, Validation: &ValidationExpr{Format: FormatIPv4, MaxLength: &ipLen}},
+			check: func(v interface{}) bool {
+				ip := net.ParseIP(v.(string))
+				return ip != nil && ip.To4() != nil && len(v.(string)) <= 12
+			},
+		},
+		"email": {
+			att: &AttributeExpr{Type: String, Validation: &ValidationExpr{Format: FormatEmail}},
+			check: func(v interface{}) bool {
+				return regexp.MustCompile(`^\w+@\w+\.com$`).MatchString(v.(string))
+			},
+		},
+		"uuid": {
+			att: &AttributeExpr{Type: String, Validation: &ValidationExpr{Format: FormatUUID}},
+			check: func(v interface{}) bool {
+				return regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(v.(string))
+			},
+		},
+		"date-time": {
+			att: &AttributeExpr{Type: String, Validation: &ValidationExpr{Format: FormatDateTime}},
+			check: func(v interface{}) bool {
+				_, err := time.Parse(time.RFC3339, v.(string))
+				return err == nil
+			},
+		},
+		"ipv4": {
+			att: &AttributeExpr{Type: String, Validation: &ValidationExpr{Format: FormatIPv4}},
+			check: func(v interface{}) bool {
+				ip := net.ParseIP(v.(string))
+				return ip != nil && ip.To4() != nil
+			},
+		},
+		"ipv6": {
+			att: &AttributeExpr{Type: String, Validation: &ValidationExpr{Format: FormatIPv6}},
+			check: func(v interface{}) bool {
+				return net.ParseIP(v.(string)) != nil
+			},
+		},
+		"cidr": {
+			att: &AttributeExpr{Type: String, Validation: &ValidationExpr{Format: FormatCIDR}},
+			check: func(v interface{}) bool {
+				_, _, err := net.ParseCIDR(v.(string))
+				return err == nil
+			},
+		},
+		"mac": {
+			att: &AttributeExpr{Type: String, Validation: &ValidationExpr{Format: FormatMAC}},
+			check: func(v interface{}) bool {
+				_, err := net.ParseMAC(v.(string))
+				return err == nil
+			},
+		},
+	}
+
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				r := NewRandom(k + string(rune('a'+i)))
+				if actual := tc.att.Example(r); !tc.check(actual) {
+					t.Errorf("got invalid example %#v", actual)
+				}
+			}
+		})
+	}
+}
+
+func TestRandomDeterministic(t *testing.T) {
+	att := &AttributeExpr{
+		Type: &Object{
+			{"name", &AttributeExpr{Type: String}},
+			{"ids", &AttributeExpr{Type: &Array{ElemType: &AttributeExpr{Type: Int64}}}},
+		},
+	}
+	first := att.Example(NewRandom("User"))
+	if second := att.Example(NewRandom("User")); !reflect.DeepEqual(first, second) {
+		t.Errorf("got %#v, expected %#v with the same seed", second, first)
+	}
+}
@@ -0,0 +1,29 @@
+package expr
+
+const (
+	// GraphQLQueryMeta is the meta key of the methods exposed as fields of
+	// the GraphQL Query type, the optional value is the name of the field.
+	GraphQLQueryMeta = "graphql:query"
+	// GraphQLMutationMeta is the meta key of the methods exposed as fields
+	// of the GraphQL Mutation type, the optional value is the name of the
+	// field.
+	GraphQLMutationMeta = "graphql:mutation"
+)
+
+// GraphQLOperation returns the GraphQL operation type, "query" or "mutation",
+// of the method with the given meta and the name of its field, empty if the
+// method is not exposed with GraphQL. The name is empty if not set in the
+// meta.
+func GraphQLOperation(meta MetaExpr) (op, name string) {
+	for _, key := range []string{GraphQLQueryMeta, GraphQLMutationMeta} {
+		v, ok := meta[key]
+		if !ok {
+			continue
+		}
+		if len(v) > 0 {
+			name = v[0]
+		}
+		return key[len("graphql:"):], name
+	}
+	return "", ""
+}
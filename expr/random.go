@@ -0,0 +1,253 @@
+package expr
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"net"
+	"regexp/syntax"
+	"strings"
+	"time"
+)
+
+// Random generates pseudo-random values, the generated values are
+// deterministic for a given seed.
+type Random struct {
+	// Seed used to initialize the generator
+	Seed string
+	// Seen keeps track of the user types examples to handle
+	// recursive definitions.
+	Seen map[string]*interface{}
+
+	rand *rand.Rand
+}
+
+// words used to generate random strings
+var words = []string{
+	"alias", "amet", "aut", "beatae", "culpa", "dolor", "dolorem",
+	"ea", "eius", "error", "est", "et", "eum", "facere", "fugit",
+	"ipsa", "ipsum", "iure", "labore", "laudantium", "lorem", "magni",
+	"minima", "modi", "nam", "natus", "nihil", "non", "odio", "officia",
+	"omnis", "porro", "quae", "qui", "quia", "quis", "quo", "ratione",
+	"rem", "sed", "sint", "sit", "sunt", "tempora", "ut", "velit",
+	"vero", "vitae", "voluptas", "voluptatem",
+}
+
+// NewRandom creates a new random generator using the given seed. Using the
+// type name as seed produces stable examples, e.g. in generated docs.
+func NewRandom(seed string) *Random {
+	hasher := md5.New()
+	hasher.Write([]byte(seed))
+	sint := int64(binary.BigEndian.Uint64(hasher.Sum(nil)))
+	return &Random{
+		Seed: seed,
+		rand: rand.New(rand.NewSource(sint)),
+	}
+}
+
+// Int produces a random non-negative integer.
+func (r *Random) Int() int {
+	return r.rand.Int() % 1000
+}
+
+// Int32 produces a random 32-bit non-negative integer.
+func (r *Random) Int32() int32 {
+	return r.rand.Int31()
+}
+
+// Int64 produces a random 64-bit non-negative integer.
+func (r *Random) Int64() int64 {
+	return r.rand.Int63()
+}
+
+// UInt produces a random unsigned integer.
+func (r *Random) UInt() uint {
+	return uint(r.Int())
+}
+
+// UInt32 produces a random 32-bit unsigned integer.
+func (r *Random) UInt32() uint32 {
+	return r.rand.Uint32()
+}
+
+// UInt64 produces a random 64-bit unsigned integer.
+func (r *Random) UInt64() uint64 {
+	return uint64(r.rand.Int63())
+}
+
+// Float32 produces a random float32 value.
+func (r *Random) Float32() float32 {
+	return r.rand.Float32()
+}
+
+// Float64 produces a random float64 value.
+func (r *Random) Float64() float64 {
+	return r.rand.Float64()
+}
+
+// Bool produces a random boolean.
+func (r *Random) Bool() bool {
+	return r.rand.Int()%2 == 0
+}
+
+// String produces a random string made of a few words.
+func (r *Random) String() string {
+	n := r.rand.Intn(4) + 1
+	ws := make([]string, n)
+	for i := range ws {
+		ws[i] = words[r.rand.Intn(len(words))]
+	}
+	return strings.Join(ws, " ")
+}
+
+// Intn produces a random integer in [0, n), it returns 0 if n <= 0.
+func (r *Random) Intn(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return r.rand.Intn(n)
+}
+
+// Word produces a random word.
+func (r *Random) Word() string {
+	return words[r.rand.Intn(len(words))]
+}
+
+// Format produces a random string which satisfies the given format, it
+// returns false if the format is not supported.
+func (r *Random) Format(f ValidationFormat) (string, bool) {
+	switch f {
+	case FormatDate:
+		return r.randTime().Format("2006-01-02"), true
+	case FormatDateTime:
+		return r.randTime().Format(time.RFC3339), true
+	case FormatRFC1123:
+		return r.randTime().Format(time.RFC1123), true
+	case FormatUUID:
+		b := r.randBytes(16)
+		b[6] = (b[6] & 0x0f) | 0x40 // version 4
+		b[8] = (b[8] & 0x3f) | 0x80 // variant RFC4122
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), true
+	case FormatEmail:
+		return r.Word() + "@" + r.Word() + ".com", true
+	case FormatHostname:
+		return r.Word() + "." + r.Word() + ".com", true
+	case FormatIPv4:
+		return net.IP(r.randBytes(4)).String(), true
+	case FormatIPv6:
+		return net.IP(r.randBytes(16)).String(), true
+	case FormatIP:
+		if r.Bool() {
+			return r.Format(FormatIPv4)
+		}
+		return r.Format(FormatIPv6)
+	case FormatURI:
+		return "https://" + r.Word() + ".com/" + r.Word(), true
+	case FormatMAC:
+		return net.HardwareAddr(r.randBytes(6)).String(), true
+	case FormatCIDR:
+		return fmt.Sprintf("%s/%d", net.IP(r.randBytes(4)), r.Intn(33)), true
+	case FormatRegexp:
+		return "^" + r.Word() + "$", true
+	case FormatJSON:
+		return fmt.Sprintf("{%q:%q}", r.Word(), r.Word()), true
+	}
+	return "", false
+}
+
+// Pattern produces a random string which matches the given RE2 regular
+// expression, it returns false if the pattern is invalid.
+func (r *Random) Pattern(pattern string) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var buf bytes.Buffer
+	r.regen(&buf, re.Simplify())
+	return buf.String(), true
+}
+
+// regen writes a random string matching re to buf.
+func (r *Random) regen(buf *bytes.Buffer, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, c := range re.Rune {
+			buf.WriteRune(c)
+		}
+	case syntax.OpCharClass:
+		buf.WriteRune(r.charClass(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		buf.WriteRune(rune('a' + r.Intn(26)))
+	case syntax.OpCapture:
+		r.regen(buf, re.Sub[0])
+	case syntax.OpStar:
+		r.repeat(buf, re.Sub[0], 0, 3)
+	case syntax.OpPlus:
+		r.repeat(buf, re.Sub[0], 1, 4)
+	case syntax.OpQuest:
+		r.repeat(buf, re.Sub[0], 0, 1)
+	case syntax.OpRepeat:
+		max := re.Max
+		if max < 0 {
+			max = re.Min + 3
+		}
+		r.repeat(buf, re.Sub[0], re.Min, max)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			r.regen(buf, sub)
+		}
+	case syntax.OpAlternate:
+		r.regen(buf, re.Sub[r.Intn(len(re.Sub))])
+	}
+}
+
+// repeat writes between min and max random strings matching re to buf.
+func (r *Random) repeat(buf *bytes.Buffer, re *syntax.Regexp, min, max int) {
+	n := min + r.Intn(max-min+1)
+	for i := 0; i < n; i++ {
+		r.regen(buf, re)
+	}
+}
+
+// charClass picks a rune from the ranges of a character class, printable
+// ASCII characters are preferred.
+func (r *Random) charClass(ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < 0x21 {
+			lo = 0x21
+		}
+		if hi > 0x7e {
+			hi = 0x7e
+		}
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+	if len(ranges) < 2 {
+		return 'a'
+	}
+	i := r.Intn(len(ranges)/2) * 2
+	return ranges[i] + rune(r.Intn(int(ranges[i+1]-ranges[i])+1))
+}
+
+// randTime produces a random time between 2000 and 2030.
+func (r *Random) randTime() time.Time {
+	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	return time.Unix(start+r.rand.Int63n(30*365*24*3600), 0).UTC()
+}
+
+// randBytes produces n random bytes.
+func (r *Random) randBytes(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(r.rand.Intn(256))
+	}
+	return b
+}
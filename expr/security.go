@@ -0,0 +1,337 @@
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.zoe.im/goser/eval"
+)
+
+const (
+	// OAuth2Kind identifies a "OAuth2" security scheme.
+	OAuth2Kind SchemeKind = iota + 1
+	// BasicAuthKind means "basic" security scheme.
+	BasicAuthKind
+	// APIKeyKind means "apiKey" security scheme.
+	APIKeyKind
+	// JWTKind means an "apiKey" security scheme, with support for
+	// TokenPath and Scopes.
+	JWTKind
+	// NoKind means to have no security for this endpoint.
+	NoKind
+)
+
+const (
+	// AuthorizationCodeFlowKind identifies a OAuth2 authorization code
+	// flow.
+	AuthorizationCodeFlowKind FlowKind = iota + 1
+	// ImplicitFlowKind identifiers a OAuth2 implicit flow.
+	ImplicitFlowKind
+	// PasswordFlowKind identifies a Resource Owner Password flow.
+	PasswordFlowKind
+	// ClientCredentialsFlowKind identifies a OAuth Client Credentials flow.
+	ClientCredentialsFlowKind
+)
+
+const (
+	// SecurityUsernameMeta is the meta key of the payload attributes
+	// holding the username of basic auth schemes.
+	SecurityUsernameMeta = "security:username"
+	// SecurityPasswordMeta is the meta key of the payload attributes
+	// holding the password of basic auth schemes.
+	SecurityPasswordMeta = "security:password"
+	// SecurityTokenMeta is the meta key of the payload attributes holding
+	// the token of JWT schemes.
+	SecurityTokenMeta = "security:token"
+	// SecurityAccessTokenMeta is the meta key of the payload attributes
+	// holding the access token of OAuth2 schemes.
+	SecurityAccessTokenMeta = "security:accesstoken"
+	// SecurityAPIKeyMeta is the prefix of the meta key of the payload
+	// attributes holding the key of API key schemes, the key is suffixed
+	// with the scheme name.
+	SecurityAPIKeyMeta = "security:apikey:"
+)
+
+type (
+	// SchemeKind is a type of security scheme.
+	SchemeKind int
+
+	// FlowKind is a type of OAuth2 flow.
+	FlowKind int
+
+	// SchemeExpr defines a security scheme used to authenticate against
+	// the method being designed.
+	SchemeExpr struct {
+		// Kind is the sort of security scheme this object represents.
+		Kind SchemeKind
+		// SchemeName is the name of the security scheme, e.g. "googAuth",
+		// "my_big_token", "jwt".
+		SchemeName string
+		// Description describes the security scheme e.g. "Google OAuth2"
+		Description string
+		// In determines the location of the API key, one of "header",
+		// "query" or "cookie".
+		In string
+		// Name refers to a header, parameter or cookie name.
+		Name string
+		// Scopes lists the JWT or OAuth2 scopes.
+		Scopes []*ScopeExpr
+		// Flows determine the oauth2 flows.
+		Flows []*FlowExpr
+		// Meta is a list of key/value pairs
+		Meta MetaExpr
+	}
+
+	// SecurityExpr defines a security requirement.
+	SecurityExpr struct {
+		// Schemes is the list of security schemes used for this
+		// requirement, all the schemes must be satisfied.
+		Schemes []*SchemeExpr
+		// Scopes list the required scopes if any.
+		Scopes []string
+	}
+
+	// FlowExpr describes a specific OAuth2 flow.
+	FlowExpr struct {
+		// Kind is the kind of flow.
+		Kind FlowKind
+		// AuthorizationURL to be used for implicit or authorizationCode
+		// flows.
+		AuthorizationURL string
+		// TokenURL to be used for password, clientCredentials or
+		// authorizationCode flows.
+		TokenURL string
+		// RefreshURL to be used for obtaining refresh token.
+		RefreshURL string
+	}
+
+	// ScopeExpr defines a scope name and description.
+	ScopeExpr struct {
+		// Name of the scope.
+		Name string
+		// Description is the description of the scope.
+		Description string
+	}
+)
+
+// EvalName returns the generic definition name used in error messages.
+func (s *SchemeExpr) EvalName() string {
+	return fmt.Sprintf("%s security scheme %q", s.Kind, s.SchemeName)
+}
+
+// Hash returns a unique hash value for s.
+func (s *SchemeExpr) Hash() string {
+	return fmt.Sprintf("%s_%s_%s", s.Kind, s.In, s.SchemeName)
+}
+
+// Type returns the type of the scheme.
+func (s *SchemeExpr) Type() string {
+	switch s.Kind {
+	case OAuth2Kind:
+		return "OAuth2"
+	case BasicAuthKind:
+		return "BasicAuth"
+	case APIKeyKind:
+		return "APIKey"
+	case JWTKind:
+		return "JWT"
+	case NoKind:
+		return "NoSecurity"
+	}
+	panic(fmt.Sprintf("unknown scheme kind: %#v", s.Kind)) // bug
+}
+
+// Scope returns the scope of the scheme with the given name, nil if there
+// isn't any.
+func (s *SchemeExpr) Scope(name string) *ScopeExpr {
+	for _, sc := range s.Scopes {
+		if sc.Name == name {
+			return sc
+		}
+	}
+	return nil
+}
+
+// PayloadMeta returns the meta key of the payload attributes holding the
+// credentials of the scheme, basic auth schemes use two attributes.
+func (s *SchemeExpr) PayloadMeta() []string {
+	switch s.Kind {
+	case BasicAuthKind:
+		return []string{SecurityUsernameMeta, SecurityPasswordMeta}
+	case APIKeyKind:
+		return []string{SecurityAPIKeyMeta + s.SchemeName}
+	case JWTKind:
+		return []string{SecurityTokenMeta}
+	case OAuth2Kind:
+		return []string{SecurityAccessTokenMeta}
+	}
+	return nil
+}
+
+// Validate ensures that the scheme has a name, that the API keys are located
+// in a header, a query parameter or a cookie, that OAuth2 schemes define at
+// least one flow and that the flows define the URLs they need.
+func (s *SchemeExpr) Validate(ctx string, parent eval.Expression) *eval.ValidationErrors {
+	verr := new(eval.ValidationErrors)
+	if ctx != "" {
+		ctx += " - "
+	}
+	if s.SchemeName == "" {
+		verr.Add(parent, "%ssecurity scheme name cannot be empty", ctx)
+	}
+	switch s.Kind {
+	case APIKeyKind, JWTKind, OAuth2Kind:
+		switch s.In {
+		case "header", "query", "cookie":
+		default:
+			verr.Add(parent, "%s%s location must be header, query or cookie, got %q", ctx, s.EvalName(), s.In)
+		}
+		if s.Name == "" {
+			verr.Add(parent, "%s%s must define the %s name", ctx, s.EvalName(), s.In)
+		}
+	}
+	if s.Kind == OAuth2Kind && len(s.Flows) == 0 {
+		verr.Add(parent, "%s%s must define at least one flow", ctx, s.EvalName())
+	}
+	for _, f := range s.Flows {
+		if s.Kind != OAuth2Kind {
+			verr.Add(parent, "%s%s cannot define flows", ctx, s.EvalName())
+			break
+		}
+		switch f.Kind {
+		case AuthorizationCodeFlowKind:
+			if f.AuthorizationURL == "" || f.TokenURL == "" {
+				verr.Add(parent, "%s%s %s flow must define the authorization and token URLs", ctx, s.EvalName(), f.Type())
+			}
+		case ImplicitFlowKind:
+			if f.AuthorizationURL == "" {
+				verr.Add(parent, "%s%s %s flow must define the authorization URL", ctx, s.EvalName(), f.Type())
+			}
+		case PasswordFlowKind, ClientCredentialsFlowKind:
+			if f.TokenURL == "" {
+				verr.Add(parent, "%s%s %s flow must define the token URL", ctx, s.EvalName(), f.Type())
+			}
+		}
+	}
+	if len(s.Scopes) > 0 && s.Kind != OAuth2Kind && s.Kind != JWTKind {
+		verr.Add(parent, "%s%s cannot define scopes", ctx, s.EvalName())
+	}
+	return verr
+}
+
+// String returns the name of the kind.
+func (k SchemeKind) String() string {
+	switch k {
+	case BasicAuthKind:
+		return "Basic"
+	case APIKeyKind:
+		return "APIKey"
+	case JWTKind:
+		return "JWT"
+	case OAuth2Kind:
+		return "OAuth2"
+	case NoKind:
+		return "None"
+	}
+	return "N/A"
+}
+
+// EvalName returns the name of the expression used in error messages.
+func (s *SecurityExpr) EvalName() string {
+	var names []string
+	for _, s := range s.Schemes {
+		names = append(names, s.SchemeName)
+	}
+	return "Security(" + strings.Join(names, ", ") + ")"
+}
+
+// Validate ensures that the scopes of the requirement are declared by its
+// JWT or OAuth2 schemes and that the method payload has the attributes
+// holding the credentials of the schemes, the payload may be nil.
+func (s *SecurityExpr) Validate(ctx string, parent eval.Expression, payload *AttributeExpr) *eval.ValidationErrors {
+	verr := new(eval.ValidationErrors)
+	if ctx != "" {
+		ctx += " - "
+	}
+	for _, scope := range s.Scopes {
+		found := false
+		for _, sch := range s.Schemes {
+			if sch.Scope(scope) != nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			verr.Add(parent, "%s%s: scope %q is not declared by the schemes", ctx, s.EvalName(), scope)
+		}
+	}
+	for _, sch := range s.Schemes {
+		for _, key := range sch.PayloadMeta() {
+			if SecurityField(payload, key) == "" {
+				verr.Add(parent, "%s%s: payload must define an attribute with meta %q", ctx, s.EvalName(), key)
+			}
+		}
+	}
+	return verr
+}
+
+// EvalName returns the name of the expression used in error messages.
+func (f *FlowExpr) EvalName() string {
+	return "flow " + f.Type()
+}
+
+// Type returns the grant type of the OAuth2 grant.
+func (f *FlowExpr) Type() string {
+	switch f.Kind {
+	case AuthorizationCodeFlowKind:
+		return "authorization_code"
+	case ImplicitFlowKind:
+		return "implicit"
+	case PasswordFlowKind:
+		return "password"
+	case ClientCredentialsFlowKind:
+		return "client_credentials"
+	}
+	panic(fmt.Sprintf("unknown flow kind: %#v", f.Kind)) // bug
+}
+
+// SecurityField returns the name of the attribute of the object payload with
+// the given security meta key, an empty string if there isn't any.
+func SecurityField(payload *AttributeExpr, key string) string {
+	if payload == nil {
+		return ""
+	}
+	obj := AsObject(payload.Type)
+	if obj == nil {
+		return ""
+	}
+	for _, nat := range *obj {
+		if _, ok := nat.Attribute.Meta[key]; ok {
+			return nat.Name
+		}
+	}
+	return ""
+}
+
+// Schemes returns the schemes used by the requirements sorted by name, each
+// scheme is listed once.
+func Schemes(reqs ...[]*SecurityExpr) []*SchemeExpr {
+	var (
+		res  []*SchemeExpr
+		seen = make(map[string]bool)
+	)
+	for _, rs := range reqs {
+		for _, r := range rs {
+			for _, s := range r.Schemes {
+				if s.Kind == NoKind || seen[s.SchemeName] {
+					continue
+				}
+				seen[s.SchemeName] = true
+				res = append(res, s)
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].SchemeName < res[j].SchemeName })
+	return res
+}
@@ -0,0 +1,79 @@
+package expr
+
+import (
+	"testing"
+)
+
+func TestSchemeExprValidate(t *testing.T) {
+	var (
+		code     = &FlowExpr{Kind: AuthorizationCodeFlowKind, AuthorizationURL: "/auth", TokenURL: "/token"}
+		implicit = &FlowExpr{Kind: ImplicitFlowKind}
+		scopes   = []*ScopeExpr{{Name: "api:read"}}
+	)
+	cases := map[string]struct {
+		scheme *SchemeExpr
+		errs   int
+	}{
+		"basic":           {&SchemeExpr{Kind: BasicAuthKind, SchemeName: "basic"}, 0},
+		"no name":         {&SchemeExpr{Kind: BasicAuthKind}, 1},
+		"api key":         {&SchemeExpr{Kind: APIKeyKind, SchemeName: "key", In: "query", Name: "k"}, 0},
+		"invalid in":      {&SchemeExpr{Kind: APIKeyKind, SchemeName: "key", In: "body", Name: "k"}, 1},
+		"no key name":     {&SchemeExpr{Kind: APIKeyKind, SchemeName: "key", In: "header"}, 1},
+		"jwt scopes":      {&SchemeExpr{Kind: JWTKind, SchemeName: "jwt", In: "header", Name: "Authorization", Scopes: scopes}, 0},
+		"basic scopes":    {&SchemeExpr{Kind: BasicAuthKind, SchemeName: "basic", Scopes: scopes}, 1},
+		"oauth2":          {&SchemeExpr{Kind: OAuth2Kind, SchemeName: "oauth", In: "header", Name: "Authorization", Flows: []*FlowExpr{code}}, 0},
+		"oauth2 no flow":  {&SchemeExpr{Kind: OAuth2Kind, SchemeName: "oauth", In: "header", Name: "Authorization"}, 1},
+		"flow no url":     {&SchemeExpr{Kind: OAuth2Kind, SchemeName: "oauth", In: "header", Name: "Authorization", Flows: []*FlowExpr{implicit}}, 1},
+		"flow not oauth2": {&SchemeExpr{Kind: JWTKind, SchemeName: "jwt", In: "header", Name: "Authorization", Flows: []*FlowExpr{code}}, 1},
+	}
+	for k, tc := range cases {
+		var n int
+		if verr := tc.scheme.Validate("", nil); verr != nil {
+			n = len(verr.Errors)
+		}
+		if n != tc.errs {
+			t.Errorf("%s: got %d errors, expected %d", k, n, tc.errs)
+		}
+	}
+}
+
+func TestSecurityExprValidate(t *testing.T) {
+	var (
+		basic = &SchemeExpr{Kind: BasicAuthKind, SchemeName: "basic"}
+		jwt   = &SchemeExpr{Kind: JWTKind, SchemeName: "jwt", Scopes: []*ScopeExpr{{Name: "api:read"}}}
+		key   = &SchemeExpr{Kind: APIKeyKind, SchemeName: "key"}
+		login = &AttributeExpr{Type: &Object{
+			{Name: "user", Attribute: &AttributeExpr{Type: String, Meta: MetaExpr{SecurityUsernameMeta: nil}}},
+			{Name: "pass", Attribute: &AttributeExpr{Type: String, Meta: MetaExpr{SecurityPasswordMeta: nil}}},
+			{Name: "token", Attribute: &AttributeExpr{Type: String, Meta: MetaExpr{SecurityTokenMeta: nil}}},
+			{Name: "k", Attribute: &AttributeExpr{Type: String, Meta: MetaExpr{SecurityAPIKeyMeta + "key": nil}}},
+		}}
+	)
+	cases := map[string]struct {
+		sec     *SecurityExpr
+		payload *AttributeExpr
+		errs    int
+	}{
+		"basic":            {&SecurityExpr{Schemes: []*SchemeExpr{basic}}, login, 0},
+		"all schemes":      {&SecurityExpr{Schemes: []*SchemeExpr{basic, jwt, key}, Scopes: []string{"api:read"}}, login, 0},
+		"undeclared scope": {&SecurityExpr{Schemes: []*SchemeExpr{jwt}, Scopes: []string{"api:write"}}, login, 1},
+		"no payload":       {&SecurityExpr{Schemes: []*SchemeExpr{basic}}, nil, 2},
+		"missing key":      {&SecurityExpr{Schemes: []*SchemeExpr{{Kind: APIKeyKind, SchemeName: "other"}}}, login, 1},
+	}
+	for k, tc := range cases {
+		var n int
+		if verr := tc.sec.Validate("", nil, tc.payload); verr != nil {
+			n = len(verr.Errors)
+		}
+		if n != tc.errs {
+			t.Errorf("%s: got %d errors, expected %d", k, n, tc.errs)
+		}
+	}
+	if actual := SecurityField(login, SecurityTokenMeta); actual != "token" {
+		t.Errorf("got security field %q, expected token", actual)
+	}
+	schemes := Schemes([]*SecurityExpr{{Schemes: []*SchemeExpr{key, basic}}}, []*SecurityExpr{{Schemes: []*SchemeExpr{basic}}})
+	if len(schemes) != 2 || schemes[0] != basic || schemes[1] != key {
+		t.Errorf("got schemes %v, expected basic and key", schemes)
+	}
+}
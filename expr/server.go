@@ -0,0 +1,287 @@
+package expr
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.zoe.im/goser/eval"
+)
+
+type (
+	// ServerExpr contains a single API host information.
+	ServerExpr struct {
+		// Name of server
+		Name string
+		// Description of server
+		Description string
+		// Services list the services hosted by the server, all the
+		// services if empty.
+		Services []string
+		// Hosts list the server hosts.
+		Hosts []*HostExpr
+		// Meta is a list of key/value pairs
+		Meta MetaExpr
+	}
+
+	// HostExpr describes a server host.
+	HostExpr struct {
+		// Name of host
+		Name string
+		// Name of server that uses host.
+		ServerName string
+		// Description of host
+		Description string
+		// URIs to host if any, may contain parameter elements using
+		// the "{param}" syntax.
+		URIs []URIExpr
+		// Variables defines the URI variables if any.
+		Variables *AttributeExpr
+		// Meta is a list of key/value pairs
+		Meta MetaExpr
+	}
+
+	// URIExpr represents a parameterized URI.
+	URIExpr string
+)
+
+// URIParamsRegex is the regular expression used to capture the parameters
+// present in a URI.
+var URIParamsRegex = regexp.MustCompile(`{([^{}]+)}`)
+
+// EvalName is the qualified name of the expression.
+func (s *ServerExpr) EvalName() string { return "Server " + s.Name }
+
+// Schemes returns the list of transport schemes used by all the server
+// endpoints. The possible values for the elements of the returned slice are
+// "http", "https", "grpc" and "grpcs".
+func (s *ServerExpr) Schemes() []string {
+	schemes := make(map[string]struct{})
+	for _, h := range s.Hosts {
+		for _, sch := range h.Schemes() {
+			schemes[sch] = struct{}{}
+		}
+	}
+	ss := make([]string, len(schemes))
+	i := 0
+	for s := range schemes {
+		ss[i] = s
+		i++
+	}
+	sort.Strings(ss)
+	return ss
+}
+
+// Host returns the host with the given name, nil if there isn't any.
+func (s *ServerExpr) Host(name string) *HostExpr {
+	for _, h := range s.Hosts {
+		if h.Name == name {
+			return h
+		}
+	}
+	return nil
+}
+
+// Validate validates the server and its hosts.
+func (s *ServerExpr) Validate(ctx string, parent eval.Expression) *eval.ValidationErrors {
+	verr := new(eval.ValidationErrors)
+	if ctx != "" {
+		ctx += " - "
+	}
+	if s.Name == "" {
+		verr.Add(parent, "%sserver name cannot be empty", ctx)
+	}
+	if len(s.Hosts) == 0 {
+		verr.Add(parent, "%s%s must define at least one host", ctx, s.EvalName())
+	}
+	seen := make(map[string]bool, len(s.Hosts))
+	for _, h := range s.Hosts {
+		if seen[h.Name] {
+			verr.Add(parent, "%s%s defines host %q more than once", ctx, s.EvalName(), h.Name)
+		}
+		seen[h.Name] = true
+		verr.Merge(h.Validate(ctx+s.EvalName(), parent))
+	}
+	return verr
+}
+
+// EvalName returns the name returned in error messages.
+func (h *HostExpr) EvalName() string {
+	return fmt.Sprintf("host %q of server %q", h.Name, h.ServerName)
+}
+
+// Schemes returns the list of transport schemes defined for the host. The
+// possible values for the elements of the returned slice are "http",
+// "https", "grpc" and "grpcs".
+func (h *HostExpr) Schemes() []string {
+	schemes := make(map[string]struct{})
+	for _, uri := range h.URIs {
+		if s := uri.Scheme(); s != "" {
+			schemes[s] = struct{}{}
+		}
+	}
+	ss := make([]string, len(schemes))
+	i := 0
+	for s := range schemes {
+		ss[i] = s
+		i++
+	}
+	sort.Strings(ss)
+	return ss
+}
+
+// Variable returns the variable of the host with the given name, nil if there
+// isn't any.
+func (h *HostExpr) Variable(name string) *AttributeExpr {
+	if h.Variables == nil {
+		return nil
+	}
+	obj := AsObject(h.Variables.Type)
+	if obj == nil {
+		return nil
+	}
+	return obj.Attribute(name)
+}
+
+// DefaultVariableValues returns the default value of the variables of the
+// host, the first enum value is used if the variable has no default.
+func (h *HostExpr) DefaultVariableValues() map[string]interface{} {
+	res := make(map[string]interface{})
+	if h.Variables == nil {
+		return res
+	}
+	obj := AsObject(h.Variables.Type)
+	if obj == nil {
+		return res
+	}
+	for _, nat := range *obj {
+		switch {
+		case nat.Attribute.DefaultValue != nil:
+			res[nat.Name] = nat.Attribute.DefaultValue
+		case nat.Attribute.Validation != nil && len(nat.Attribute.Validation.Values) > 0:
+			res[nat.Name] = nat.Attribute.Validation.Values[0]
+		}
+	}
+	return res
+}
+
+// URIString returns the URI of the host with the variables replaced by the
+// given values or their default values otherwise. It returns an error if a
+// variable has no value or if a value is not one of the variable enum values.
+func (h *HostExpr) URIString(uri URIExpr, values map[string]string) (string, error) {
+	defaults := h.DefaultVariableValues()
+	var err error
+	res := URIParamsRegex.ReplaceAllStringFunc(string(uri), func(m string) string {
+		name := m[1 : len(m)-1]
+		v, ok := values[name]
+		if !ok {
+			def, ok := defaults[name]
+			if !ok {
+				if err == nil {
+					err = fmt.Errorf("%s: no value for variable %q", h.EvalName(), name)
+				}
+				return m
+			}
+			return fmt.Sprintf("%v", def)
+		}
+		if att := h.Variable(name); att != nil && att.Validation != nil && len(att.Validation.Values) > 0 {
+			found := false
+			for _, e := range att.Validation.Values {
+				if fmt.Sprintf("%v", e) == v {
+					found = true
+					break
+				}
+			}
+			if !found && err == nil {
+				err = fmt.Errorf("%s: value %q of variable %q must be one of %v", h.EvalName(), v, name, att.Validation.Values)
+			}
+		}
+		return v
+	})
+	return res, err
+}
+
+// Validate validates the host: it must define at least one URI, the URI
+// schemes must be http, https, grpc or grpcs and the parameters of the URIs
+// must be variables with a default value or an enum validation.
+func (h *HostExpr) Validate(ctx string, parent eval.Expression) *eval.ValidationErrors {
+	verr := new(eval.ValidationErrors)
+	if ctx != "" {
+		ctx += " - "
+	}
+	if h.Name == "" {
+		verr.Add(parent, "%shost name cannot be empty", ctx)
+	}
+	if len(h.URIs) == 0 {
+		verr.Add(parent, "%s%s must define at least one URI", ctx, h.EvalName())
+	}
+	for _, uri := range h.URIs {
+		switch uri.Scheme() {
+		case "http", "https", "grpc", "grpcs":
+		default:
+			verr.Add(parent, "%s%s: URI %q scheme must be one of http, https, grpc or grpcs", ctx, h.EvalName(), uri)
+			continue
+		}
+		for _, p := range uri.Params() {
+			att := h.Variable(p)
+			if att == nil {
+				verr.Add(parent, "%s%s: URI %q parameter %q is not defined as a variable", ctx, h.EvalName(), uri, p)
+				continue
+			}
+			if att.DefaultValue == nil && (att.Validation == nil || len(att.Validation.Values) == 0) {
+				verr.Add(parent, "%s%s: variable %q must have a default value or an enum validation", ctx, h.EvalName(), p)
+			}
+		}
+		if len(uri.Params()) == 0 {
+			if _, err := url.Parse(string(uri)); err != nil {
+				verr.Add(parent, "%s%s: invalid URI %q: %v", ctx, h.EvalName(), uri, err)
+			}
+		}
+	}
+	if h.Variables != nil {
+		if obj := AsObject(h.Variables.Type); obj != nil {
+			for _, nat := range *obj {
+				if !IsPrimitive(nat.Attribute.Type) {
+					verr.Add(parent, "%s%s: variable %q must be a primitive", ctx, h.EvalName(), nat.Name)
+				}
+			}
+		}
+	}
+	return verr
+}
+
+// Params return the names of the parameters used in URI if any.
+func (u URIExpr) Params() []string {
+	r := URIParamsRegex.FindAllStringSubmatch(string(u), -1)
+	if len(r) == 0 {
+		return nil
+	}
+	params := make([]string, len(r))
+	for i, w := range r {
+		params[i] = w[1]
+	}
+	return params
+}
+
+// Scheme returns the URI scheme, an empty string if the URI has none.
+func (u URIExpr) Scheme() string {
+	s := string(u)
+	if i := strings.Index(s, "://"); i > 0 {
+		return strings.ToLower(s[:i])
+	}
+	return ""
+}
+
+// Transport returns the transport of the URI, "http" for the http and https
+// schemes and "grpc" for the grpc and grpcs schemes.
+func (u URIExpr) Transport() string {
+	switch u.Scheme() {
+	case "http", "https":
+		return "http"
+	case "grpc", "grpcs":
+		return "grpc"
+	}
+	return ""
+}
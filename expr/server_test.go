@@ -0,0 +1,77 @@
+package expr
+
+import (
+	"testing"
+)
+
+func TestHostExprValidate(t *testing.T) {
+	vars := func(atts ...*NamedAttributeExpr) *AttributeExpr {
+		obj := Object(atts)
+		return &AttributeExpr{Type: &obj}
+	}
+	var (
+		withDefault = &NamedAttributeExpr{Name: "version", Attribute: &AttributeExpr{Type: String, DefaultValue: "v1"}}
+		withEnum    = &NamedAttributeExpr{Name: "version", Attribute: &AttributeExpr{Type: String, Validation: &ValidationExpr{Values: []interface{}{"v1", "v2"}}}}
+		noDefault   = &NamedAttributeExpr{Name: "version", Attribute: &AttributeExpr{Type: String}}
+		notPrim     = &NamedAttributeExpr{Name: "version", Attribute: &AttributeExpr{Type: &Array{ElemType: &AttributeExpr{Type: String}}, DefaultValue: []interface{}{}}}
+	)
+	cases := map[string]struct {
+		uris []URIExpr
+		vars *AttributeExpr
+		errs int
+	}{
+		"valid":          {[]URIExpr{"http://localhost:80/calc", "grpc://localhost:8080"}, nil, 0},
+		"no uri":         {nil, nil, 1},
+		"invalid scheme": {[]URIExpr{"ftp://localhost"}, nil, 1},
+		"no scheme":      {[]URIExpr{"localhost:80"}, nil, 1},
+		"default":        {[]URIExpr{"https://{version}.goser.zoe.im"}, vars(withDefault), 0},
+		"enum":           {[]URIExpr{"https://{version}.goser.zoe.im"}, vars(withEnum), 0},
+		"no variable":    {[]URIExpr{"https://{version}.goser.zoe.im"}, nil, 1},
+		"no default":     {[]URIExpr{"https://{version}.goser.zoe.im"}, vars(noDefault), 1},
+		"not primitive":  {[]URIExpr{"https://{version}.goser.zoe.im"}, vars(notPrim), 1},
+	}
+	for k, tc := range cases {
+		h := &HostExpr{Name: "dev", ServerName: "svr", URIs: tc.uris, Variables: tc.vars}
+		var n int
+		if verr := h.Validate("", nil); verr != nil {
+			n = len(verr.Errors)
+		}
+		if n != tc.errs {
+			t.Errorf("%s: got %d errors, expected %d", k, n, tc.errs)
+		}
+	}
+}
+
+func TestHostExprURIString(t *testing.T) {
+	obj := Object{
+		{Name: "version", Attribute: &AttributeExpr{Type: String, DefaultValue: "v1"}},
+		{Name: "region", Attribute: &AttributeExpr{Type: String, Validation: &ValidationExpr{Values: []interface{}{"us", "eu"}}}},
+	}
+	h := &HostExpr{Name: "prod", ServerName: "svr", Variables: &AttributeExpr{Type: &obj}}
+	uri := URIExpr("https://{version}.{region}.goser.zoe.im/calc")
+
+	cases := map[string]struct {
+		values   map[string]string
+		expected string
+		err      bool
+	}{
+		"defaults": {nil, "https://v1.us.goser.zoe.im/calc", false},
+		"values":   {map[string]string{"version": "v2", "region": "eu"}, "https://v2.eu.goser.zoe.im/calc", false},
+		"not enum": {map[string]string{"region": "asia"}, "https://v1.asia.goser.zoe.im/calc", true},
+	}
+	for k, tc := range cases {
+		actual, err := h.URIString(uri, tc.values)
+		if actual != tc.expected {
+			t.Errorf("%s: got %q, expected %q", k, actual, tc.expected)
+		}
+		if (err != nil) != tc.err {
+			t.Errorf("%s: got error %v, expected error %t", k, err, tc.err)
+		}
+	}
+	if params := uri.Params(); len(params) != 2 || params[0] != "version" || params[1] != "region" {
+		t.Errorf("got params %v, expected [version region]", params)
+	}
+	if tr := URIExpr("grpcs://localhost").Transport(); tr != "grpc" {
+		t.Errorf("got transport %q, expected grpc", tr)
+	}
+}
@@ -0,0 +1,163 @@
+package expr
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Pick creates a user type with the given name made of the given fields of the
+// object type t. The descriptions, validations and meta (e.g. "rpc:tag") of
+// the fields are kept, the required fields are restricted to the picked ones.
+func Pick(name string, t DataType, fields ...string) (*UserTypeExpr, error) {
+	ut, err := derive(name, t)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFields(t, fields); err != nil {
+		return nil, err
+	}
+	keep := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		keep[f] = true
+	}
+	for _, nat := range *AsObject(t) {
+		if !keep[nat.Name] {
+			ut.Delete(nat.Name)
+		}
+	}
+	return ut, nil
+}
+
+// Omit creates a user type with the given name made of the fields of the
+// object type t except the given ones. See Pick.
+func Omit(name string, t DataType, fields ...string) (*UserTypeExpr, error) {
+	ut, err := derive(name, t)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFields(t, fields); err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		ut.Delete(f)
+	}
+	return ut, nil
+}
+
+// Partial creates a user type with the given name made of the fields of the
+// object type t, none of them being required.
+func Partial(name string, t DataType) (*UserTypeExpr, error) {
+	ut, err := derive(name, t)
+	if err != nil {
+		return nil, err
+	}
+	if ut.Validation != nil {
+		ut.Validation.Required = nil
+	}
+	return ut, nil
+}
+
+// RequiredAll creates a user type with the given name made of the fields of
+// the object type t, all of them being required.
+func RequiredAll(name string, t DataType) (*UserTypeExpr, error) {
+	ut, err := derive(name, t)
+	if err != nil {
+		return nil, err
+	}
+	if ut.Validation == nil {
+		ut.Validation = &ValidationExpr{}
+	}
+	for _, nat := range *AsObject(ut) {
+		ut.Validation.AddRequired(nat.Name)
+	}
+	return ut, nil
+}
+
+// RenameFields creates a user type with the given name made of the fields of
+// the object type t, the fields are renamed using the names map which maps
+// the old names to the new ones.
+func RenameFields(name string, t DataType, names map[string]string) (*UserTypeExpr, error) {
+	ut, err := derive(name, t)
+	if err != nil {
+		return nil, err
+	}
+	old := make([]string, 0, len(names))
+	for n := range names {
+		old = append(old, n)
+	}
+	sort.Strings(old)
+	if err := checkFields(t, old); err != nil {
+		return nil, err
+	}
+	obj := AsObject(ut)
+	for _, nat := range *obj {
+		if n, ok := names[nat.Name]; ok {
+			nat.Name = n
+		}
+	}
+	if ut.Validation != nil {
+		for i, r := range ut.Validation.Required {
+			if n, ok := names[r]; ok {
+				ut.Validation.Required[i] = n
+			}
+		}
+	}
+	return ut, nil
+}
+
+// derive creates a user type with the given name and a copy of the attribute
+// of the object type t. The fields are copied so that they can be modified
+// without affecting t but their types are shared.
+func derive(name string, t DataType) (*UserTypeExpr, error) {
+	obj := AsObject(t)
+	if obj == nil {
+		return nil, fmt.Errorf("cannot derive %s from %s: only object types can be transformed", name, typeName(t))
+	}
+	src := &AttributeExpr{Type: t}
+	if ut, ok := t.(UserType); ok {
+		src = ut.Attribute()
+	}
+	// the fields of the bases must be merged before being transformed
+	if err := src.ResolveBases(); err != nil {
+		return nil, fmt.Errorf("cannot derive %s from %s: %v", name, typeName(t), err)
+	}
+	obj = AsObject(t)
+	att := copyAttribute(src)
+	att.Bases, att.References = nil, nil
+	// examples of the source type may not match the derived type
+	att.UserExamples = nil
+	res := make(Object, len(*obj))
+	for i, nat := range *obj {
+		res[i] = &NamedAttributeExpr{Name: nat.Name, Attribute: copyAttribute(nat.Attribute)}
+	}
+	att.Type = &res
+	return &UserTypeExpr{TypeName: name, AttributeExpr: att}, nil
+}
+
+// checkFields returns an error if the object type t doesn't have one of the
+// given fields.
+func checkFields(t DataType, fields []string) error {
+	obj := AsObject(t)
+	for _, f := range fields {
+		if obj.Attribute(f) == nil {
+			return fmt.Errorf("type %s has no field %q", typeName(t), f)
+		}
+	}
+	return nil
+}
+
+// copyAttribute returns a shallow copy of the attribute with its own
+// validations and meta.
+func copyAttribute(att *AttributeExpr) *AttributeExpr {
+	cp := *att
+	if att.Validation != nil {
+		cp.Validation = att.Validation.Dup()
+	}
+	if att.Meta != nil {
+		cp.Meta = make(MetaExpr, len(att.Meta))
+		for k, v := range att.Meta {
+			cp.Meta[k] = append([]string(nil), v...)
+		}
+	}
+	return &cp
+}
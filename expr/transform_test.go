@@ -0,0 +1,90 @@
+package expr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	var (
+		max  = 10
+		user = &UserTypeExpr{TypeName: "User", AttributeExpr: &AttributeExpr{
+			Description: "A user",
+			Type: &Object{
+				{"id", &AttributeExpr{Type: String, Meta: MetaExpr{"rpc:tag": []string{"1"}}}},
+				{"name", &AttributeExpr{Type: String, Description: "Name", Validation: &ValidationExpr{MaxLength: &max}, Meta: MetaExpr{"rpc:tag": []string{"2"}}}},
+				{"age", &AttributeExpr{Type: Int32, Meta: MetaExpr{"rpc:tag": []string{"3"}}}},
+			},
+			Validation: &ValidationExpr{Required: []string{"id", "name"}},
+		}}
+		names = func(ut *UserTypeExpr) []string {
+			var res []string
+			for _, nat := range *AsObject(ut) {
+				res = append(res, nat.Name)
+			}
+			return res
+		}
+		required = func(ut *UserTypeExpr) []string {
+			if ut.Validation == nil {
+				return nil
+			}
+			return ut.Validation.Required
+		}
+	)
+	pick := func() (*UserTypeExpr, error) { return Pick("UserName", user, "name") }
+	omit := func() (*UserTypeExpr, error) { return Omit("CreateUserPayload", user, "id") }
+	partial := func() (*UserTypeExpr, error) { return Partial("UpdateUserPayload", user) }
+	all := func() (*UserTypeExpr, error) { return RequiredAll("FullUser", user) }
+	rename := func() (*UserTypeExpr, error) {
+		return RenameFields("Person", user, map[string]string{"name": "full_name"})
+	}
+	cases := map[string]struct {
+		fn       func() (*UserTypeExpr, error)
+		fields   []string
+		required []string
+	}{
+		"pick":         {pick, []string{"name"}, []string{"name"}},
+		"omit":         {omit, []string{"name", "age"}, []string{"name"}},
+		"partial":      {partial, []string{"id", "name", "age"}, nil},
+		"required all": {all, []string{"id", "name", "age"}, []string{"id", "name", "age"}},
+		"rename":       {rename, []string{"id", "full_name", "age"}, []string{"id", "full_name"}},
+	}
+	for k, tc := range cases {
+		ut, err := tc.fn()
+		if err != nil {
+			t.Errorf("%s: %v", k, err)
+			continue
+		}
+		if actual := names(ut); !reflect.DeepEqual(actual, tc.fields) {
+			t.Errorf("%s: got fields %v, expected %v", k, actual, tc.fields)
+		}
+		if actual := required(ut); len(actual) > 0 || len(tc.required) > 0 {
+			if !reflect.DeepEqual(actual, tc.required) {
+				t.Errorf("%s: got required %v, expected %v", k, actual, tc.required)
+			}
+		}
+		if ut.Description != "A user" {
+			t.Errorf("%s: got description %q, expected it to be kept", k, ut.Description)
+		}
+		for _, nat := range *AsObject(ut) {
+			if _, ok := nat.Attribute.Meta["rpc:tag"]; !ok {
+				t.Errorf("%s: got no rpc:tag for field %s, expected it to be kept", k, nat.Name)
+			}
+		}
+	}
+
+	// the source type is left untouched
+	if actual := names(user); !reflect.DeepEqual(actual, []string{"id", "name", "age"}) {
+		t.Errorf("got fields %v, expected source type to be unchanged", actual)
+	}
+	if actual := required(user); !reflect.DeepEqual(actual, []string{"id", "name"}) {
+		t.Errorf("got required %v, expected source type to be unchanged", actual)
+	}
+
+	if _, err := Pick("Bad", user, "email"); err == nil || err.Error() != `type User has no field "email"` {
+		t.Errorf("got %v, expected unknown field error", err)
+	}
+	if _, err := Partial("Bad", String); err == nil {
+		t.Errorf("got no error, expected error for non object type")
+	}
+}
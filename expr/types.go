@@ -1,8 +1,13 @@
 package expr
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
+	"regexp"
+	"strings"
+	"time"
 
 	"go.zoe.im/goser/eval"
 )
@@ -53,6 +58,21 @@ type (
 	// Note: not a map because order matters.
 	Object []*NamedAttributeExpr
 
+	// Union is the type used to describe values which may be one of
+	// several alternative types (oneOf).
+	Union struct {
+		// TypeName is the name of the union, empty for inline unions.
+		TypeName string
+		// Values lists the alternatives, the names are used to identify
+		// the alternatives in the generated code and by the
+		// discriminator.
+		Values []*NamedAttributeExpr
+		// Discriminator is the name of the field holding the name of the
+		// alternative in serialized values. Alternatives must be objects
+		// if set, the alternative is inferred from the value otherwise.
+		Discriminator string
+	}
+
 	// UserType is the interface implemented by all user type
 	// implementations. Plugins may leverage this interface to introduce
 	// their own types.
@@ -119,6 +139,19 @@ const (
 	ResultTypeKind
 	// AnyKind represents an unknown type.
 	AnyKind
+	// TimestampKind represents an instant in time.
+	TimestampKind
+	// DateKind represents a calendar date.
+	DateKind
+	// DurationKind represents an elapsed time.
+	DurationKind
+	// UnionKind represents a value which is one of several alternative
+	// types.
+	UnionKind
+	// DecimalKind represents an exact decimal number.
+	DecimalKind
+	// BigIntKind represents an arbitrary-precision integer.
+	BigIntKind
 )
 
 const (
@@ -157,6 +190,31 @@ const (
 
 	// Any is the type for an arbitrary JSON value (interface{} in Go).
 	Any = Primitive(AnyKind)
+
+	// Timestamp is the type for an instant in time, represented as a RFC3339
+	// date time string in JSON (time.Time in Go).
+	Timestamp = Primitive(TimestampKind)
+
+	// Date is the type for a calendar date, represented as a RFC3339 full
+	// date string in JSON (time.Time in Go).
+	Date = Primitive(DateKind)
+
+	// Duration is the type for an elapsed time, represented as a duration
+	// string such as "1h30m" in JSON (time.Duration in Go).
+	Duration = Primitive(DurationKind)
+
+	// Decimal is the type for an exact decimal number, represented as a
+	// string such as "12.50" in JSON to avoid losing precision.
+	Decimal = Primitive(DecimalKind)
+
+	// BigInt is the type for an arbitrary-precision integer, represented as
+	// a string of digits in JSON.
+	BigInt = Primitive(BigIntKind)
+)
+
+var (
+	decimalRegex = regexp.MustCompile(`^[-+]?[0-9]+(\.[0-9]+)?$`)
+	bigIntRegex  = regexp.MustCompile(`^[-+]?[0-9]+$`)
 )
 
 // Built-in composite types
@@ -214,6 +272,20 @@ func AsMap(dt DataType) *Map {
 	}
 }
 
+// AsUnion returns the type underlying union if any, nil otherwise.
+func AsUnion(dt DataType) *Union {
+	switch t := dt.(type) {
+	case *UserTypeExpr:
+		return AsUnion(t.Type)
+	case *ResultTypeExpr:
+		return AsUnion(t.Type)
+	case *Union:
+		return t
+	default:
+		return nil
+	}
+}
+
 // IsObject returns true if the data type is an object.
 func IsObject(dt DataType) bool { return AsObject(dt) != nil }
 
@@ -223,6 +295,9 @@ func IsArray(dt DataType) bool { return AsArray(dt) != nil }
 // IsMap returns true if the data type is a map.
 func IsMap(dt DataType) bool { return AsMap(dt) != nil }
 
+// IsUnion returns true if the data type is a union.
+func IsUnion(dt DataType) bool { return AsUnion(dt) != nil }
+
 // IsPrimitive returns true if the data type is a primitive type.
 func IsPrimitive(dt DataType) bool {
 	switch t := dt.(type) {
@@ -232,6 +307,8 @@ func IsPrimitive(dt DataType) bool {
 		return IsPrimitive(t.Type)
 	case *ResultTypeExpr:
 		return IsPrimitive(t.Type)
+	case *EnumTypeExpr:
+		return true
 	default:
 		return false
 	}
@@ -244,6 +321,8 @@ func IsPrimitive(dt DataType) bool {
 //    - array types have elements whose types are equal
 //    - map types have keys and elements whose types are equal
 //    - objects have the same attribute names and the attribute types are equal
+//    - unions have the same discriminator and alternatives whose names and
+//      types are equal
 //
 // Note: calling Equal is not equivalent to evaluation dt.Hash() == dt2.Hash()
 // as the former may return true for two user types with different names and
@@ -293,6 +372,19 @@ func equal(dt, dt2 DataType, seen ...map[string]*[]*bool) *[]*bool {
 			bs = append(bs, *equal(nat.Attribute.Type, at.Type, s)...)
 		}
 		return &bs
+	case *Union:
+		other := AsUnion(dt2)
+		if len(actual.Values) != len(other.Values) || actual.Discriminator != other.Discriminator {
+			return &fs
+		}
+		var bs []*bool
+		for i, nat := range actual.Values {
+			if nat.Name != other.Values[i].Name {
+				return &fs
+			}
+			bs = append(bs, *equal(nat.Attribute.Type, other.Values[i].Attribute.Type, s)...)
+		}
+		return &bs
 	case UserType:
 		key := actual.Name() + "=" + dt2.Name()
 		if v, ok := s[key]; ok {
@@ -304,8 +396,13 @@ func equal(dt, dt2 DataType, seen ...map[string]*[]*bool) *[]*bool {
 		if IsObject(actual) {
 			*pres = *equal(AsObject(dt), AsObject(dt2), s)
 		} else {
-			// User types can also be arrays (CollectionOf)
-			*pres = *equal(AsArray(dt), AsArray(dt2), s)
+			// User types can also be arrays (CollectionOf), enums or
+			// aliases of primitives
+			other := dt2
+			if ut, ok := dt2.(UserType); ok {
+				other = ut.Attribute().Type
+			}
+			*pres = *equal(actual.Attribute().Type, other, s)
 		}
 		return pres
 	}
@@ -347,6 +444,16 @@ func (p Primitive) Name() string {
 		return "bytes"
 	case Any:
 		return "any"
+	case Timestamp:
+		return "timestamp"
+	case Date:
+		return "date"
+	case Duration:
+		return "duration"
+	case Decimal:
+		return "decimal"
+	case BigInt:
+		return "bigint"
 	default:
 		panic("unknown primitive type") // bug
 	}
@@ -357,6 +464,12 @@ func (p Primitive) IsCompatible(val interface{}) bool {
 	if p == Any {
 		return true
 	}
+	switch p {
+	case Timestamp, Date, Duration:
+		return isTimeCompatible(p, val)
+	case Decimal, BigInt:
+		return isBigCompatible(p, val)
+	}
 	switch val.(type) {
 	case bool:
 		return p == Boolean
@@ -396,11 +509,78 @@ func (p Primitive) Example(r *Random) interface{} {
 		return r.String()
 	case Bytes:
 		return []byte(r.String())
+	case Timestamp:
+		s, _ := r.Format(FormatDateTime)
+		return s
+	case Date:
+		s, _ := r.Format(FormatDate)
+		return s
+	case Duration:
+		return (time.Duration(r.Intn(24*3600)) * time.Second).String()
+	case Decimal:
+		return fmt.Sprintf("%d.%02d", r.Intn(10000), r.Intn(100))
+	case BigInt:
+		return fmt.Sprintf("%d%06d", r.UInt64(), r.Intn(1000000))
 	default:
 		panic("unknown primitive type") // bug
 	}
 }
 
+// isTimeCompatible returns true if val is compatible with the time primitive
+// p: time.Time or a RFC3339 string for timestamps and dates, time.Duration,
+// a duration string or a number of nanoseconds for durations.
+func isTimeCompatible(p Primitive, val interface{}) bool {
+	switch v := val.(type) {
+	case time.Time:
+		return p == Timestamp || p == Date
+	case time.Duration:
+		return p == Duration
+	case int, int32, int64:
+		return p == Duration
+	case string:
+		var err error
+		switch p {
+		case Timestamp:
+			_, err = time.Parse(time.RFC3339, v)
+		case Date:
+			_, err = time.Parse("2006-01-02", v)
+		case Duration:
+			_, err = time.ParseDuration(v)
+		}
+		return err == nil
+	}
+	return false
+}
+
+// isBigCompatible returns true if val is compatible with the decimal or big
+// integer primitive p: a string or json.Number holding a decimal (resp.
+// integer) number, a Go integer, a float for decimals, *big.Int or, for
+// decimals, *big.Float and *big.Rat.
+func isBigCompatible(p Primitive, val interface{}) bool {
+	switch v := val.(type) {
+	case string:
+		return isBigString(p, v)
+	case json.Number:
+		return isBigString(p, string(v))
+	case *big.Int:
+		return v != nil
+	case *big.Float, *big.Rat, float32, float64:
+		return p == Decimal
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	}
+	return false
+}
+
+// isBigString returns true if s is a decimal number for decimals or an integer
+// for big integers.
+func isBigString(p Primitive, s string) bool {
+	if p == BigInt {
+		return bigIntRegex.MatchString(s)
+	}
+	return decimalRegex.MatchString(s)
+}
+
 // Hash returns a unique hash value for p.
 func (p Primitive) Hash() string {
 	return p.Name()
@@ -546,11 +726,14 @@ func (o *Object) Hash() string {
 // with duplicates of the named attributes of other. Named attributes of o that
 // have an identical name to named attributes of other get overridden.
 func (o *Object) Merge(other *Object) *Object {
-	res := o
+	res := make(Object, len(*o))
+	for i, nat := range *o {
+		res[i] = &NamedAttributeExpr{Name: nat.Name, Attribute: nat.Attribute}
+	}
 	for _, nat := range *other {
 		res.Set(nat.Name, DupAtt(nat.Attribute))
 	}
-	return res
+	return &res
 }
 
 // IsCompatible returns true if o describes the (Go) type of val.
@@ -616,6 +799,78 @@ func (m *Map) Example(r *Random) interface{} {
 	return m.MakeMap(pair)
 }
 
+// Kind implements DataKind.
+func (u *Union) Kind() Kind { return UnionKind }
+
+// Name returns the type name.
+func (u *Union) Name() string {
+	if u.TypeName != "" {
+		return u.TypeName
+	}
+	return "union"
+}
+
+// Hash returns a unique hash value for u.
+func (u *Union) Hash() string {
+	h := "_union_+" + u.Discriminator
+	for _, nat := range u.Values {
+		h += "+" + nat.Name + "/" + nat.Attribute.Type.Hash()
+	}
+	return h
+}
+
+// Alternative returns the alternative with the given name, nil if there is
+// none.
+func (u *Union) Alternative(name string) *AttributeExpr {
+	for _, nat := range u.Values {
+		if nat.Name == name {
+			return nat.Attribute
+		}
+	}
+	return nil
+}
+
+// IsCompatible returns true if val is compatible with one of the alternatives,
+// the alternative is given by the discriminator field of val if any.
+func (u *Union) IsCompatible(val interface{}) bool {
+	if u.Discriminator != "" {
+		fields, ok := objectFields(val)
+		if !ok {
+			return false
+		}
+		name, _ := fields[u.Discriminator].(string)
+		att := u.Alternative(name)
+		return att != nil && att.Type.IsCompatible(val)
+	}
+	for _, nat := range u.Values {
+		if nat.Attribute.Type.IsCompatible(val) {
+			return true
+		}
+	}
+	return false
+}
+
+// Example returns a random value of one of the alternatives, the name of the
+// alternative is set in the discriminator field of the value if any.
+func (u *Union) Example(r *Random) interface{} {
+	if len(u.Values) == 0 {
+		return nil
+	}
+	nat := u.Values[r.Intn(len(u.Values))]
+	ex := nat.Attribute.Example(r)
+	if u.Discriminator == "" {
+		return ex
+	}
+	res := make(map[string]interface{})
+	if fields, ok := objectFields(ex); ok {
+		for k, v := range fields {
+			res[k] = v
+		}
+	}
+	res[u.Discriminator] = nat.Name
+	return res
+}
+
 // MakeMap examines the key type from a Map and create a map with builtin type
 // if possible. The idea is to avoid generating map[interface{}]interface{},
 // which cannot be handled by json.Marshal.
@@ -651,9 +906,20 @@ func (m MapVal) ToMap() map[interface{}]interface{} {
 //     "array<string>"
 //     "map<string, string>"
 //     "map<string, array<int32>>"
+//     "union<Card, Bank>"
 //
 func QualifiedTypeName(t DataType) string {
 	switch t.Kind() {
+	case UnionKind:
+		u := t.(*Union)
+		if u.TypeName != "" {
+			return u.TypeName
+		}
+		names := make([]string, len(u.Values))
+		for i, nat := range u.Values {
+			names[i] = QualifiedTypeName(nat.Attribute.Type)
+		}
+		return fmt.Sprintf("%s<%s>", t.Name(), strings.Join(names, ", "))
 	case ArrayKind:
 		a := t.(*Array)
 		return fmt.Sprintf("%s<%s>",
@@ -684,7 +950,9 @@ func toReflectType(dtype DataType) reflect.Type {
 		return reflect.TypeOf(float32(0))
 	case Float64Kind:
 		return reflect.TypeOf(float64(0))
-	case StringKind:
+	case StringKind, TimestampKind, DateKind, DurationKind, DecimalKind, BigIntKind:
+		// time values and big numbers are represented as strings in
+		// examples
 		return reflect.TypeOf("")
 	case BytesKind:
 		return reflect.TypeOf([]byte{})
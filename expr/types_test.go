@@ -1,6 +1,11 @@
 package expr
 
-import "testing"
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+)
 
 func TestAsObject(t *testing.T) {
 	var (
@@ -1092,4 +1097,139 @@ func TestQualifiedTypeName(t *testing.T) {
 			t.Errorf("%s: got %#v, expected %#v", k, actual, tc.expected)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestTimePrimitiveIsCompatible(t *testing.T) {
+	cases := map[string]struct {
+		typ      Primitive
+		value    interface{}
+		expected bool
+	}{
+		"timestamp time":          {Timestamp, time.Now(), true},
+		"timestamp string":        {Timestamp, "2019-10-12T07:20:50.52Z", true},
+		"timestamp invalid":       {Timestamp, "2019-10-12", false},
+		"date string":             {Date, "2019-10-12", true},
+		"date invalid":            {Date, "2019-10-12T07:20:50Z", false},
+		"duration":                {Duration, time.Second, true},
+		"duration string":         {Duration, "1h30m", true},
+		"duration nanoseconds":    {Duration, int64(1000), true},
+		"duration invalid":        {Duration, "1 hour", false},
+		"string is not timestamp": {String, time.Now(), false},
+	}
+	for k, tc := range cases {
+		if actual := tc.typ.IsCompatible(tc.value); actual != tc.expected {
+			t.Errorf("%s: got %#v, expected %#v", k, actual, tc.expected)
+		}
+	}
+}
+
+func TestTimePrimitiveExample(t *testing.T) {
+	r := NewRandom("time")
+	for _, p := range []Primitive{Timestamp, Date, Duration} {
+		ex := p.Example(r)
+		if !p.IsCompatible(ex) {
+			t.Errorf("%s: got incompatible example %#v", p.Name(), ex)
+		}
+		arr := &Array{ElemType: &AttributeExpr{Type: p}}
+		if ex := arr.Example(r); !arr.IsCompatible(ex) {
+			t.Errorf("array<%s>: got incompatible example %#v", p.Name(), ex)
+		}
+	}
+}
+
+func testUnion(discriminator string) *Union {
+	card := &UserTypeExpr{TypeName: "Card", AttributeExpr: &AttributeExpr{
+		Type:       &Object{{"number", &AttributeExpr{Type: String}}},
+		Validation: &ValidationExpr{Required: []string{"number"}},
+	}}
+	bank := &UserTypeExpr{TypeName: "Bank", AttributeExpr: &AttributeExpr{
+		Type:       &Object{{"iban", &AttributeExpr{Type: String}}},
+		Validation: &ValidationExpr{Required: []string{"iban"}},
+	}}
+	return &Union{
+		TypeName:      "PaymentMethod",
+		Discriminator: discriminator,
+		Values: []*NamedAttributeExpr{
+			{"card", &AttributeExpr{Type: card}},
+			{"bank", &AttributeExpr{Type: bank}},
+		},
+	}
+}
+
+func TestUnion(t *testing.T) {
+	var (
+		tagged   = testUnion("type")
+		untagged = testUnion("")
+		inline   = &Union{Values: []*NamedAttributeExpr{
+			{"name", &AttributeExpr{Type: String}},
+			{"ids", &AttributeExpr{Type: &Array{ElemType: &AttributeExpr{Type: Int}}}},
+		}}
+	)
+	if !Equal(tagged, testUnion("type")) {
+		t.Errorf("got different unions, expected equal")
+	}
+	if Equal(tagged, untagged) {
+		t.Errorf("got equal unions with different discriminators, expected different")
+	}
+	if tagged.Hash() == untagged.Hash() {
+		t.Errorf("got same hash %q for different unions", tagged.Hash())
+	}
+	if actual := QualifiedTypeName(inline); actual != "union<string, array<int>>" {
+		t.Errorf("got %q, expected %q", actual, "union<string, array<int>>")
+	}
+
+	cases := map[string]struct {
+		typ      *Union
+		value    interface{}
+		expected bool
+	}{
+		"discriminated":         {tagged, map[string]interface{}{"type": "bank", "iban": "FR76"}, true},
+		"unknown discriminator": {tagged, map[string]interface{}{"type": "cash"}, false},
+		"no discriminator":      {tagged, map[string]interface{}{"iban": "FR76"}, false},
+		"first alternative":     {inline, "zoe", true},
+		"second alternative":    {inline, []int{1, 2}, true},
+		"no alternative":        {inline, true, false},
+	}
+	for k, tc := range cases {
+		if actual := tc.typ.IsCompatible(tc.value); actual != tc.expected {
+			t.Errorf("%s: got %#v, expected %#v", k, actual, tc.expected)
+		}
+	}
+
+	r := NewRandom("union")
+	for i := 0; i < 10; i++ {
+		ex := tagged.Example(r)
+		if err := Validate(&AttributeExpr{Type: tagged}, ex); err != nil {
+			t.Errorf("got invalid example %#v: %v", ex, err)
+		}
+	}
+}
+
+func TestBigPrimitiveIsCompatible(t *testing.T) {
+	cases := map[string]struct {
+		typ      Primitive
+		value    interface{}
+		expected bool
+	}{
+		"decimal string":      {Decimal, "-12.50", true},
+		"decimal json number": {Decimal, json.Number("3.14"), true},
+		"decimal float":       {Decimal, 3.14, true},
+		"decimal big float":   {Decimal, big.NewFloat(1.5), true},
+		"decimal invalid":     {Decimal, "12,50", false},
+		"bigint string":       {BigInt, "123456789012345678901234567890", true},
+		"bigint big int":      {BigInt, big.NewInt(42), true},
+		"bigint int":          {BigInt, 42, true},
+		"bigint decimal":      {BigInt, "1.5", false},
+		"bigint float":        {BigInt, 1.5, false},
+	}
+	for k, tc := range cases {
+		if actual := tc.typ.IsCompatible(tc.value); actual != tc.expected {
+			t.Errorf("%s: got %#v, expected %#v", k, actual, tc.expected)
+		}
+	}
+	r := NewRandom("big")
+	for _, p := range []Primitive{Decimal, BigInt} {
+		if ex := p.Example(r); !p.IsCompatible(ex) {
+			t.Errorf("%s: got incompatible example %#v", p.Name(), ex)
+		}
+	}
+}
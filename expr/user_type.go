@@ -1,5 +1,7 @@
 package expr
 
+import "go.zoe.im/goser/eval"
+
 type (
 	// UserTypeExpr describes user defined types.
 	UserTypeExpr struct {
@@ -50,6 +52,14 @@ func (u *UserTypeExpr) Attribute() *AttributeExpr {
 	return u.AttributeExpr
 }
 
+// Validate validates the attribute of the user type, it is the root of a
+// validation so the nested attributes and user types share its validator. Use
+// AttributeValidator.ValidateUserType to share a validator across the user
+// types of a design so that the shared types are reported once.
+func (u *UserTypeExpr) Validate(ctx string, parent eval.Expression) *eval.ValidationErrors {
+	return NewAttributeValidator().ValidateUserType(u, ctx, parent)
+}
+
 // SetAttribute sets the embedded attribute.
 func (u *UserTypeExpr) SetAttribute(att *AttributeExpr) {
 	u.AttributeExpr = att
@@ -0,0 +1,507 @@
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type (
+	// ValueError describes a value which doesn't satisfy the attribute it
+	// is validated against.
+	ValueError struct {
+		// Path of the value, e.g. "user.addresses[2].zip", empty for the
+		// validated value itself.
+		Path string
+		// Message describes the error.
+		Message string
+	}
+
+	// ValueErrors is the list of errors returned by Validate.
+	ValueErrors []*ValueError
+)
+
+var (
+	uuidRegex     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnameRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?)*$`)
+)
+
+// Validate checks that a value decoded from JSON or YAML satisfies the
+// attribute: the type, the required fields and all the validations (enum,
+// format, pattern, minimum, maximum and length). It returns nil if the value
+// is valid or ValueErrors listing all the errors with the path of the
+// offending values.
+func Validate(att *AttributeExpr, value interface{}) error {
+	var errs ValueErrors
+	validateValue(att, value, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Error returns the error message prefixed with the path.
+func (e *ValueError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Error returns all the error messages separated by semicolons.
+func (e ValueErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func validateValue(att *AttributeExpr, val interface{}, path string, errs *ValueErrors) {
+	if att == nil || att.Type == nil || val == nil {
+		return
+	}
+	add := func(format string, args ...interface{}) {
+		*errs = append(*errs, &ValueError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch t := att.Type.(type) {
+	case UserType:
+		n := len(*errs)
+		validateValue(t.Attribute(), val, path, errs)
+		if len(*errs) > n {
+			return
+		}
+	case Primitive:
+		if !compatibleValue(t, val) {
+			add("value %s must be %s", formatValue(val), t.Name())
+			return
+		}
+	case *Array:
+		rv := reflect.ValueOf(val)
+		if k := rv.Kind(); k != reflect.Slice && k != reflect.Array {
+			add("value %s must be %s", formatValue(val), QualifiedTypeName(t))
+			return
+		}
+		for i := 0; i < rv.Len(); i++ {
+			validateValue(t.ElemType, rv.Index(i).Interface(), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case *Map:
+		rv := reflect.ValueOf(val)
+		if rv.Kind() != reflect.Map {
+			add("value %s must be %s", formatValue(val), QualifiedTypeName(t))
+			return
+		}
+		for _, k := range sortedKeys(rv) {
+			p := fmt.Sprintf("%s[%s]", path, formatValue(k.Interface()))
+			validateValue(t.KeyType, mapKey(t.KeyType.Type, k.Interface()), p, errs)
+			validateValue(t.ElemType, rv.MapIndex(k).Interface(), p, errs)
+		}
+	case *Object:
+		fields, ok := objectFields(val)
+		if !ok {
+			add("value %s must be an object", formatValue(val))
+			return
+		}
+		for _, nat := range *t {
+			validateValue(nat.Attribute, fields[nat.Name], joinPath(path, nat.Name), errs)
+		}
+	case *Union:
+		if !validateUnion(t, val, path, errs) {
+			return
+		}
+	}
+
+	if att.Validation == nil {
+		return
+	}
+	v := att.Validation
+	if len(v.Required) > 0 {
+		if fields, ok := objectFields(val); ok {
+			for _, n := range v.Required {
+				if f, ok := fields[n]; !ok || f == nil {
+					*errs = append(*errs, &ValueError{Path: joinPath(path, n), Message: "attribute is required"})
+				}
+			}
+		}
+	}
+	if len(v.Values) > 0 {
+		var found bool
+		for _, e := range v.Values {
+			if equalValues(e, val) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			vals := make([]string, len(v.Values))
+			for i, e := range v.Values {
+				vals[i] = formatValue(e)
+			}
+			add("value %s must be one of %s", formatValue(val), strings.Join(vals, ", "))
+		}
+	}
+	if s, ok := val.(string); ok {
+		if v.Format != "" {
+			if err := validateFormat(v.Format, s); err != nil {
+				add("value %s must be formatted as %s: %v", formatValue(val), v.Format, err)
+			}
+		}
+		if v.Pattern != "" {
+			if re, err := regexp.Compile(v.Pattern); err == nil && !re.MatchString(s) {
+				add("value %s must match the regular expression %q", formatValue(val), v.Pattern)
+			}
+		}
+	}
+	if f, ok := toFloat(val); ok {
+		if v.Minimum != nil && f < *v.Minimum {
+			add("value %s must be >= %v", formatValue(val), *v.Minimum)
+		}
+		if v.Maximum != nil && f > *v.Maximum {
+			add("value %s must be <= %v", formatValue(val), *v.Maximum)
+		}
+	}
+	if v.Precision != nil || v.Scale != nil {
+		if ip, fp, ok := decimalDigits(val); ok {
+			if v.Scale != nil && fp > *v.Scale {
+				add("value %s must have at most %d digits after the decimal point", formatValue(val), *v.Scale)
+			}
+			if v.Precision != nil {
+				max := *v.Precision
+				if v.Scale != nil {
+					max -= *v.Scale
+				}
+				if ip > max || ip+fp > *v.Precision {
+					add("value %s must have at most %d digits", formatValue(val), *v.Precision)
+				}
+			}
+		}
+	}
+	if v.MinLength != nil || v.MaxLength != nil {
+		if l, ok := valueLength(val); ok {
+			if v.MinLength != nil && l < *v.MinLength {
+				add("length must be >= %d", *v.MinLength)
+			}
+			if v.MaxLength != nil && l > *v.MaxLength {
+				add("length must be <= %d", *v.MaxLength)
+			}
+		}
+	}
+}
+
+// validateUnion validates the value against the alternative named by the
+// discriminator or, if the union has none, against the first alternative the
+// value satisfies. It returns false if no alternative matches.
+func validateUnion(u *Union, val interface{}, path string, errs *ValueErrors) bool {
+	add := func(format string, args ...interface{}) {
+		*errs = append(*errs, &ValueError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	names := make([]string, len(u.Values))
+	for i, nat := range u.Values {
+		names[i] = formatValue(nat.Name)
+	}
+	if u.Discriminator != "" {
+		fields, ok := objectFields(val)
+		if !ok {
+			add("value %s must be an object", formatValue(val))
+			return false
+		}
+		name, _ := fields[u.Discriminator].(string)
+		att := u.Alternative(name)
+		if att == nil {
+			*errs = append(*errs, &ValueError{
+				Path:    joinPath(path, u.Discriminator),
+				Message: fmt.Sprintf("value %s must be one of %s", formatValue(fields[u.Discriminator]), strings.Join(names, ", ")),
+			})
+			return false
+		}
+		validateValue(att, val, path, errs)
+		return true
+	}
+	for _, nat := range u.Values {
+		var alt ValueErrors
+		validateValue(nat.Attribute, val, path, &alt)
+		if len(alt) == 0 {
+			return true
+		}
+	}
+	add("value %s must match one of the alternatives %s", formatValue(val), strings.Join(names, ", "))
+	return false
+}
+
+// compatibleValue returns true if the decoded value is compatible with the
+// primitive, numbers are compatible with integer primitives if they have no
+// fractional part and fit in the range of the primitive.
+func compatibleValue(p Primitive, val interface{}) bool {
+	switch p {
+	case Any:
+		return true
+	case Boolean:
+		_, ok := val.(bool)
+		return ok
+	case String:
+		_, ok := val.(string)
+		return ok
+	case Bytes:
+		switch val.(type) {
+		case string, []byte:
+			return true
+		}
+		return false
+	case Float32, Float64:
+		_, ok := toFloat(val)
+		return ok
+	case Decimal, BigInt:
+		if f, ok := toFloat(val); ok {
+			return p == Decimal || f == math.Trunc(f)
+		}
+		return p.IsCompatible(val)
+	case Timestamp, Date, Duration:
+		if f, ok := toFloat(val); ok {
+			// durations may be given in nanoseconds
+			return p == Duration && f == math.Trunc(f)
+		}
+		return p.IsCompatible(val)
+	}
+	if n, ok := val.(json.Number); ok {
+		// integers above 2^53 are checked without losing precision
+		if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+			switch p {
+			case Int32:
+				return i >= math.MinInt32 && i <= math.MaxInt32
+			case UInt32:
+				return i >= 0 && i <= math.MaxUint32
+			case UInt, UInt64:
+				return i >= 0
+			}
+			return true
+		}
+		if _, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+			return p == UInt || p == UInt64
+		}
+	}
+	f, ok := toFloat(val)
+	if !ok || f != math.Trunc(f) {
+		return false
+	}
+	switch p {
+	case Int32:
+		return f >= math.MinInt32 && f <= math.MaxInt32
+	case UInt32:
+		return f >= 0 && f <= math.MaxUint32
+	case UInt, UInt64:
+		return f >= 0
+	}
+	return true
+}
+
+// validateFormat checks the string satisfies the format.
+func validateFormat(f ValidationFormat, s string) error {
+	var err error
+	switch f {
+	case FormatDate:
+		_, err = time.Parse("2006-01-02", s)
+	case FormatDateTime:
+		_, err = time.Parse(time.RFC3339, s)
+	case FormatRFC1123:
+		_, err = time.Parse(time.RFC1123, s)
+	case FormatUUID:
+		if !uuidRegex.MatchString(s) {
+			err = fmt.Errorf("invalid uuid")
+		}
+	case FormatEmail:
+		_, err = mail.ParseAddress(s)
+	case FormatHostname:
+		if !hostnameRegex.MatchString(s) {
+			err = fmt.Errorf("invalid hostname")
+		}
+	case FormatIPv4:
+		if ip := net.ParseIP(s); ip == nil || ip.To4() == nil {
+			err = fmt.Errorf("invalid ipv4 address")
+		}
+	case FormatIPv6:
+		if ip := net.ParseIP(s); ip == nil || !strings.Contains(s, ":") {
+			err = fmt.Errorf("invalid ipv6 address")
+		}
+	case FormatIP:
+		if net.ParseIP(s) == nil {
+			err = fmt.Errorf("invalid ip address")
+		}
+	case FormatURI:
+		_, err = url.ParseRequestURI(s)
+	case FormatMAC:
+		_, err = net.ParseMAC(s)
+	case FormatCIDR:
+		_, _, err = net.ParseCIDR(s)
+	case FormatRegexp:
+		_, err = regexp.Compile(s)
+	case FormatJSON:
+		if !json.Valid([]byte(s)) {
+			err = fmt.Errorf("invalid json")
+		}
+	}
+	return err
+}
+
+// objectFields returns the fields of a decoded object value.
+func objectFields(val interface{}) (map[string]interface{}, bool) {
+	switch actual := val.(type) {
+	case map[string]interface{}:
+		return actual, true
+	case Val:
+		return actual, true
+	}
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Map {
+		return nil, false
+	}
+	fields := make(map[string]interface{}, rv.Len())
+	for _, k := range rv.MapKeys() {
+		fields[fmt.Sprintf("%v", k.Interface())] = rv.MapIndex(k).Interface()
+	}
+	return fields, true
+}
+
+// toFloat converts a decoded number to float64.
+func toFloat(val interface{}) (float64, bool) {
+	switch n := val.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	}
+	return 0, false
+}
+
+// decimalDigits returns the number of significant digits before and after the
+// decimal point of a decimal value given as a string or a number.
+func decimalDigits(val interface{}) (int, int, bool) {
+	var s string
+	switch v := val.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = string(v)
+	case float32:
+		s = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		if f, ok := toFloat(val); ok {
+			s = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+	if !decimalRegex.MatchString(s) {
+		return 0, 0, false
+	}
+	s = strings.TrimLeft(s, "+-")
+	ip, fp := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		ip, fp = s[:i], strings.TrimRight(s[i+1:], "0")
+	}
+	return len(strings.TrimLeft(ip, "0")), len(fp), true
+}
+
+// valueLength returns the number of characters of strings, the number of bytes
+// of binary data or the number of items of arrays and maps.
+func valueLength(val interface{}) (int, bool) {
+	switch actual := val.(type) {
+	case string:
+		return utf8.RuneCountInString(actual), true
+	case []byte:
+		return len(actual), true
+	}
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len(), true
+	}
+	return 0, false
+}
+
+// equalValues compares decoded values, numbers are compared by value
+// regardless of their Go types.
+func equalValues(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// mapKey returns the map key parsed into the key type: JSON object keys are
+// strings so the keys of numeric and boolean types are decoded as such.
+func mapKey(dt DataType, k interface{}) interface{} {
+	s, ok := k.(string)
+	if !ok {
+		return k
+	}
+	for {
+		ut, ok := dt.(UserType)
+		if !ok {
+			break
+		}
+		dt = ut.Attribute().Type
+	}
+	p, ok := dt.(Primitive)
+	if !ok {
+		return k
+	}
+	switch p {
+	case Boolean:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case Int, Int32, Int64, UInt, UInt32, UInt64, Float32, Float64:
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			return json.Number(s)
+		}
+	}
+	return k
+}
+
+// sortedKeys returns the keys of the map value sorted by their string
+// representation so that errors are reported in a stable order.
+func sortedKeys(rv reflect.Value) []reflect.Value {
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprintf("%v", keys[i].Interface()) < fmt.Sprintf("%v", keys[j].Interface())
+	})
+	return keys
+}
+
+// formatValue formats the value used in error messages.
+func formatValue(val interface{}) string {
+	if s, ok := val.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", val)
+}
+
+// joinPath joins the path and the field name.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,204 @@
+package expr
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	var (
+		five    = 5
+		zero    = 0.0
+		address = &UserTypeExpr{
+			TypeName: "Address",
+			AttributeExpr: &AttributeExpr{
+				Type: &Object{
+					{"zip", &AttributeExpr{Type: String, Validation: &ValidationExpr{MinLength: &five}}},
+					{"kind", &AttributeExpr{Type: String, Validation: &ValidationExpr{Values: []interface{}{"home", "work"}}}},
+				},
+			},
+		}
+		user = &AttributeExpr{
+			Type: &Object{
+				{"name", &AttributeExpr{Type: String}},
+				{"email", &AttributeExpr{Type: String, Validation: &ValidationExpr{Format: FormatEmail}}},
+				{"age", &AttributeExpr{Type: Int32, Validation: &ValidationExpr{Minimum: &zero}}},
+				{"code", &AttributeExpr{Type: String, Validation: &ValidationExpr{Pattern: "^[A-Z]+$"}}},
+				{"addresses", &AttributeExpr{Type: &Array{ElemType: &AttributeExpr{Type: address}}}},
+				{"labels", &AttributeExpr{Type: &Map{KeyType: &AttributeExpr{Type: String}, ElemType: &AttributeExpr{Type: Int}}}},
+				{"scores", &AttributeExpr{Type: &Map{KeyType: &AttributeExpr{Type: Int32, Validation: &ValidationExpr{Minimum: &zero}}, ElemType: &AttributeExpr{Type: String}}}},
+			},
+			Validation: &ValidationExpr{Required: []string{"name"}},
+		}
+		root = &AttributeExpr{
+			Type: &Object{{"user", user}},
+		}
+	)
+	cases := map[string]struct {
+		value    string
+		expected string
+	}{
+		"valid": {
+			value:    `{"user":{"name":"zoe","email":"zoe@zoe.im","age":18,"code":"ZOE","addresses":[{"zip":"12345","kind":"home"}],"labels":{"a":1},"scores":{"1":"a"}}}`,
+			expected: "",
+		},
+		"required": {
+			value:    `{"user":{}}`,
+			expected: `user.name: attribute is required`,
+		},
+		"nested length": {
+			value:    `{"user":{"name":"zoe","addresses":[{"zip":"12345"},{"zip":"12345"},{"zip":"123"}]}}`,
+			expected: `user.addresses[2].zip: length must be >= 5`,
+		},
+		"enum": {
+			value:    `{"user":{"name":"zoe","addresses":[{"kind":"school"}]}}`,
+			expected: `user.addresses[0].kind: value "school" must be one of "home", "work"`,
+		},
+		"type": {
+			value:    `{"user":{"name":1,"age":1.5}}`,
+			expected: `user.name: value 1 must be string; user.age: value 1.5 must be int32`,
+		},
+		"range": {
+			value:    `{"user":{"name":"zoe","age":-1}}`,
+			expected: `user.age: value -1 must be >= 0`,
+		},
+		"pattern": {
+			value:    `{"user":{"name":"zoe","code":"zoe"}}`,
+			expected: `user.code: value "zoe" must match the regular expression "^[A-Z]+$"`,
+		},
+		"format": {
+			value:    `{"user":{"name":"zoe","email":"zoe"}}`,
+			expected: `user.email: value "zoe" must be formatted as email: mail: missing '@' or angle-addr`,
+		},
+		"map": {
+			value:    `{"user":{"name":"zoe","labels":{"a":"b"}}}`,
+			expected: `user.labels["a"]: value "b" must be int`,
+		},
+		"map key": {
+			value:    `{"user":{"name":"zoe","scores":{"-1":"a","1.5":"b","x":"c"}}}`,
+			expected: `user.scores["-1"]: value -1 must be >= 0; user.scores["1.5"]: value 1.5 must be int32; user.scores["x"]: value "x" must be int32`,
+		},
+		"object": {
+			value:    `{"user":"zoe"}`,
+			expected: `user: value "zoe" must be an object`,
+		},
+	}
+
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			var val interface{}
+			if err := json.Unmarshal([]byte(tc.value), &val); err != nil {
+				t.Fatal(err)
+			}
+			var actual string
+			if err := Validate(root, val); err != nil {
+				actual = err.Error()
+			}
+			if actual != tc.expected {
+				t.Errorf("got %q, expected %q", actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestValidateUnion(t *testing.T) {
+	cases := map[string]struct {
+		union    *Union
+		value    string
+		expected string
+	}{
+		"discriminated": {
+			union: testUnion("type"),
+			value: `{"type":"card","number":"4242"}`,
+		},
+		"discriminated invalid": {
+			union:    testUnion("type"),
+			value:    `{"type":"card","iban":"FR76"}`,
+			expected: `number: attribute is required`,
+		},
+		"unknown discriminator": {
+			union:    testUnion("type"),
+			value:    `{"type":"cash"}`,
+			expected: `type: value "cash" must be one of "card", "bank"`,
+		},
+		"inferred": {
+			union: testUnion(""),
+			value: `{"iban":"FR76"}`,
+		},
+		"no alternative": {
+			union:    testUnion(""),
+			value:    `{"cash":1}`,
+			expected: `value map[cash:1] must match one of the alternatives "card", "bank"`,
+		},
+	}
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			var val interface{}
+			if err := json.Unmarshal([]byte(tc.value), &val); err != nil {
+				t.Fatal(err)
+			}
+			var actual string
+			if err := Validate(&AttributeExpr{Type: tc.union}, val); err != nil {
+				actual = err.Error()
+			}
+			if actual != tc.expected {
+				t.Errorf("got %q, expected %q", actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestValidateDecimal(t *testing.T) {
+	var (
+		precision = 5
+		scale     = 2
+		att       = &AttributeExpr{Type: Decimal, Validation: &ValidationExpr{Precision: &precision, Scale: &scale}}
+	)
+	cases := map[string]struct {
+		value    interface{}
+		expected string
+	}{
+		"valid":           {"123.45", ""},
+		"trailing zeros":  {"12.500", ""},
+		"number":          {json.Number("-0.5"), ""},
+		"too many places": {"1.234", `value "1.234" must have at most 2 digits after the decimal point`},
+		"too many digits": {1234.5, `value 1234.5 must have at most 5 digits`},
+		"not a decimal":   {"1e5", `value "1e5" must be decimal`},
+	}
+	for k, tc := range cases {
+		var actual string
+		if err := Validate(att, tc.value); err != nil {
+			actual = err.Error()
+		}
+		if actual != tc.expected {
+			t.Errorf("%s: got %q, expected %q", k, actual, tc.expected)
+		}
+	}
+}
+
+func TestValidateNumber(t *testing.T) {
+	cases := map[string]struct {
+		typ      DataType
+		value    json.Number
+		expected string
+	}{
+		"int64 above 2^53": {Int64, "9007199254740993", ""},
+		"int64 maximum":    {Int64, "9223372036854775807", ""},
+		"int64 overflow":   {Int64, "9223372036854775808", "value 9223372036854775808 must be int64"},
+		"uint64 maximum":   {UInt64, "18446744073709551615", ""},
+		"uint64 negative":  {UInt64, "-1", "value -1 must be uint64"},
+		"int32 overflow":   {Int32, "2147483648", "value 2147483648 must be int32"},
+		"int exponent":     {Int, "1e3", ""},
+		"int fraction":     {Int, "1.5", "value 1.5 must be int"},
+		"float":            {Float64, "1.5", ""},
+	}
+	for k, tc := range cases {
+		var actual string
+		if err := Validate(&AttributeExpr{Type: tc.typ}, tc.value); err != nil {
+			actual = err.Error()
+		}
+		if actual != tc.expected {
+			t.Errorf("%s: got %q, expected %q", k, actual, tc.expected)
+		}
+	}
+}
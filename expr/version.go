@@ -0,0 +1,146 @@
+package expr
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	// VersionsMeta is the meta key of the API versions maintained side by
+	// side by a design.
+	VersionsMeta = "versions"
+	// VersionSinceMeta is the meta key of the first API version design
+	// elements are available in.
+	VersionSinceMeta = "version:since"
+	// VersionUntilMeta is the meta key of the last API version design
+	// elements are available in.
+	VersionUntilMeta = "version:until"
+)
+
+// CompareVersions compares two API versions such as "v1", "v2" or "1.2.0" and
+// returns -1, 0 or 1 if a is respectively lower than, equal to or greater than
+// b. The numeric parts are compared as numbers, the others as strings.
+func CompareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var ap, bp string
+		if i < len(as) {
+			ap = as[i]
+		}
+		if i < len(bs) {
+			bp = bs[i]
+		}
+		an, aerr := strconv.Atoi(ap)
+		bn, berr := strconv.Atoi(bp)
+		if aerr == nil && berr == nil {
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+		if ap != bp {
+			if ap < bp {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// InVersion returns true if the element with the given meta is available in
+// the API version, that is if the version is between the "version:since" and
+// "version:until" meta included. Elements are available in all versions if
+// version is empty.
+func InVersion(meta MetaExpr, version string) bool {
+	if version == "" {
+		return true
+	}
+	if since := meta[VersionSinceMeta]; len(since) > 0 && CompareVersions(version, since[0]) < 0 {
+		return false
+	}
+	if until := meta[VersionUntilMeta]; len(until) > 0 && CompareVersions(version, until[0]) > 0 {
+		return false
+	}
+	return true
+}
+
+// AtVersion returns a copy of the data type with only the fields and union
+// alternatives available in the API version. User types keep their names so
+// that the types of a version can be generated in their own package.
+func AtVersion(t DataType, version string) DataType {
+	dup := Dup(t)
+	pruneVersion(&AttributeExpr{Type: dup}, version, make(map[*AttributeExpr]bool))
+	return dup
+}
+
+// AttributeAtVersion returns a copy of the attribute with only the fields and
+// union alternatives available in the API version. See AtVersion.
+func AttributeAtVersion(att *AttributeExpr, version string) *AttributeExpr {
+	dup := DupAtt(att)
+	pruneVersion(dup, version, make(map[*AttributeExpr]bool))
+	return dup
+}
+
+// pruneVersion removes the fields and union alternatives not available in the
+// version from the attribute recursively.
+func pruneVersion(att *AttributeExpr, version string, seen map[*AttributeExpr]bool) {
+	if att == nil || seen[att] {
+		return
+	}
+	seen[att] = true
+	switch t := att.Type.(type) {
+	case UserType:
+		pruneVersion(t.Attribute(), version, seen)
+	case *Object:
+		var (
+			res     = make(Object, 0, len(*t))
+			removed []string
+		)
+		for _, nat := range *t {
+			if !InVersion(nat.Attribute.Meta, version) {
+				removed = append(removed, nat.Name)
+				if att.Validation != nil {
+					att.Validation.RemoveRequired(nat.Name)
+				}
+				continue
+			}
+			res = append(res, nat)
+			pruneVersion(nat.Attribute, version, seen)
+		}
+		*t = res
+		if len(removed) > 0 {
+			// examples are shared with the original attribute
+			for _, ex := range att.UserExamples {
+				if m, ok := ex.Value.(map[string]interface{}); ok {
+					cp := make(map[string]interface{}, len(m))
+					for k, v := range m {
+						cp[k] = v
+					}
+					for _, n := range removed {
+						delete(cp, n)
+					}
+					ex.Value = cp
+				}
+			}
+		}
+	case *Array:
+		pruneVersion(t.ElemType, version, seen)
+	case *Map:
+		pruneVersion(t.KeyType, version, seen)
+		pruneVersion(t.ElemType, version, seen)
+	case *Union:
+		values := make([]*NamedAttributeExpr, 0, len(t.Values))
+		for _, nat := range t.Values {
+			if InVersion(nat.Attribute.Meta, version) {
+				values = append(values, nat)
+				pruneVersion(nat.Attribute, version, seen)
+			}
+		}
+		t.Values = values
+	}
+}
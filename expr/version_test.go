@@ -0,0 +1,69 @@
+package expr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	cases := map[string]struct {
+		a, b     string
+		expected int
+	}{
+		"equal":        {"v1", "v1", 0},
+		"prefix":       {"v1", "1", 0},
+		"lower":        {"v1", "v2", -1},
+		"numeric":      {"v10", "v9", 1},
+		"minor":        {"1.2", "1.10", -1},
+		"missing part": {"1", "1.1", -1},
+		"string parts": {"v1beta", "v1alpha", 1},
+	}
+	for k, tc := range cases {
+		if actual := CompareVersions(tc.a, tc.b); actual != tc.expected {
+			t.Errorf("%s: got %d, expected %d", k, actual, tc.expected)
+		}
+	}
+}
+
+func TestAtVersion(t *testing.T) {
+	var (
+		since = func(v string) MetaExpr { return MetaExpr{VersionSinceMeta: {v}} }
+		until = func(v string) MetaExpr { return MetaExpr{VersionUntilMeta: {v}} }
+		user  = &UserTypeExpr{
+			TypeName: "User",
+			AttributeExpr: &AttributeExpr{
+				Type: &Object{
+					{"id", &AttributeExpr{Type: String}},
+					{"login", &AttributeExpr{Type: String, Meta: until("v1")}},
+					{"email", &AttributeExpr{Type: String, Meta: since("v2")}},
+				},
+				Validation: &ValidationExpr{Required: []string{"id", "login", "email"}},
+			},
+		}
+	)
+	cases := map[string]struct {
+		fields   []string
+		required []string
+	}{
+		"":   {[]string{"id", "login", "email"}, []string{"id", "login", "email"}},
+		"v1": {[]string{"id", "login"}, []string{"id", "login"}},
+		"v2": {[]string{"id", "email"}, []string{"id", "email"}},
+		"v3": {[]string{"id", "email"}, []string{"id", "email"}},
+	}
+	for version, tc := range cases {
+		ut := AtVersion(user, version).(*UserTypeExpr)
+		var fields []string
+		for _, nat := range *AsObject(ut) {
+			fields = append(fields, nat.Name)
+		}
+		if !reflect.DeepEqual(fields, tc.fields) {
+			t.Errorf("%q: got fields %v, expected %v", version, fields, tc.fields)
+		}
+		if !reflect.DeepEqual(ut.Validation.Required, tc.required) {
+			t.Errorf("%q: got required %v, expected %v", version, ut.Validation.Required, tc.required)
+		}
+	}
+	if len(*AsObject(user)) != 3 || len(user.Validation.Required) != 3 {
+		t.Errorf("got %s, expected the original type to be kept", QualifiedTypeName(user))
+	}
+}
@@ -5,4 +5,5 @@ go 1.12
 require (
 	go.zoe.im/x v0.0.5
 	goa.design/goa/v3 v3.0.3
+	gopkg.in/yaml.v2 v2.4.0
 )
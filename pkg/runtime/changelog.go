@@ -0,0 +1,111 @@
+package runtime
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+)
+
+// changelogSections lists the sections of a changelog group in order
+var changelogSections = []string{"Added", "Deprecated", "Changed", "Removed"}
+
+// Changelog renders the changes between two versions of the models and
+// services loaded in runtimes as Markdown, old being the previous version.
+// The changes are grouped by service, changes of the models and enums are
+// grouped in a Types section.
+func Changelog(title string, old, new *Runtime) string {
+	var (
+		groups = map[string][]*expr.Change{}
+		names  []string
+	)
+	for _, c := range Compare(old, new) {
+		g := changeGroup(c.Path, old, new)
+		if _, ok := groups[g]; !ok {
+			names = append(names, g)
+		}
+		groups[g] = append(groups[g], c)
+	}
+	// services first then types
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == "" || names[j] == "" {
+			return names[j] == ""
+		}
+		return names[i] < names[j]
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", title)
+	if len(names) == 0 {
+		b.WriteString("\nNo changes.\n")
+		return b.String()
+	}
+	for _, g := range names {
+		if g == "" {
+			b.WriteString("\n## Types\n")
+		} else {
+			fmt.Fprintf(&b, "\n## Service %s\n", g)
+			svc := new.Service(g)
+			if svc == nil {
+				svc = old.Service(g)
+			}
+			if svc.Description != "" {
+				fmt.Fprintf(&b, "\n%s\n", svc.Description)
+			}
+		}
+		sections := map[string][]*expr.Change{}
+		for _, c := range groups[g] {
+			s := changeSection(c.Kind)
+			sections[s] = append(sections[s], c)
+		}
+		for _, s := range changelogSections {
+			if len(sections[s]) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "\n### %s\n\n", s)
+			for _, c := range sections[s] {
+				writeChange(&b, c)
+			}
+		}
+	}
+	return b.String()
+}
+
+// changeGroup returns the name of the service the change path belongs to,
+// empty if the change is not about a service.
+func changeGroup(path string, old, new *Runtime) string {
+	name := path
+	if i := strings.IndexAny(path, ".|[{"); i >= 0 {
+		name = path[:i]
+	}
+	if new.Service(name) != nil || old.Service(name) != nil {
+		return name
+	}
+	return ""
+}
+
+// changeSection returns the changelog section of the change kind
+func changeSection(kind expr.ChangeKind) string {
+	switch kind {
+	case expr.ChangeTypeAdded, expr.ChangeFieldAdded, expr.ChangeServiceAdded, expr.ChangeMethodAdded:
+		return "Added"
+	case expr.ChangeTypeRemoved, expr.ChangeFieldRemoved, expr.ChangeServiceRemoved, expr.ChangeMethodRemoved:
+		return "Removed"
+	case expr.ChangeDeprecated:
+		return "Deprecated"
+	}
+	return "Changed"
+}
+
+// writeChange writes the change as a list item
+func writeChange(b *strings.Builder, c *expr.Change) {
+	fmt.Fprintf(b, "- `%s`: %s", c.Path, c.Message)
+	if c.Description != "" {
+		fmt.Fprintf(b, " - %s", c.Description)
+	}
+	if c.IsBreaking() {
+		fmt.Fprintf(b, " **(breaking %s)**", strings.Join(c.Breaking, ", "))
+	}
+	b.WriteString("\n")
+}
@@ -0,0 +1,96 @@
+package runtime
+
+import (
+	"testing"
+)
+
+func TestChangelog(t *testing.T) {
+	load := func(s string) *Runtime {
+		spec, err := ParseSpec([]byte(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := New()
+		if err := r.Load(spec); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	old := load(`
+models:
+  User:
+    fields:
+      - name: id
+        type: string
+      - name: login
+        type: string
+services:
+  users:
+    description: Manage the users
+    methods:
+      get:
+        payload: User
+        result: User
+`)
+	new := load(`
+models:
+  User:
+    fields:
+      - name: id
+        type: string
+      - name: login
+        type: string
+        meta:
+          deprecated: [use email instead]
+      - name: email
+        type: string
+        description: Email of the user
+  Team:
+    description: A team of users
+    fields:
+      - name: id
+        type: string
+services:
+  users:
+    description: Manage the users
+    methods:
+      get:
+        payload: User
+        result: User
+        http: GET /users/{id}
+      list:
+        description: List the users
+        result: User
+`)
+	expected := `# Changes
+
+## Service users
+
+Manage the users
+
+### Added
+
+- ` + "`users.list`" + `: method list added - List the users
+
+### Changed
+
+- ` + "`users.get`" + `: route changed from "POST /users/get" to "GET /users/{id}" **(breaking http)**
+
+## Types
+
+### Added
+
+- ` + "`User.email`" + `: field email added - Email of the user
+- ` + "`Team`" + `: type Team added - A team of users
+
+### Deprecated
+
+- ` + "`User.login`" + `: field login deprecated - use email instead
+`
+	if actual := Changelog("Changes", old, new); actual != expected {
+		t.Errorf("got\n%s\nexpected\n%s", actual, expected)
+	}
+	if actual := Changelog("Changes", old, old); actual != "# Changes\n\nNo changes.\n" {
+		t.Errorf("got %q, expected no changes", actual)
+	}
+}
@@ -0,0 +1,72 @@
+package runtime
+
+import (
+	"go.zoe.im/goser/expr"
+)
+
+// Compare returns the changes between two versions of the models and services
+// loaded in runtimes, old being the previous version. Every change is
+// classified as compatible or breaking per transport.
+func Compare(old, new *Runtime) []*expr.Change {
+	changes := expr.CompareUserTypes(old.userTypes(), new.userTypes())
+	for _, osvc := range old.Services() {
+		nsvc := new.Service(osvc.Name)
+		if nsvc == nil {
+			changes = append(changes, expr.NewChange(osvc.Name, expr.ChangeServiceRemoved, expr.AllTransports,
+				"service %s removed", osvc.Name).Describe(osvc.Description))
+			continue
+		}
+		if c := expr.CompareDeprecation(osvc.Name, "service "+osvc.Name, osvc.Meta, nsvc.Meta); c != nil {
+			changes = append(changes, c)
+		}
+		changes = append(changes, compareService(osvc, nsvc)...)
+	}
+	for _, nsvc := range new.Services() {
+		if old.Service(nsvc.Name) == nil {
+			changes = append(changes, expr.NewChange(nsvc.Name, expr.ChangeServiceAdded, nil,
+				"service %s added", nsvc.Name).Describe(nsvc.Description))
+		}
+	}
+	return changes
+}
+
+// compareService compares the methods of two versions of a service.
+func compareService(old, new *Service) []*expr.Change {
+	var changes []*expr.Change
+	for _, om := range old.Methods {
+		nm := new.Method(om.Name)
+		if nm == nil {
+			changes = append(changes, expr.NewChange(om.Key(), expr.ChangeMethodRemoved, expr.AllTransports,
+				"method %s removed", om.Name).Describe(om.Description))
+			continue
+		}
+		if c := expr.CompareDeprecation(om.Key(), "method "+om.Name, om.Meta, nm.Meta); c != nil {
+			changes = append(changes, c)
+		}
+		if *om.Route != *nm.Route {
+			changes = append(changes, expr.NewChange(om.Key(), expr.ChangeRouteChanged, []string{expr.TransportHTTP},
+				"route changed from %q to %q", om.Route.Method+" "+om.Route.Path, nm.Route.Method+" "+nm.Route.Path))
+		}
+		changes = append(changes, expr.CompareAttributes(om.Key()+".payload", om.Payload, nm.Payload)...)
+		changes = append(changes, expr.CompareAttributes(om.Key()+".result", om.Result, nm.Result)...)
+	}
+	for _, nm := range new.Methods {
+		if old.Method(nm.Name) == nil {
+			changes = append(changes, expr.NewChange(nm.Key(), expr.ChangeMethodAdded, nil,
+				"method %s added", nm.Name).Describe(nm.Description))
+		}
+	}
+	return changes
+}
+
+// userTypes returns the enums and models of the runtime.
+func (r *Runtime) userTypes() []expr.UserType {
+	uts := make([]expr.UserType, 0, len(r.enums)+len(r.models))
+	for _, e := range r.enums {
+		uts = append(uts, e)
+	}
+	for _, m := range r.models {
+		uts = append(uts, m.Type)
+	}
+	return uts
+}
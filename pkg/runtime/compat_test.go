@@ -0,0 +1,99 @@
+package runtime
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	const old = `
+models:
+  User:
+    fields:
+      - name: id
+        type: string
+        tag: 1
+      - name: name
+        type: string
+        tag: 2
+services:
+  users:
+    methods:
+      get:
+        payload: User
+        result: User
+        http: GET /users/{id}
+      delete:
+        payload: User
+`
+	cases := map[string]struct {
+		spec     string
+		expected []string
+	}{
+		"same": {
+			spec: old,
+		},
+		"breaking": {
+			spec: `
+models:
+  User:
+    fields:
+      - name: id
+        type: string
+        tag: 3
+      - name: email
+        type: string
+        required: true
+services:
+  users:
+    methods:
+      get:
+        payload: User
+        result: User
+        http: GET /v2/users/{id}
+`,
+			expected: []string{
+				"User.id: rpc:tag of field id changed from 1 to 3 (breaking grpc)",
+				"User.name: field name removed (breaking http, grpc)",
+				"User.email: required field email added (breaking http, grpc)",
+				"users.delete: method delete removed (breaking http, grpc)",
+				"users.get: route changed from \"GET /users/{id}\" to \"GET /v2/users/{id}\" (breaking http)",
+			},
+		},
+		"compatible": {
+			spec: old + `
+      list:
+        result: User
+  teams:
+    methods:
+      list: {}
+`,
+			expected: []string{
+				"users.list: method list added",
+				"teams: service teams added",
+			},
+		},
+	}
+	for k, tc := range cases {
+		o, n := New(), New()
+		for _, p := range []struct {
+			r    *Runtime
+			spec string
+		}{{o, old}, {n, tc.spec}} {
+			spec, err := ParseSpec([]byte(p.spec))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.r.Load(spec); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var actual []string
+		for _, c := range Compare(o, n) {
+			actual = append(actual, c.String())
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%s: got %#v, expected %#v", k, actual, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package runtime
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sort"
+
+	"go.zoe.im/goser/expr"
+)
+
+// ErrorResult is the error returned by the handlers for the errors declared
+// by the services and methods, it is encoded as the response body.
+type ErrorResult struct {
+	// Name is the name of this class of errors
+	Name string `json:"name"`
+	// ID is a unique identifier for this particular occurrence of the problem
+	ID string `json:"id"`
+	// Message is a human-readable explanation of the problem
+	Message string `json:"message"`
+	// Temporary is true if the error is temporary (i.e. retryable)
+	Temporary bool `json:"temporary"`
+	// Timeout is true if the error is due to a timeout
+	Timeout bool `json:"timeout"`
+	// Fault is true if the error is due to a server-side fault
+	Fault bool `json:"fault"`
+
+	status int
+	code   int
+}
+
+// Error returns the error message.
+func (e *ErrorResult) Error() string {
+	return e.Message
+}
+
+// Is returns true if target is an error result with the same name.
+func (e *ErrorResult) Is(target error) bool {
+	t, ok := target.(*ErrorResult)
+	return ok && t.Name == e.Name
+}
+
+// HTTPStatus returns the HTTP status of the error response.
+func (e *ErrorResult) HTTPStatus() int {
+	if e.status != 0 {
+		return e.status
+	}
+	if e.Fault {
+		return 500
+	}
+	return 400
+}
+
+// GRPCCode returns the gRPC code of the error response.
+func (e *ErrorResult) GRPCCode() int {
+	if e.code != 0 {
+		return e.code
+	}
+	if e.Fault {
+		return 13
+	}
+	return 2
+}
+
+// Error returns the error declared by the method or its service with the
+// given name, nil if there isn't any.
+func (m *Method) Error(name string) *expr.ErrorExpr {
+	for _, e := range m.Errors {
+		if e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// MakeError builds an occurrence of the error declared by the method or its
+// service with the given name and the message of err. It panics if the error
+// isn't declared, which is a programming error.
+func (m *Method) MakeError(name string, err error) *ErrorResult {
+	e := m.Error(name)
+	if e == nil {
+		panic(fmt.Sprintf("method %s has no error %s", m.Key(), name))
+	}
+	return &ErrorResult{
+		Name:      e.Name,
+		ID:        newErrorID(),
+		Message:   err.Error(),
+		Temporary: e.IsTemporary(),
+		Timeout:   e.IsTimeout(),
+		Fault:     e.IsFault(),
+		status:    e.HTTPStatus(),
+		code:      e.GRPCCode(),
+	}
+}
+
+// loadErrors returns the errors of the specs sorted by name, the errors of
+// specs override the errors of inherited with the same name. The errors are
+// validated with the validator of the load.
+func loadErrors(v *expr.AttributeValidator, ctx string, inherited []*expr.ErrorExpr, specs map[string]*ErrorSpec) ([]*expr.ErrorExpr, error) {
+	if len(specs) == 0 {
+		return inherited, nil
+	}
+	errs := make(map[string]*expr.ErrorExpr, len(inherited)+len(specs))
+	for _, e := range inherited {
+		errs[e.Name] = e
+	}
+	for name, es := range specs {
+		e := es.errorExpr(name)
+		if verr := v.ValidateError(e, ctx, nil); verr != nil && len(verr.Errors) > 0 {
+			return nil, verr
+		}
+		errs[name] = e
+	}
+	res := make([]*expr.ErrorExpr, 0, len(errs))
+	for _, e := range errs {
+		res = append(res, e)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// newErrorID returns a random identifier of error occurrences.
+func newErrorID() string {
+	b := make([]byte, 6)
+	io.ReadFull(rand.Reader, b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,303 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+)
+
+// Handler returns a http handler exposes all methods of the loaded services
+// as HTTP/JSON endpoints. Requests are validated against the method payload
+// definition and dispatched to the handler registered with Handle, or to the
+// in-memory store if no handler is registered. The requests of the methods
+// having security requirements are authorized first with the function set
+// with Authorize.
+func (r *Runtime) Handler() http.Handler {
+	h := r.newHandler(r.dispatch)
+	h.authorize = r.authorize
+	return h
+}
+
+// dispatch returns the registered handler of method, or the in-memory store
+// handler if no one registered.
+func (r *Runtime) dispatch(m *Method) HandlerFunc {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if fn, ok := r.handlers[m.Key()]; ok {
+		return fn
+	}
+	return r.store.handler(m)
+}
+
+func (r *Runtime) newHandler(dispatch func(*Method) HandlerFunc) *handler {
+	h := &handler{dispatch: dispatch}
+	services := r.Services()
+	if len(r.versions) > 0 {
+		services = nil
+		for _, v := range r.versions {
+			services = append(services, r.AtVersion(v).Services()...)
+		}
+	}
+	for _, svc := range services {
+		for _, m := range svc.Methods {
+			var warnings []string
+			if d := expr.Deprecation(svc.Meta); d != nil {
+				warnings = append(warnings, fmt.Sprintf("service %s is deprecated: %s", svc.Name, d))
+			}
+			if d := expr.Deprecation(m.Meta); d != nil {
+				warnings = append(warnings, fmt.Sprintf("method %s is deprecated: %s", m.Key(), d))
+			}
+			h.routes = append(h.routes, &route{
+				method:   m,
+				segments: splitPath(m.Route.Path),
+				warnings: warnings,
+			})
+		}
+	}
+	return h
+}
+
+// handler is the http handler of runtime
+type handler struct {
+	dispatch func(*Method) HandlerFunc
+	// authorize returns the context of the authorized requests, the
+	// requests are not authorized if nil
+	authorize func(*http.Request, *Method) (context.Context, error)
+	routes    []*route
+}
+
+// route matches requests for a method
+type route struct {
+	method   *Method
+	segments []string
+	// warnings about the deprecation of the method or its service
+	warnings []string
+}
+
+// ServeHTTP implements http.Handler
+func (h *handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	segments := splitPath(req.URL.Path)
+	var allowed bool
+	for _, rt := range h.routes {
+		params, ok := rt.match(segments)
+		if !ok {
+			continue
+		}
+		if rt.method.Route.Method != req.Method {
+			allowed = true
+			continue
+		}
+		for _, warning := range rt.warnings {
+			w.Header().Add("Warning", fmt.Sprintf("299 - %q", warning))
+		}
+		if len(rt.warnings) > 0 {
+			w.Header().Set("Deprecation", "true")
+		}
+		h.serve(w, req, rt.method, params)
+		return
+	}
+	if allowed {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	writeError(w, http.StatusNotFound, errors.New("not found"))
+}
+
+func (h *handler) serve(w http.ResponseWriter, req *http.Request, m *Method, params map[string]string) {
+	ctx := req.Context()
+	if h.authorize != nil {
+		var err error
+		if ctx, err = h.authorize(req, m); err != nil {
+			var er *ErrorResult
+			if errors.As(err, &er) {
+				writeJSON(w, er.HTTPStatus(), er)
+				return
+			}
+			writeError(w, http.StatusUnauthorized, err)
+			return
+		}
+	}
+
+	payload, err := decodePayload(req, m, params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if m.Payload != nil {
+		if err := expr.Validate(m.Payload, payload); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if m.Payload != nil {
+		for _, f := range deprecatedFields(m.Payload, payload, "") {
+			w.Header().Add("Warning", fmt.Sprintf("299 - %q", f))
+		}
+	}
+
+	res, err := h.dispatch(m)(ctx, payload)
+	if err != nil {
+		var er *ErrorResult
+		if errors.As(err, &er) {
+			writeJSON(w, er.HTTPStatus(), er)
+			return
+		}
+		switch {
+		case errors.Is(err, ErrNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case errors.Is(err, ErrConflict):
+			writeError(w, http.StatusConflict, err)
+		case errors.Is(err, ErrNotImplemented):
+			writeError(w, http.StatusNotImplemented, err)
+		default:
+			writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	if res == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// deprecatedFields returns the warnings about the deprecated fields set in
+// the value of the attribute, path is the path of the value.
+func deprecatedFields(att *expr.AttributeExpr, val interface{}, path string) []string {
+	var warnings []string
+	switch v := val.(type) {
+	case map[string]interface{}:
+		obj := expr.AsObject(att.Type)
+		if obj == nil {
+			return nil
+		}
+		for _, nat := range *obj {
+			fv, ok := v[nat.Name]
+			if !ok {
+				continue
+			}
+			fpath := nat.Name
+			if path != "" {
+				fpath = path + "." + nat.Name
+			}
+			if d := expr.Deprecation(nat.Attribute.Meta); d != nil {
+				warnings = append(warnings, fmt.Sprintf("field %s is deprecated: %s", fpath, d))
+			}
+			warnings = append(warnings, deprecatedFields(nat.Attribute, fv, fpath)...)
+		}
+	case []interface{}:
+		arr := expr.AsArray(att.Type)
+		if arr == nil {
+			return nil
+		}
+		for i, ev := range v {
+			warnings = append(warnings, deprecatedFields(arr.ElemType, ev, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return warnings
+}
+
+// match returns the path params if segments match the route
+func (rt *route) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(rt.segments) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, s := range rt.segments {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			params[s[1:len(s)-1]] = segments[i]
+			continue
+		}
+		if s != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// decodePayload decodes the request body, path and query params to payload,
+// the numbers are decoded as json.Number to keep the precision of the 64-bit
+// integers.
+func decodePayload(req *http.Request, m *Method, params map[string]string) (interface{}, error) {
+	var payload interface{}
+	if req.Body != nil {
+		dec := json.NewDecoder(req.Body)
+		dec.UseNumber()
+		err := dec.Decode(&payload)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+	}
+	if m.Payload == nil {
+		return payload, nil
+	}
+	obj := expr.AsObject(m.Payload.Type)
+	if obj == nil {
+		return payload, nil
+	}
+	values := req.URL.Query()
+	for k, v := range params {
+		values.Set(k, v)
+	}
+	if len(values) == 0 {
+		return payload, nil
+	}
+	mp, ok := payload.(map[string]interface{})
+	if !ok {
+		if payload != nil {
+			return payload, nil
+		}
+		mp = map[string]interface{}{}
+	}
+	for k := range values {
+		att := obj.Attribute(k)
+		if att == nil {
+			continue
+		}
+		v, err := coerce(att, values.Get(k))
+		if err != nil {
+			return nil, err
+		}
+		mp[k] = v
+	}
+	return mp, nil
+}
+
+// coerce converts the string value of path or query params to the same
+// value as decoded from JSON.
+func coerce(att *expr.AttributeExpr, s string) (interface{}, error) {
+	switch att.Type.Kind() {
+	case expr.BooleanKind:
+		return strconv.ParseBool(s)
+	case expr.IntKind, expr.Int32Kind, expr.Int64Kind,
+		expr.UIntKind, expr.UInt32Kind, expr.UInt64Kind,
+		expr.Float32Kind, expr.Float64Kind:
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return nil, err
+		}
+		return json.Number(s), nil
+	}
+	return s, nil
+}
+
+func splitPath(p string) []string {
+	return strings.Split(strings.Trim(p, "/"), "/")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,1003 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.zoe.im/goser/expr"
+)
+
+const testSpec = `
+name: users
+enums:
+  Status:
+    values:
+      - active
+      - value: inactive
+        name: disabled
+        tag: 3
+models:
+  User:
+    fields:
+      - name: id
+        type: string
+      - name: name
+        type: string
+        required: true
+      - name: role
+        type: string
+        enum: [admin, guest]
+      - name: age
+        type: int32
+      - name: score
+        type: int64
+      - name: status
+        type: Status
+  UserID:
+    fields:
+      - name: id
+        type: string
+        required: true
+services:
+  users:
+    methods:
+      create:
+        payload: User
+        result: User
+        http: POST /users
+      get:
+        payload: UserID
+        result: User
+        http: GET /users/{id}
+      hello:
+        payload: User
+        result: string
+`
+
+func newTestRuntime(t *testing.T) *Runtime {
+	spec, err := ParseSpec([]byte(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New()
+	if err := r.Load(spec); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestHandler(t *testing.T) {
+	r := newTestRuntime(t)
+	r.Handle("users.hello", func(_ context.Context, p interface{}) (interface{}, error) {
+		name := p.(map[string]interface{})["name"].(string)
+		if name == "bob" {
+			return nil, fmt.Errorf("%s: %w", name, ErrNotFound)
+		}
+		return "hello " + name, nil
+	})
+	h := r.Handler()
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		resp   string
+	}{
+		{"create", "POST", "/users", `{"name":"zoe","age":18}`, http.StatusOK, `{"age":18,"id":"1","name":"zoe"}`},
+		{"get", "GET", "/users/1", ``, http.StatusOK, `{"age":18,"id":"1","name":"zoe"}`},
+		{"not found", "GET", "/users/2", ``, http.StatusNotFound, `{"error":"not found"}`},
+		{"duplicate id", "POST", "/users", `{"id":"1","name":"eve"}`, http.StatusConflict, `{"error":"conflict"}`},
+		{"unchanged after conflict", "GET", "/users/1", ``, http.StatusOK, `{"age":18,"id":"1","name":"zoe"}`},
+		{"wrapped error", "POST", "/users/hello", `{"name":"bob"}`, http.StatusNotFound, `{"error":"bob: not found"}`},
+		{"missing required", "POST", "/users", `{"age":18}`, http.StatusBadRequest, `{"error":"name: attribute is required"}`},
+		{"not in enum", "POST", "/users", `{"name":"zoe","role":"root"}`, http.StatusBadRequest, `{"error":"role: value \"root\" must be one of \"admin\", \"guest\""}`},
+		{"not in named enum", "POST", "/users", `{"name":"zoe","status":"banned"}`, http.StatusBadRequest, `{"error":"status: value \"banned\" must be one of \"active\", \"inactive\""}`},
+		{"wrong type", "POST", "/users", `{"name":"zoe","age":1.5}`, http.StatusBadRequest, `{"error":"age: value 1.5 must be int32"}`},
+		{"int64 precision", "POST", "/users", `{"id":"3","name":"max","score":9007199254740993}`, http.StatusOK, `{"id":"3","name":"max","score":9007199254740993}`},
+		{"int64 overflow", "POST", "/users", `{"name":"max","score":9223372036854775808}`, http.StatusBadRequest, `{"error":"score: value 9223372036854775808 must be int64"}`},
+		{"method not allowed", "DELETE", "/users", ``, http.StatusMethodNotAllowed, `{"error":"method not allowed"}`},
+		{"registered handler", "POST", "/users/hello", `{"name":"zoe"}`, http.StatusOK, `"hello zoe"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != tc.status {
+				t.Errorf("got status %d, expected %d", w.Code, tc.status)
+			}
+			if actual := strings.TrimSpace(w.Body.String()); actual != tc.resp {
+				t.Errorf("got %s, expected %s", actual, tc.resp)
+			}
+		})
+	}
+}
+
+func TestStoreCopies(t *testing.T) {
+	s := NewStore()
+	payload := map[string]interface{}{"name": "zoe"}
+	rec, err := s.Create("users", payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := payload[IDField]; ok {
+		t.Errorf("the payload has been modified: %v", payload)
+	}
+	rec["name"] = "eve"
+	got, err := s.Get("users", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got["age"] = 18
+	if _, err := s.Update("users", "1", map[string]interface{}{"role": "admin"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"id": "1", "name": "zoe", "role": "admin"}
+	if actual := s.List("users")[0]; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got %v, expected %v", actual, expected)
+	}
+	if _, err := s.Create("users", map[string]interface{}{"id": "1"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("got error %v, expected %v", err, ErrConflict)
+	}
+	if rec, _ := s.Create("users", nil); idOf(rec) != "2" {
+		t.Errorf("got id %q, expected \"2\"", idOf(rec))
+	}
+}
+
+func TestParseType(t *testing.T) {
+	r := newTestRuntime(t)
+	cases := map[string]string{
+		"string":                    "string",
+		"User":                      "User",
+		"Status":                    "Status",
+		"[]User":                    "array<User>",
+		"array<int32>":              "array<int32>",
+		"map<string, array<int64>>": "map<string, array<int64>>",
+	}
+	for name, expected := range cases {
+		dt, err := r.parseType(name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if actual := expr.QualifiedTypeName(dt); actual != expected {
+			t.Errorf("%s: got %s, expected %s", name, actual, expected)
+		}
+	}
+	if _, err := r.parseType("Unknown"); err == nil {
+		t.Errorf("expected error for unknown type")
+	}
+}
+
+func TestLoadEnum(t *testing.T) {
+	r := newTestRuntime(t)
+	e := r.Enum("Status")
+	if e == nil {
+		t.Fatal("expected enum Status to be loaded")
+	}
+	cases := map[string]struct {
+		value interface{}
+		tag   int
+	}{
+		"active":   {"active", 1},
+		"disabled": {"inactive", 3},
+	}
+	for name, tc := range cases {
+		v := e.Value(name)
+		if v == nil {
+			t.Errorf("%s: value not found", name)
+			continue
+		}
+		if v.Value != tc.value || v.Tag != tc.tag {
+			t.Errorf("%s: got %#v with tag %d, expected %#v with tag %d", name, v.Value, v.Tag, tc.value, tc.tag)
+		}
+	}
+
+	spec, err := ParseSpec([]byte("enums:\n  Level:\n    type: float64\n    values: [1.5]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := New().Load(spec); err == nil {
+		t.Errorf("expected error for float enum")
+	}
+}
+
+func TestLoadUnion(t *testing.T) {
+	spec, err := ParseSpec([]byte(`
+unions:
+  Payment:
+    discriminator: kind
+    values:
+      - name: card
+        type: Card
+      - name: bank
+        type: Bank
+models:
+  Card:
+    fields:
+      - name: number
+        type: string
+  Bank:
+    fields:
+      - name: iban
+        type: string
+  Order:
+    fields:
+      - name: payment
+        type: Payment
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New()
+	if err := r.Load(spec); err != nil {
+		t.Fatal(err)
+	}
+	u := r.Union("Payment")
+	if u == nil || len(r.Unions()) != 1 {
+		t.Fatal("expected union Payment to be loaded")
+	}
+	if u.Discriminator != "kind" || len(u.Values) != 2 || u.Alternative("bank").Type != r.Model("Bank").Type {
+		t.Errorf("got union %#v", u)
+	}
+	if att := r.Model("Order").Type.Find("payment"); att == nil || att.Type != u {
+		t.Errorf("expected field payment to be the union")
+	}
+	if !u.IsCompatible(map[string]interface{}{"kind": "card", "number": "4242"}) {
+		t.Errorf("expected card to be compatible")
+	}
+
+	cases := map[string]string{
+		"primitive alternative": "unions:\n  U:\n    discriminator: kind\n    values:\n      - name: a\n        type: string\n",
+		"unknown type":          "unions:\n  U:\n    values:\n      - name: a\n        type: Unknown\n",
+		"no alternative":        "unions:\n  U:\n    values: []\n",
+	}
+	for k, c := range cases {
+		spec, err := ParseSpec([]byte(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := New().Load(spec); err == nil {
+			t.Errorf("%s: expected error", k)
+		}
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cases := map[string]struct {
+		spec     string
+		expected string
+	}{
+		"valid": {
+			spec: `
+enums:
+  Role:
+    values: [admin, member]
+models:
+  User:
+    fields:
+      - name: age
+        type: int32
+        default: 18
+        example: 42
+        minimum: 0
+      - name: role
+        type: Role
+        default: member
+      - name: address
+        type: Address
+        example: {city: Paris}
+  Address:
+    fields:
+      - name: city
+        type: string
+        required: true
+`,
+		},
+		"default type": {
+			spec: `
+models:
+  User:
+    fields:
+      - name: age
+        type: int32
+        default: old
+`,
+			expected: "model User: field age: invalid default: value \"old\" must be int32",
+		},
+		"example validation": {
+			spec: `
+models:
+  User:
+    fields:
+      - name: age
+        type: int32
+        minimum: 0
+        example: -1
+`,
+			expected: "model User: field age: invalid example: value -1 must be >= 0",
+		},
+		"enum default": {
+			spec: `
+enums:
+  Role:
+    values: [admin, member]
+models:
+  User:
+    fields:
+      - name: role
+        type: Role
+        default: root
+`,
+			expected: "model User: field role: invalid default: value \"root\" must be one of \"admin\", \"member\"",
+		},
+		"nested example": {
+			spec: `
+models:
+  User:
+    fields:
+      - name: address
+        type: Address
+        example: {zip: "75001"}
+  Address:
+    fields:
+      - name: city
+        type: string
+        required: true
+`,
+			expected: "model User: field address: invalid example: city: attribute is required",
+		},
+		"host variable": {
+			spec: `
+servers:
+  api:
+    hosts:
+      - name: prod
+        uris: ["https://{region}.example.com"]
+        variables:
+          - name: region
+            default: eu
+            enum: [us, asia]
+`,
+			expected: "server api host prod: field region: invalid default: value \"eu\" must be one of \"us\", \"asia\"",
+		},
+	}
+	for k, tc := range cases {
+		spec, err := ParseSpec([]byte(tc.spec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual string
+		if err := New().Load(spec); err != nil {
+			actual = err.Error()
+		}
+		if actual != tc.expected {
+			t.Errorf("%s: got %q, expected %q", k, actual, tc.expected)
+		}
+	}
+}
+
+func TestLoadInheritance(t *testing.T) {
+	cases := map[string]struct {
+		spec     string
+		expected string
+	}{
+		"extends": {
+			spec: `
+models:
+  Base:
+    fields:
+      - name: id
+        type: string
+        required: true
+  User:
+    extends: [Base]
+    fields:
+      - name: name
+        type: string
+`,
+		},
+		"extends string": {
+			spec: `
+models:
+  User:
+    extends: [string]
+    fields:
+      - name: name
+        type: string
+`,
+			expected: "model User: cannot extend string: only object types can be extended",
+		},
+		"cycle": {
+			spec: `
+models:
+  A:
+    extends: [B]
+  B:
+    extends: [A]
+`,
+			expected: "model A: type B: inheritance cycle through type A",
+		},
+	}
+	for k, tc := range cases {
+		spec, err := ParseSpec([]byte(tc.spec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := New()
+		var actual string
+		if err := r.Load(spec); err != nil {
+			actual = err.Error()
+		}
+		if actual != tc.expected {
+			t.Errorf("%s: got %q, expected %q", k, actual, tc.expected)
+		}
+	}
+
+	spec, _ := ParseSpec([]byte(cases["extends"].spec))
+	r := New()
+	if err := r.Load(spec); err != nil {
+		t.Fatal(err)
+	}
+	user := r.Model("User").Type
+	if user.Find("id") == nil || !user.IsRequired("id") {
+		t.Errorf("got %s, expected required id to be inherited from Base", expr.QualifiedTypeName(user))
+	}
+}
+
+func TestLoadDerived(t *testing.T) {
+	const models = `
+models:
+  User:
+    fields:
+      - name: id
+        type: string
+        required: true
+      - name: name
+        type: string
+        required: true
+      - name: email
+        type: string
+`
+	cases := map[string]struct {
+		spec     string
+		model    string
+		fields   []string
+		required []string
+		expected string
+	}{
+		"pick": {
+			spec: `
+  UserName:
+    from: User
+    pick: [id, name]
+`,
+			model:    "UserName",
+			fields:   []string{"id", "name"},
+			required: []string{"id", "name"},
+		},
+		"omit and own fields": {
+			spec: `
+  CreateUser:
+    from: User
+    omit: [id]
+    fields:
+      - name: password
+        type: string
+        required: true
+`,
+			model:    "CreateUser",
+			fields:   []string{"name", "email", "password"},
+			required: []string{"name", "password"},
+		},
+		"partial": {
+			spec: `
+  UpdateUser:
+    from: User
+    partial: true
+`,
+			model:  "UpdateUser",
+			fields: []string{"id", "name", "email"},
+		},
+		"required all and rename": {
+			spec: `
+  Person:
+    from: User
+    rename:
+      name: full_name
+    required_all: true
+`,
+			model:    "Person",
+			fields:   []string{"id", "full_name", "email"},
+			required: []string{"id", "full_name", "email"},
+		},
+		"chained": {
+			spec: `
+  UserName:
+    from: User
+    pick: [id, name]
+  UpdateUserName:
+    from: UserName
+    partial: true
+`,
+			model:  "UpdateUserName",
+			fields: []string{"id", "name"},
+		},
+		"unknown field": {
+			spec: `
+  UserName:
+    from: User
+    pick: [age]
+`,
+			expected: "model UserName: type User has no field \"age\"",
+		},
+		"cycle": {
+			spec: `
+  A:
+    from: B
+  B:
+    from: A
+`,
+			expected: "model A: derivation cycle through model B",
+		},
+	}
+	for k, tc := range cases {
+		spec, err := ParseSpec([]byte(models + tc.spec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := New()
+		var actual string
+		if err := r.Load(spec); err != nil {
+			actual = err.Error()
+		}
+		if actual != tc.expected {
+			t.Errorf("%s: got %q, expected %q", k, actual, tc.expected)
+			continue
+		}
+		if tc.expected != "" {
+			continue
+		}
+		derived := r.Model(tc.model)
+		var fields []string
+		for _, nat := range *expr.AsObject(derived.Type) {
+			fields = append(fields, nat.Name)
+		}
+		if !reflect.DeepEqual(fields, tc.fields) {
+			t.Errorf("%s: got fields %v, expected %v", k, fields, tc.fields)
+		}
+		var required []string
+		if derived.Type.Validation != nil {
+			required = derived.Type.Validation.Required
+		}
+		if !reflect.DeepEqual(required, tc.required) {
+			t.Errorf("%s: got required %v, expected %v", k, required, tc.required)
+		}
+	}
+}
+
+func TestHandlerDeprecation(t *testing.T) {
+	spec, err := ParseSpec([]byte(`
+models:
+  User:
+    fields:
+      - name: name
+        type: string
+      - name: login
+        type: string
+        deprecated:
+          reason: use name instead
+          since: v2
+services:
+  users:
+    methods:
+      create:
+        payload: User
+        result: User
+        http: POST /users
+      hello:
+        payload: User
+        http: POST /users/hello
+        deprecated: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New()
+	if err := r.Load(spec); err != nil {
+		t.Fatal(err)
+	}
+	h := r.Handler()
+
+	cases := map[string]struct {
+		path        string
+		body        string
+		warnings    []string
+		deprecation string
+	}{
+		"not deprecated": {"/users", `{"name":"zoe"}`, nil, ""},
+		"deprecated field": {"/users", `{"login":"zoe"}`,
+			[]string{`299 - "field login is deprecated: use name instead (since v2)"`}, ""},
+		"deprecated method": {"/users/hello", `{"name":"zoe"}`,
+			[]string{`299 - "method users.hello is deprecated: deprecated"`}, "true"},
+	}
+	for k, tc := range cases {
+		req := httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if actual := w.Header()["Warning"]; !reflect.DeepEqual(actual, tc.warnings) {
+			t.Errorf("%s: got warnings %#v, expected %#v", k, actual, tc.warnings)
+		}
+		if actual := w.Header().Get("Deprecation"); actual != tc.deprecation {
+			t.Errorf("%s: got deprecation %q, expected %q", k, actual, tc.deprecation)
+		}
+	}
+}
+
+func TestHandlerVersions(t *testing.T) {
+	spec, err := ParseSpec([]byte(`
+versions: [v2, v1]
+models:
+  User:
+    fields:
+      - name: name
+        type: string
+        required: true
+      - name: email
+        type: string
+        required: true
+        since: v2
+services:
+  users:
+    methods:
+      create:
+        payload: User
+        result: User
+        http: POST /users
+      hello:
+        payload: User
+        http: POST /users/hello
+        until: v1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New()
+	if err := r.Load(spec); err != nil {
+		t.Fatal(err)
+	}
+	if actual := r.Versions(); !reflect.DeepEqual(actual, []string{"v1", "v2"}) {
+		t.Errorf("got versions %v, expected [v1 v2]", actual)
+	}
+	r.Handle("users.hello", func(_ context.Context, p interface{}) (interface{}, error) {
+		return "hello", nil
+	})
+	h := r.Handler()
+
+	cases := map[string]struct {
+		path   string
+		body   string
+		status int
+	}{
+		"v1 create":           {"/v1/users", `{"name":"zoe"}`, http.StatusOK},
+		"v2 create":           {"/v2/users", `{"name":"zoe","email":"zoe@goser"}`, http.StatusOK},
+		"v2 missing required": {"/v2/users", `{"name":"zoe"}`, http.StatusBadRequest},
+		"v1 hello":            {"/v1/users/hello", `{"name":"zoe"}`, http.StatusOK},
+		"v2 hello":            {"/v2/users/hello", `{"name":"zoe","email":"zoe@goser"}`, http.StatusNotFound},
+		"no version":          {"/users", `{"name":"zoe"}`, http.StatusNotFound},
+	}
+	for k, tc := range cases {
+		req := httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != tc.status {
+			t.Errorf("%s: got status %d, expected %d: %s", k, w.Code, tc.status, w.Body.String())
+		}
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	spec, err := ParseSpec([]byte(`
+models:
+  User:
+    fields:
+      - name: name
+        type: string
+services:
+  users:
+    errors:
+      unavailable:
+        temporary: true
+    methods:
+      hello:
+        payload: User
+        http: POST /users/hello
+        errors:
+          unknown_user:
+            description: The user doesn't exist
+            status: 404
+          unavailable:
+            fault: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New()
+	if err := r.Load(spec); err != nil {
+		t.Fatal(err)
+	}
+	m := r.Service("users").Method("hello")
+	r.Handle("users.hello", func(_ context.Context, p interface{}) (interface{}, error) {
+		switch p.(map[string]interface{})["name"] {
+		case "nobody":
+			return nil, m.MakeError("unknown_user", errors.New("nobody is unknown"))
+		case "down":
+			return nil, fmt.Errorf("hello: %w", m.MakeError("unavailable", errors.New("service down")))
+		}
+		return "hello", nil
+	})
+	h := r.Handler()
+
+	cases := map[string]struct {
+		body   string
+		status int
+		name   string
+		fault  bool
+	}{
+		"success":         {`{"name":"zoe"}`, http.StatusOK, "", false},
+		"declared status": {`{"name":"nobody"}`, http.StatusNotFound, "unknown_user", false},
+		"method override": {`{"name":"down"}`, http.StatusInternalServerError, "unavailable", true},
+	}
+	for k, tc := range cases {
+		req := httptest.NewRequest("POST", "/users/hello", strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != tc.status {
+			t.Errorf("%s: got status %d, expected %d: %s", k, w.Code, tc.status, w.Body.String())
+		}
+		if tc.name == "" {
+			continue
+		}
+		var res ErrorResult
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Errorf("%s: invalid error result %s: %v", k, w.Body.String(), err)
+			continue
+		}
+		if res.Name != tc.name || res.Fault != tc.fault || res.ID == "" {
+			t.Errorf("%s: got %#v, expected error %s with fault %t", k, res, tc.name, tc.fault)
+		}
+		if !errors.Is(&res, &ErrorResult{Name: tc.name}) {
+			t.Errorf("%s: expected decoded error to match %s", k, tc.name)
+		}
+	}
+	if code := m.MakeError("unknown_user", errors.New("")).GRPCCode(); code != 5 {
+		t.Errorf("got gRPC code %d, expected 5", code)
+	}
+}
+
+func TestHandlerSecurity(t *testing.T) {
+	spec, err := ParseSpec([]byte(`
+schemes:
+  api_key:
+    kind: apikey
+    in: header
+    name: X-API-Key
+  jwt:
+    kind: jwt
+    scopes:
+      read: Read access
+security:
+  - api_key
+models:
+  User:
+    fields:
+      - name: name
+        type: string
+services:
+  users:
+    methods:
+      hello:
+        payload: User
+        result: string
+        http: POST /users/hello
+      find:
+        payload: User
+        result: string
+        http: POST /users/find
+        security:
+          - schemes: [jwt]
+            scopes: [read]
+          - none
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New()
+	if err := r.Load(spec); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Schemes()) != 2 || r.Scheme("jwt").In != "header" || r.Scheme("jwt").Name != "Authorization" {
+		t.Errorf("got schemes %#v", r.Schemes())
+	}
+	hello, find := r.Service("users").Method("hello"), r.Service("users").Method("find")
+	if len(hello.Security) != 1 || hello.Security[0].Schemes[0] != r.Scheme("api_key") {
+		t.Errorf("expected hello to inherit the api_key requirement, got %#v", hello.Security)
+	}
+	if len(find.Security) != 2 || find.Security[1].Schemes[0].Kind != expr.NoKind {
+		t.Errorf("expected find to override the requirements, got %#v", find.Security)
+	}
+
+	type key struct{}
+	r.Authorize(func(req *http.Request, m *Method) (context.Context, error) {
+		if req.Header.Get("X-API-Key") != "secret" {
+			return nil, ErrUnauthorized
+		}
+		return context.WithValue(req.Context(), key{}, m.Key()), nil
+	})
+	r.Handle("users.hello", func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return ctx.Value(key{}), nil
+	})
+	h := r.Handler()
+	cases := map[string]struct {
+		key    string
+		status int
+	}{
+		"authorized":   {"secret", http.StatusOK},
+		"unauthorized": {"wrong", http.StatusUnauthorized},
+	}
+	for k, tc := range cases {
+		req := httptest.NewRequest("POST", "/users/hello", strings.NewReader(`{"name":"zoe"}`))
+		req.Header.Set("X-API-Key", tc.key)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != tc.status {
+			t.Errorf("%s: got status %d, expected %d: %s", k, w.Code, tc.status, w.Body.String())
+		}
+	}
+	req := httptest.NewRequest("POST", "/users/hello", strings.NewReader(`{"name":"zoe"}`))
+	req.Header.Set("X-API-Key", "secret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if body := strings.TrimSpace(w.Body.String()); body != `"users.hello"` {
+		t.Errorf("expected the authorized context to be passed to the handler, got %s", body)
+	}
+
+	errCases := map[string]string{
+		"unknown scheme": "security: [basic]\n",
+		"unknown kind":   "schemes:\n  s:\n    kind: digest\n",
+		"unknown scope":  "schemes:\n  s:\n    kind: jwt\nsecurity:\n  - schemes: [s]\n    scopes: [admin]\n",
+		"reserved name":  "schemes:\n  none:\n    kind: basic\n",
+	}
+	for k, c := range errCases {
+		spec, err := ParseSpec([]byte(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := New().Load(spec); err == nil {
+			t.Errorf("%s: expected error", k)
+		}
+	}
+}
+
+func TestLoadServers(t *testing.T) {
+	cases := map[string]struct {
+		spec string
+		err  bool
+	}{
+		"valid": {`
+      hosts:
+        - name: production
+          uris: ["https://{version}.goser.zoe.im/users", "grpcs://{version}.goser.zoe.im"]
+          variables:
+            - name: version
+              enum: [v1, v2]
+        - name: development
+          uris: ["http://localhost:8080/users"]`, false},
+		"no default": {`
+      hosts:
+        - name: production
+          uris: ["https://{version}.goser.zoe.im/users"]
+          variables:
+            - name: version`, true},
+		"invalid scheme": {`
+      hosts:
+        - name: production
+          uris: ["ftp://goser.zoe.im"]`, true},
+		"unknown service": {`
+      services: [teams]
+      hosts:
+        - name: production
+          uris: ["https://goser.zoe.im"]`, true},
+	}
+	for k, tc := range cases {
+		spec, err := ParseSpec([]byte(`
+services:
+  users:
+    methods:
+      hello:
+        http: GET /hello
+servers:
+  usersvr:` + tc.spec))
+		if err != nil {
+			t.Fatalf("%s: %v", k, err)
+		}
+		r := New()
+		err = r.Load(spec)
+		if (err != nil) != tc.err {
+			t.Errorf("%s: got error %v, expected error %t", k, err, tc.err)
+		}
+		if err != nil {
+			continue
+		}
+		svr := r.Server("usersvr")
+		if svr == nil || len(svr.Hosts) != 2 || svr.Hosts[0].Name != "production" {
+			t.Errorf("%s: got server %#v, expected production and development hosts", k, svr)
+			continue
+		}
+		uri, err := svr.Hosts[0].URIString(svr.Hosts[0].URIs[0], nil)
+		if err != nil || uri != "https://v1.goser.zoe.im/users" {
+			t.Errorf("%s: got URI %q (%v), expected https://v1.goser.zoe.im/users", k, uri, err)
+		}
+	}
+}
+
+func TestCall(t *testing.T) {
+	spec, err := ParseSpec([]byte(`
+models:
+  User:
+    fields:
+      - name: name
+        type: string
+        required: true
+services:
+  users:
+    methods:
+      hello:
+        payload: User
+        result: string
+        meta:
+          graphql:query: [hello]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New()
+	if err := r.Load(spec); err != nil {
+		t.Fatal(err)
+	}
+	if v := r.Service("users").Method("hello").Meta["graphql:query"]; len(v) != 1 || v[0] != "hello" {
+		t.Errorf("got meta %v, expected graphql:query hello", v)
+	}
+	r.Handle("users.hello", func(_ context.Context, p interface{}) (interface{}, error) {
+		return "hello " + p.(map[string]interface{})["name"].(string), nil
+	})
+
+	cases := map[string]struct {
+		key      string
+		payload  interface{}
+		expected interface{}
+		err      bool
+	}{
+		"success":        {"users.hello", map[string]interface{}{"name": "zoe"}, "hello zoe", false},
+		"invalid":        {"users.hello", map[string]interface{}{}, nil, true},
+		"unknown method": {"users.bye", nil, nil, true},
+	}
+	for k, tc := range cases {
+		res, err := r.Call(context.Background(), tc.key, tc.payload)
+		if (err != nil) != tc.err {
+			t.Errorf("%s: got error %v, expected error %t", k, err, tc.err)
+		}
+		if res != tc.expected {
+			t.Errorf("%s: got %v, expected %v", k, res, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+
+	"go.zoe.im/goser/expr"
+)
+
+// MockHandler returns a http handler exposes all methods of the loaded
+// services like Handler, but answers every request with an example of the
+// method result instead of calling any handler.
+//
+// The examples defined in spec are used when present, otherwise examples are
+// generated pseudo-randomly. The generated examples only depend on the seed
+// and the method, so the same request always gets the same response.
+func (r *Runtime) MockHandler(seed string) http.Handler {
+	return r.newHandler(func(m *Method) HandlerFunc {
+		return mock(seed, m)
+	})
+}
+
+// mock returns a handler responds with the example of method result
+func mock(seed string, m *Method) HandlerFunc {
+	return func(context.Context, interface{}) (interface{}, error) {
+		if m.Result == nil {
+			return nil, nil
+		}
+		return m.Result.Example(expr.NewRandom(seed + ":" + m.Key())), nil
+	}
+}
@@ -0,0 +1,64 @@
+package runtime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const mockSpec = `
+models:
+  User:
+    fields:
+      - name: name
+        type: string
+        example: zoe
+      - name: role
+        type: string
+        enum: [admin, guest]
+      - name: tags
+        type: array<string>
+services:
+  users:
+    methods:
+      get:
+        result: User
+        http: GET /users/{id}
+      delete:
+        http: DELETE /users/{id}
+`
+
+func TestMockHandler(t *testing.T) {
+	spec, err := ParseSpec([]byte(mockSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New()
+	if err := r.Load(spec); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(h http.Handler, method, path string) (int, string) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code, strings.TrimSpace(w.Body.String())
+	}
+
+	code, first := get(r.MockHandler("seed"), "GET", "/users/1")
+	if code != http.StatusOK {
+		t.Fatalf("got status %d, expected %d", code, http.StatusOK)
+	}
+	if !strings.Contains(first, `"name":"zoe"`) {
+		t.Errorf("got %s, expected user example to be used", first)
+	}
+	if !strings.Contains(first, `"role":"admin"`) && !strings.Contains(first, `"role":"guest"`) {
+		t.Errorf("got %s, expected role to be one of the enum values", first)
+	}
+	if _, second := get(r.MockHandler("seed"), "GET", "/users/1"); second != first {
+		t.Errorf("got %s, expected %s with the same seed", second, first)
+	}
+	if code, _ := get(r.MockHandler("seed"), "DELETE", "/users/1"); code != http.StatusNoContent {
+		t.Errorf("got status %d, expected %d", code, http.StatusNoContent)
+	}
+}
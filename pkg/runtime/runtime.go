@@ -1,32 +1,808 @@
 package runtime
 
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.zoe.im/goser/expr"
+)
+
 // Runtime a the main factory to deal with all
 type Runtime struct {
+	api      API
+	versions []string
+	enums    map[string]*expr.EnumTypeExpr
+	unions   map[string]*expr.Union
 	models   map[string]*Model
 	services map[string]*Service
+	servers  map[string]*expr.ServerExpr
+	schemes  map[string]*expr.SchemeExpr
+
+	mu       sync.RWMutex
+	handlers map[string]HandlerFunc
+	auth     AuthFunc
+	store    *Store
+}
+
+// API presents the general information of the API, the first loaded spec
+// setting an information wins
+type API struct {
+	Name string
+	// Description of the API
+	Description string
+	// TermsOfService of the API, the terms or a link to them
+	TermsOfService string
+	// Contact information of the API, nil if not set
+	Contact *ContactSpec
+	// License of the API, nil if not set
+	License *LicenseSpec
+	// Docs links to the external documentation of the API, nil if not set
+	Docs *expr.DocsExpr
 }
 
 // Model presents struct of model like message in proto
 type Model struct {
 	Name string
+	// Description of the model
+	Description string
+	// Meta settings of the model
+	Meta Meta
+	// Type is the user type described by the model
+	Type *expr.UserTypeExpr
 }
 
 // Service presents struct of service like service in proto
 type Service struct {
 	Name string
+	// Description of the service
+	Description string
+	// Docs links to the external documentation of the service
+	Docs *expr.DocsExpr
+	// Methods of the service sorted by name
+	Methods []*Method
+	// Errors returned by all the methods of the service sorted by name
+	Errors []*expr.ErrorExpr
+	// Security requirements of the methods of the service, one of them
+	// must be satisfied
+	Security []*expr.SecurityExpr
+	// Meta is a list of key/value pairs
+	Meta expr.MetaExpr
 }
 
-// Load data from a spec
+// Method presents a method of service like rpc in proto
+type Method struct {
+	Name string
+	// Service is the name of service the method belongs to
+	Service string
+	// Description of the method
+	Description string
+	// Docs links to the external documentation of the method
+	Docs *expr.DocsExpr
+	// Payload describes the request, nil if the method
+	// doesn't accept any payload
+	Payload *expr.AttributeExpr
+	// Result describes the response, nil if the method
+	// doesn't return any result
+	Result *expr.AttributeExpr
+	// Route is the HTTP route of the method
+	Route *Route
+	// Errors returned by the method including the service errors, sorted
+	// by name
+	Errors []*expr.ErrorExpr
+	// Security requirements of the method including the inherited ones,
+	// one of them must be satisfied
+	Security []*expr.SecurityExpr
+	// Meta is a list of key/value pairs
+	Meta expr.MetaExpr
+}
+
+// Route presents a HTTP route
+type Route struct {
+	// Method is the HTTP method, e.g. GET, POST
+	Method string
+	// Path is the HTTP path which may contain {param}
+	Path string
+}
+
+// HandlerFunc handles a method call with the decoded payload, the payload has
+// been validated against the method payload definition.
+type HandlerFunc func(ctx context.Context, payload interface{}) (interface{}, error)
+
+// Key returns the key of method with format service.method
+func (m *Method) Key() string {
+	return m.Service + "." + m.Name
+}
+
+// Method returns the method of service with the given name, nil if not exits
+func (s *Service) Method(name string) *Method {
+	for _, m := range s.Methods {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// Load data from a spec, the types of the spec are validated once with the
+// validator of the load.
 func (r *Runtime) Load(spec *Spec) error {
+	validator := expr.NewAttributeValidator()
+	r.loadAPI(spec)
+	for _, v := range spec.Versions {
+		r.addVersion(v)
+	}
+
+	enames := make([]string, 0, len(spec.Enums))
+	for name := range spec.Enums {
+		enames = append(enames, name)
+	}
+	sort.Strings(enames)
+	for _, name := range enames {
+		if _, ok := r.enums[name]; ok {
+			return fmt.Errorf("enum %s already exists", name)
+		}
+		e, err := r.loadEnum(name, spec.Enums[name])
+		if err != nil {
+			return err
+		}
+		r.enums[name] = e
+	}
+
+	names := make([]string, 0, len(spec.Models))
+	for name := range spec.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	unames := make([]string, 0, len(spec.Unions))
+	for name := range spec.Unions {
+		unames = append(unames, name)
+	}
+	sort.Strings(unames)
+
+	// create all models and unions first so that fields can refer to any
+	// of them
+	for _, name := range names {
+		if _, ok := r.models[name]; ok {
+			return fmt.Errorf("model %s already exists", name)
+		}
+		if _, ok := r.enums[name]; ok {
+			return fmt.Errorf("model %s already exists as enum", name)
+		}
+		if _, ok := r.unions[name]; ok {
+			return fmt.Errorf("model %s already exists as union", name)
+		}
+		ms := spec.Models[name]
+		r.models[name] = &Model{
+			Name:        name,
+			Description: ms.Description,
+			Meta:        ms.Meta,
+			Type: &expr.UserTypeExpr{
+				TypeName: name,
+				AttributeExpr: &expr.AttributeExpr{
+					Description: ms.Description,
+					Type:        &expr.Object{},
+					Meta:        ms.Deprecated.deprecate(nil),
+				},
+			},
+		}
+	}
+	for _, name := range unames {
+		if _, ok := r.unions[name]; ok {
+			return fmt.Errorf("union %s already exists", name)
+		}
+		if _, ok := r.enums[name]; ok {
+			return fmt.Errorf("union %s already exists as enum", name)
+		}
+		if _, ok := r.models[name]; ok {
+			return fmt.Errorf("union %s already exists as model", name)
+		}
+		r.unions[name] = &expr.Union{TypeName: name, Discriminator: spec.Unions[name].Discriminator}
+	}
+	for _, name := range unames {
+		if err := r.loadUnion(r.unions[name], spec.Unions[name]); err != nil {
+			return err
+		}
+	}
+	for _, name := range names {
+		if err := r.loadModel(r.models[name], spec.Models[name]); err != nil {
+			return err
+		}
+	}
+	// derive the models once all the models are loaded, the sources of
+	// the derived models are derived first
+	derived := make(map[string]bool, len(names))
+	for _, name := range names {
+		if err := r.deriveModel(spec, name, derived); err != nil {
+			return err
+		}
+	}
+	// resolve the inheritance once all the models are loaded
+	for _, name := range names {
+		if err := r.models[name].Type.ResolveBases(); err != nil {
+			return fmt.Errorf("model %s: %v", name, err)
+		}
+	}
+	// validate the defaults and the examples once the types of the fields
+	// are complete
+	for _, name := range names {
+		for _, nat := range *expr.AsObject(r.models[name].Type) {
+			if err := validateDefaults(nat.Name, nat.Attribute); err != nil {
+				return fmt.Errorf("model %s: %v", name, err)
+			}
+		}
+	}
+	// validate the unions once the alternatives are complete
+	for _, name := range unames {
+		att := &expr.AttributeExpr{Type: r.unions[name]}
+		if verr := validator.Validate(att, "", nil); verr != nil && len(verr.Errors) > 0 {
+			return fmt.Errorf("union %s: %v", name, verr)
+		}
+	}
+
+	scnames := make([]string, 0, len(spec.Schemes))
+	for name := range spec.Schemes {
+		scnames = append(scnames, name)
+	}
+	sort.Strings(scnames)
+	for _, name := range scnames {
+		if _, ok := r.schemes[name]; ok {
+			return fmt.Errorf("security scheme %s already exists", name)
+		}
+		s, err := loadScheme(name, spec.Schemes[name])
+		if err != nil {
+			return err
+		}
+		r.schemes[name] = s
+	}
+	security, err := r.loadSecurity("api", nil, spec.Security)
+	if err != nil {
+		return err
+	}
+
+	snames := make([]string, 0, len(spec.Services))
+	for name := range spec.Services {
+		snames = append(snames, name)
+	}
+	sort.Strings(snames)
+	for _, name := range snames {
+		if _, ok := r.services[name]; ok {
+			return fmt.Errorf("service %s already exists", name)
+		}
+		svc, err := r.loadService(validator, name, spec.Services[name], security)
+		if err != nil {
+			return err
+		}
+		r.services[name] = svc
+	}
+
+	svrnames := make([]string, 0, len(spec.Servers))
+	for name := range spec.Servers {
+		svrnames = append(svrnames, name)
+	}
+	sort.Strings(svrnames)
+	for _, name := range svrnames {
+		if _, ok := r.servers[name]; ok {
+			return fmt.Errorf("server %s already exists", name)
+		}
+		svr, err := r.loadServer(name, spec.Servers[name])
+		if err != nil {
+			return err
+		}
+		r.servers[name] = svr
+	}
+
+	return nil
+}
+
+// loadAPI sets the information of the API which are not set yet.
+func (r *Runtime) loadAPI(spec *Spec) {
+	if r.api.Name == "" {
+		r.api.Name = spec.Name
+	}
+	if r.api.Description == "" {
+		r.api.Description = spec.Description
+	}
+	if r.api.TermsOfService == "" {
+		r.api.TermsOfService = spec.TermsOfService
+	}
+	if r.api.Contact == nil {
+		r.api.Contact = spec.Contact
+	}
+	if r.api.License == nil {
+		r.api.License = spec.License
+	}
+	if r.api.Docs == nil {
+		r.api.Docs = spec.Docs.docsExpr()
+	}
+}
+
+// loadServer creates the server expression and validates it, the services
+// of the server must exist.
+func (r *Runtime) loadServer(name string, ss *ServerSpec) (*expr.ServerExpr, error) {
+	svr := &expr.ServerExpr{
+		Name:        name,
+		Description: ss.Description,
+		Services:    ss.Services,
+	}
+	for _, sn := range ss.Services {
+		if _, ok := r.services[sn]; !ok {
+			return nil, fmt.Errorf("server %s: service %s not found", name, sn)
+		}
+	}
+	for _, hs := range ss.Hosts {
+		h := &expr.HostExpr{
+			Name:        hs.Name,
+			ServerName:  name,
+			Description: hs.Description,
+			Variables:   &expr.AttributeExpr{Type: &expr.Object{}},
+		}
+		for _, u := range hs.URIs {
+			h.URIs = append(h.URIs, expr.URIExpr(u))
+		}
+		obj := expr.AsObject(h.Variables.Type)
+		for _, f := range hs.Variables {
+			vs := *f
+			if vs.Type == "" {
+				vs.Type = "string"
+			}
+			att, err := r.attribute(&vs)
+			if err == nil {
+				err = validateDefaults(f.Name, att)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("server %s host %s: %v", name, hs.Name, err)
+			}
+			obj.Set(f.Name, att)
+		}
+		svr.Hosts = append(svr.Hosts, h)
+	}
+	if verr := svr.Validate("", nil); verr != nil && len(verr.Errors) > 0 {
+		return nil, verr
+	}
+	return svr, nil
+}
+
+func (r *Runtime) loadEnum(name string, es *EnumSpec) (*expr.EnumTypeExpr, error) {
+	base := expr.String
+	if es.Type != "" {
+		t, ok := primitives[es.Type]
+		if !ok {
+			return nil, fmt.Errorf("enum %s: unknown type %q", name, es.Type)
+		}
+		base = t.(expr.Primitive)
+	}
+	values := make([]*expr.EnumValueExpr, len(es.Values))
+	for i, v := range es.Values {
+		values[i] = &expr.EnumValueExpr{
+			Value:       v.Value,
+			Name:        v.Name,
+			Description: v.Description,
+			Tag:         v.Tag,
+			Meta:        v.Deprecated.deprecate(nil),
+		}
+	}
+	e := expr.NewEnumTypeExpr(name, base, values...)
+	e.Description = es.Description
+	e.Meta = es.Deprecated.deprecate(e.Meta)
+	if verr := e.Validate("", nil); verr != nil && len(verr.Errors) > 0 {
+		return nil, verr
+	}
+	return e, nil
+}
+
+func (r *Runtime) loadUnion(u *expr.Union, us *UnionSpec) error {
+	for _, f := range us.Values {
+		att, err := r.attribute(f)
+		if err != nil {
+			return fmt.Errorf("union %s: %v", u.TypeName, err)
+		}
+		u.Values = append(u.Values, &expr.NamedAttributeExpr{Name: f.Name, Attribute: att})
+	}
+	return nil
+}
+
+func (r *Runtime) loadModel(m *Model, ms *ModelSpec) error {
+	obj := expr.AsObject(m.Type)
+	var required []string
+	for _, f := range ms.Fields {
+		if f.Name == "" {
+			return fmt.Errorf("model %s: field name cannot be empty", m.Name)
+		}
+		att, err := r.attribute(f)
+		if err != nil {
+			return fmt.Errorf("model %s: %v", m.Name, err)
+		}
+		obj.Set(f.Name, att)
+		if f.Required {
+			required = append(required, f.Name)
+		}
+	}
+	if len(required) > 0 {
+		m.Type.Validation = &expr.ValidationExpr{Required: required}
+	}
+	for _, n := range ms.Extends {
+		t, err := r.parseType(n)
+		if err != nil {
+			return fmt.Errorf("model %s extends: %v", m.Name, err)
+		}
+		m.Type.Bases = append(m.Type.Bases, t)
+	}
+	for _, n := range ms.References {
+		t, err := r.parseType(n)
+		if err != nil {
+			return fmt.Errorf("model %s references: %v", m.Name, err)
+		}
+		m.Type.References = append(m.Type.References, t)
+	}
+	return nil
+}
 
+// deriveModel derives the model with the given name from the model of its
+// "from" setting if any. The fields of the model are added to the derived
+// fields and override the fields with the same names.
+func (r *Runtime) deriveModel(spec *Spec, name string, derived map[string]bool) error {
+	ms := spec.Models[name]
+	if ms.From == "" {
+		return nil
+	}
+	if done, ok := derived[name]; ok {
+		if !done {
+			return fmt.Errorf("model %s: derivation cycle through model %s", name, ms.From)
+		}
+		return nil
+	}
+	derived[name] = false
+	if _, ok := spec.Models[ms.From]; ok {
+		if err := r.deriveModel(spec, ms.From, derived); err != nil {
+			return err
+		}
+	}
+	src, err := r.parseType(ms.From)
+	if err != nil {
+		return fmt.Errorf("model %s from: %v", name, err)
+	}
+	m := r.models[name]
+	ut, err := deriveType(name, src, ms)
+	if err != nil {
+		return fmt.Errorf("model %s: %v", name, err)
+	}
+	att := ut.AttributeExpr
+	obj := expr.AsObject(att.Type)
+	for _, nat := range *expr.AsObject(m.Type) {
+		obj.Set(nat.Name, nat.Attribute)
+	}
+	if m.Type.Validation != nil {
+		if att.Validation == nil {
+			att.Validation = &expr.ValidationExpr{}
+		}
+		for _, n := range m.Type.Validation.Required {
+			att.Validation.AddRequired(n)
+		}
+	}
+	if ms.Description != "" {
+		att.Description = ms.Description
+	} else {
+		m.Description = att.Description
+	}
+	// the deprecation of the source model is not derived
+	delete(att.Meta, expr.DeprecatedMeta)
+	delete(att.Meta, expr.DeprecatedSinceMeta)
+	att.Meta = ms.Deprecated.deprecate(att.Meta)
+	att.Bases, att.References = m.Type.Bases, m.Type.References
+	m.Type.AttributeExpr = att
+	derived[name] = true
 	return nil
 }
 
+// deriveType applies the transformations of the model spec to the source type.
+func deriveType(name string, src expr.DataType, ms *ModelSpec) (*expr.UserTypeExpr, error) {
+	var (
+		ut  *expr.UserTypeExpr
+		err error
+	)
+	if ms.Partial && ms.RequiredAll {
+		return nil, fmt.Errorf("partial and required_all cannot be used together")
+	}
+	if len(ms.Pick) > 0 {
+		ut, err = expr.Pick(name, src, ms.Pick...)
+	} else {
+		ut, err = expr.Omit(name, src, ms.Omit...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(ms.Pick) > 0 && len(ms.Omit) > 0 {
+		if ut, err = expr.Omit(name, ut, ms.Omit...); err != nil {
+			return nil, err
+		}
+	}
+	if len(ms.Rename) > 0 {
+		if ut, err = expr.RenameFields(name, ut, ms.Rename); err != nil {
+			return nil, err
+		}
+	}
+	if ms.Partial {
+		return expr.Partial(name, ut)
+	}
+	if ms.RequiredAll {
+		return expr.RequiredAll(name, ut)
+	}
+	return ut, nil
+}
+
+func (r *Runtime) loadService(v *expr.AttributeValidator, name string, ss *ServiceSpec, security []*expr.SecurityExpr) (*Service, error) {
+	svc := &Service{
+		Name:        name,
+		Description: ss.Description,
+		Docs:        ss.Docs.docsExpr(),
+		Meta:        versionMeta(ss.Deprecated.deprecate(nil), ss.Since, ss.Until),
+	}
+	errs, err := loadErrors(v, "service "+name, nil, ss.Errors)
+	if err != nil {
+		return nil, err
+	}
+	svc.Errors = errs
+	if svc.Security, err = r.loadSecurity("service "+name, security, ss.Security); err != nil {
+		return nil, err
+	}
+
+	mnames := make([]string, 0, len(ss.Methods))
+	for n := range ss.Methods {
+		mnames = append(mnames, n)
+	}
+	sort.Strings(mnames)
+	for _, n := range mnames {
+		ms := ss.Methods[n]
+		var meta expr.MetaExpr
+		if len(ms.Meta) > 0 {
+			meta = make(expr.MetaExpr, len(ms.Meta))
+			for k, v := range ms.Meta {
+				meta[k] = v
+			}
+		}
+		m := &Method{
+			Name:        n,
+			Service:     name,
+			Description: ms.Description,
+			Docs:        ms.Docs.docsExpr(),
+			Meta:        versionMeta(ms.Deprecated.deprecate(meta), ms.Since, ms.Until),
+		}
+		if ms.Payload != "" {
+			t, err := r.parseType(ms.Payload)
+			if err != nil {
+				return nil, fmt.Errorf("method %s.%s payload: %v", name, n, err)
+			}
+			m.Payload = &expr.AttributeExpr{Type: t}
+		}
+		if ms.Result != "" {
+			t, err := r.parseType(ms.Result)
+			if err != nil {
+				return nil, fmt.Errorf("method %s.%s result: %v", name, n, err)
+			}
+			m.Result = &expr.AttributeExpr{Type: t}
+		}
+		if m.Errors, err = loadErrors(v, "method "+m.Key(), svc.Errors, ms.Errors); err != nil {
+			return nil, err
+		}
+		if m.Security, err = r.loadSecurity("method "+m.Key(), svc.Security, ms.Security); err != nil {
+			return nil, err
+		}
+		route, err := parseRoute(ms.HTTP)
+		if err != nil {
+			return nil, fmt.Errorf("method %s.%s: %v", name, n, err)
+		}
+		if route == nil {
+			route = &Route{Method: "POST", Path: "/" + name + "/" + n}
+		}
+		m.Route = route
+		svc.Methods = append(svc.Methods, m)
+	}
+	return svc, nil
+}
+
+// parseRoute parses a route with format "METHOD /path", it returns nil if
+// s is empty.
+func parseRoute(s string) (*Route, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Fields(s)
+	if len(parts) != 2 || !strings.HasPrefix(parts[1], "/") {
+		return nil, fmt.Errorf("invalid http route %q, must be \"METHOD /path\"", s)
+	}
+	return &Route{Method: strings.ToUpper(parts[0]), Path: parts[1]}, nil
+}
+
+// Model returns the model with the given name, nil if not exits
+func (r *Runtime) Model(name string) *Model {
+	return r.models[name]
+}
+
+// Models returns all models sorted by name
+func (r *Runtime) Models() []*Model {
+	res := make([]*Model, 0, len(r.models))
+	for _, m := range r.models {
+		res = append(res, m)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// Enum returns the enum with the given name, nil if not exits
+func (r *Runtime) Enum(name string) *expr.EnumTypeExpr {
+	return r.enums[name]
+}
+
+// Enums returns all enums sorted by name
+func (r *Runtime) Enums() []*expr.EnumTypeExpr {
+	res := make([]*expr.EnumTypeExpr, 0, len(r.enums))
+	for _, e := range r.enums {
+		res = append(res, e)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].TypeName < res[j].TypeName })
+	return res
+}
+
+// Union returns the union with the given name, nil if not exits
+func (r *Runtime) Union(name string) *expr.Union {
+	return r.unions[name]
+}
+
+// Unions returns all unions sorted by name
+func (r *Runtime) Unions() []*expr.Union {
+	res := make([]*expr.Union, 0, len(r.unions))
+	for _, u := range r.unions {
+		res = append(res, u)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].TypeName < res[j].TypeName })
+	return res
+}
+
+// Service returns the service with the given name, nil if not exits
+func (r *Runtime) Service(name string) *Service {
+	return r.services[name]
+}
+
+// Services returns all services sorted by name
+func (r *Runtime) Services() []*Service {
+	res := make([]*Service, 0, len(r.services))
+	for _, s := range r.services {
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// Server returns the server with the given name, nil if not exits
+func (r *Runtime) Server(name string) *expr.ServerExpr {
+	return r.servers[name]
+}
+
+// Servers returns all servers sorted by name
+func (r *Runtime) Servers() []*expr.ServerExpr {
+	res := make([]*expr.ServerExpr, 0, len(r.servers))
+	for _, s := range r.servers {
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// API returns the general information of the API
+func (r *Runtime) API() *API {
+	return &r.api
+}
+
+// Versions returns the API versions served side by side sorted from the
+// oldest to the newest, empty if the API is not versioned
+func (r *Runtime) Versions() []string {
+	return r.versions
+}
+
+// addVersion adds the version if not exists and keeps the versions sorted
+func (r *Runtime) addVersion(v string) {
+	for _, ver := range r.versions {
+		if ver == v {
+			return
+		}
+	}
+	r.versions = append(r.versions, v)
+	sort.Slice(r.versions, func(i, j int) bool {
+		return expr.CompareVersions(r.versions[i], r.versions[j]) < 0
+	})
+}
+
+// AtVersion returns a runtime with the models, services and methods available
+// in the API version, the routes of the methods are prefixed with the version.
+// The returned runtime is not versioned and shares the handlers and the store
+// of r.
+func (r *Runtime) AtVersion(version string) *Runtime {
+	res := &Runtime{
+		api:      r.api,
+		enums:    r.enums,
+		unions:   r.unions,
+		models:   make(map[string]*Model, len(r.models)),
+		services: make(map[string]*Service, len(r.services)),
+		servers:  r.servers,
+		schemes:  r.schemes,
+		handlers: r.handlers,
+		auth:     r.auth,
+		store:    r.store,
+	}
+	for name, m := range r.models {
+		vm := *m
+		vm.Type = expr.AtVersion(m.Type, version).(*expr.UserTypeExpr)
+		res.models[name] = &vm
+	}
+	for name, svc := range r.services {
+		if !expr.InVersion(svc.Meta, version) {
+			continue
+		}
+		vsvc := *svc
+		vsvc.Methods = nil
+		for _, m := range svc.Methods {
+			if !expr.InVersion(m.Meta, version) {
+				continue
+			}
+			vm := *m
+			if m.Payload != nil {
+				vm.Payload = expr.AttributeAtVersion(m.Payload, version)
+			}
+			if m.Result != nil {
+				vm.Result = expr.AttributeAtVersion(m.Result, version)
+			}
+			vm.Route = &Route{Method: m.Route.Method, Path: "/" + version + m.Route.Path}
+			vsvc.Methods = append(vsvc.Methods, &vm)
+		}
+		res.services[name] = &vsvc
+	}
+	return res
+}
+
+// Handle registers the handler of method with key service.method, the
+// registered handler takes precedence over the in-memory store.
+func (r *Runtime) Handle(key string, h HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[key] = h
+}
+
+// Call validates the payload and calls the handler of the method with key
+// service.method. It lets the transports other than HTTP, e.g. GraphQL, call
+// the methods with the same handlers.
+func (r *Runtime) Call(ctx context.Context, key string, payload interface{}) (interface{}, error) {
+	var m *Method
+	if i := strings.LastIndex(key, "."); i > 0 {
+		if svc := r.Service(key[:i]); svc != nil {
+			m = svc.Method(key[i+1:])
+		}
+	}
+	if m == nil {
+		return nil, fmt.Errorf("unknown method %q", key)
+	}
+	if m.Payload != nil {
+		if err := expr.Validate(m.Payload, payload); err != nil {
+			return nil, err
+		}
+	}
+	return r.dispatch(m)(ctx, payload)
+}
+
 // New init a runtime
 func New() *Runtime {
 	r := &Runtime{
+		enums:    map[string]*expr.EnumTypeExpr{},
+		unions:   map[string]*expr.Union{},
 		models:   map[string]*Model{},
 		services: map[string]*Service{},
+		servers:  map[string]*expr.ServerExpr{},
+		schemes:  map[string]*expr.SchemeExpr{},
+		handlers: map[string]HandlerFunc{},
+		store:    NewStore(),
 	}
 
 	return r
@@ -0,0 +1,158 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+)
+
+// ErrUnauthorized is the error of the requests rejected by the AuthFunc
+var ErrUnauthorized = errors.New("unauthorized")
+
+// NoSecurity is the scheme name of the requirements making the security of
+// the methods optional
+const NoSecurity = "none"
+
+// AuthFunc authorizes the HTTP request of a method having security
+// requirements, it returns the context of the authorized request which is
+// passed to the handler of the method.
+type AuthFunc func(req *http.Request, m *Method) (context.Context, error)
+
+// Authorize sets the function authorizing the requests of the methods having
+// security requirements, the requests are not authorized if not set.
+func (r *Runtime) Authorize(fn AuthFunc) {
+	r.mu.Lock()
+	r.auth = fn
+	r.mu.Unlock()
+}
+
+// authorize authorizes the request of method with the function set with
+// Authorize if the method has security requirements.
+func (r *Runtime) authorize(req *http.Request, m *Method) (context.Context, error) {
+	r.mu.RLock()
+	fn := r.auth
+	r.mu.RUnlock()
+	if fn == nil || len(m.Security) == 0 {
+		return req.Context(), nil
+	}
+	return fn(req, m)
+}
+
+// Scheme returns the security scheme with the given name, nil if not exits
+func (r *Runtime) Scheme(name string) *expr.SchemeExpr {
+	return r.schemes[name]
+}
+
+// Schemes returns all security schemes sorted by name
+func (r *Runtime) Schemes() []*expr.SchemeExpr {
+	res := make([]*expr.SchemeExpr, 0, len(r.schemes))
+	for _, s := range r.schemes {
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].SchemeName < res[j].SchemeName })
+	return res
+}
+
+// schemeKinds maps the kinds of schemes used in spec to scheme kinds
+var schemeKinds = map[string]expr.SchemeKind{
+	"basic":  expr.BasicAuthKind,
+	"apikey": expr.APIKeyKind,
+	"jwt":    expr.JWTKind,
+	"oauth2": expr.OAuth2Kind,
+}
+
+// flowKinds maps the kinds of OAuth2 flows used in spec to flow kinds
+var flowKinds = map[string]expr.FlowKind{
+	"authorization_code": expr.AuthorizationCodeFlowKind,
+	"implicit":           expr.ImplicitFlowKind,
+	"password":           expr.PasswordFlowKind,
+	"client_credentials": expr.ClientCredentialsFlowKind,
+}
+
+// loadScheme returns the security scheme of the spec with the given name, the
+// JWT and OAuth2 tokens are read from the Authorization header by default.
+func loadScheme(name string, ss *SchemeSpec) (*expr.SchemeExpr, error) {
+	if name == NoSecurity {
+		return nil, fmt.Errorf("security scheme %s: the name is reserved", name)
+	}
+	kind, ok := schemeKinds[strings.ToLower(ss.Kind)]
+	if !ok {
+		return nil, fmt.Errorf("security scheme %s: unknown kind %q, must be basic, apikey, jwt or oauth2", name, ss.Kind)
+	}
+	s := &expr.SchemeExpr{
+		Kind:        kind,
+		SchemeName:  name,
+		Description: ss.Description,
+		In:          ss.In,
+		Name:        ss.Name,
+	}
+	if (kind == expr.JWTKind || kind == expr.OAuth2Kind) && s.In == "" && s.Name == "" {
+		s.In, s.Name = "header", "Authorization"
+	}
+	scopes := make([]string, 0, len(ss.Scopes))
+	for sc := range ss.Scopes {
+		scopes = append(scopes, sc)
+	}
+	sort.Strings(scopes)
+	for _, sc := range scopes {
+		s.Scopes = append(s.Scopes, &expr.ScopeExpr{Name: sc, Description: ss.Scopes[sc]})
+	}
+	for _, fs := range ss.Flows {
+		fk, ok := flowKinds[fs.Kind]
+		if !ok {
+			return nil, fmt.Errorf("security scheme %s: unknown flow %q", name, fs.Kind)
+		}
+		s.Flows = append(s.Flows, &expr.FlowExpr{
+			Kind:             fk,
+			AuthorizationURL: fs.AuthorizationURL,
+			TokenURL:         fs.TokenURL,
+			RefreshURL:       fs.RefreshURL,
+		})
+	}
+	if verr := s.Validate("", nil); verr != nil && len(verr.Errors) > 0 {
+		return nil, verr
+	}
+	return s, nil
+}
+
+// loadSecurity returns the security requirements of the specs, the inherited
+// requirements are returned if specs is empty.
+func (r *Runtime) loadSecurity(ctx string, inherited []*expr.SecurityExpr, specs []*SecuritySpec) ([]*expr.SecurityExpr, error) {
+	if len(specs) == 0 {
+		return inherited, nil
+	}
+	res := make([]*expr.SecurityExpr, len(specs))
+	for i, ss := range specs {
+		if len(ss.Schemes) == 0 {
+			return nil, fmt.Errorf("%s: security requirement must list at least one scheme", ctx)
+		}
+		sec := &expr.SecurityExpr{Scopes: ss.Scopes}
+		for _, n := range ss.Schemes {
+			if n == NoSecurity {
+				sec.Schemes = append(sec.Schemes, &expr.SchemeExpr{Kind: expr.NoKind, SchemeName: n})
+				continue
+			}
+			s, ok := r.schemes[n]
+			if !ok {
+				return nil, fmt.Errorf("%s: unknown security scheme %q", ctx, n)
+			}
+			sec.Schemes = append(sec.Schemes, s)
+		}
+		for _, sc := range sec.Scopes {
+			found := false
+			for _, s := range sec.Schemes {
+				found = found || s.Scope(sc) != nil
+			}
+			if !found {
+				return nil, fmt.Errorf("%s: scope %q is not declared by the schemes %s", ctx, sc, strings.Join(ss.Schemes, ", "))
+			}
+		}
+		res[i] = sec
+	}
+	return res, nil
+}
@@ -1,8 +1,50 @@
 package runtime
 
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+
+	"go.zoe.im/goser/expr"
+)
+
 // Spec present all things in yaml
 type Spec struct {
 	Meta Meta `yaml:"_" json:"_"`
+
+	// Name of the API
+	Name string `yaml:"name" json:"name"`
+	// Description of the API
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Versions lists the API versions served side by side, the
+	// routes of every version are prefixed with the version
+	Versions []string `yaml:"versions,omitempty" json:"versions,omitempty"`
+	// TermsOfService of the API, the terms or a link to them
+	TermsOfService string `yaml:"terms_of_service,omitempty" json:"terms_of_service,omitempty"`
+	// Contact information of the API
+	Contact *ContactSpec `yaml:"contact,omitempty" json:"contact,omitempty"`
+	// License of the API
+	License *LicenseSpec `yaml:"license,omitempty" json:"license,omitempty"`
+	// Docs links to the external documentation of the API
+	Docs *DocsSpec `yaml:"docs,omitempty" json:"docs,omitempty"`
+	// Enums contains all named enums, keyed by enum name
+	Enums map[string]*EnumSpec `yaml:"enums,omitempty" json:"enums,omitempty"`
+	// Unions contains all named unions, keyed by union name
+	Unions map[string]*UnionSpec `yaml:"unions,omitempty" json:"unions,omitempty"`
+	// Models contains all models, keyed by model name
+	Models map[string]*ModelSpec `yaml:"models" json:"models"`
+	// Services contains all services, keyed by service name
+	Services map[string]*ServiceSpec `yaml:"services" json:"services"`
+	// Servers contains the servers hosting the services, keyed by
+	// server name
+	Servers map[string]*ServerSpec `yaml:"servers,omitempty" json:"servers,omitempty"`
+	// Schemes contains the security schemes, keyed by scheme name
+	Schemes map[string]*SchemeSpec `yaml:"schemes,omitempty" json:"schemes,omitempty"`
+	// Security lists the security requirements of the services of the
+	// spec, one of them must be satisfied
+	Security []*SecuritySpec `yaml:"security,omitempty" json:"security,omitempty"`
 }
 
 // Meta presents settings option
@@ -14,3 +56,442 @@ type Meta struct {
 	// request
 	Writable bool
 }
+
+// ContactSpec presents the contact information of the API in yaml
+type ContactSpec struct {
+	// Name of the contact person or organization
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Email of the contact
+	Email string `yaml:"email,omitempty" json:"email,omitempty"`
+	// URL of the contact
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+}
+
+// LicenseSpec presents the license of the API in yaml
+type LicenseSpec struct {
+	// Name of the license, e.g. MIT
+	Name string `yaml:"name" json:"name"`
+	// URL of the license text
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+}
+
+// DocsSpec presents a link to external documentation in yaml
+type DocsSpec struct {
+	// Description of the documentation
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// URL of the documentation
+	URL string `yaml:"url" json:"url"`
+}
+
+// docsExpr returns the docs expression, nil if d is nil.
+func (d *DocsSpec) docsExpr() *expr.DocsExpr {
+	if d == nil {
+		return nil
+	}
+	return &expr.DocsExpr{Description: d.Description, URL: d.URL}
+}
+
+// ModelSpec presents a model in yaml
+type ModelSpec struct {
+	Meta Meta `yaml:"_" json:"_"`
+
+	// Description of the model
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Deprecated marks the model as deprecated
+	Deprecated *DeprecationSpec `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	// Extends lists the models whose fields are added to the model,
+	// the fields of the model override the fields with the same names
+	Extends []string `yaml:"extends,omitempty" json:"extends,omitempty"`
+	// References lists the models the fields with the same names
+	// inherit the description, validations, default and meta from
+	References []string `yaml:"references,omitempty" json:"references,omitempty"`
+	// From is the model the model is derived from using Pick, Omit,
+	// Rename, Partial and RequiredAll, applied in this order
+	From string `yaml:"from,omitempty" json:"from,omitempty"`
+	// Pick lists the fields of From kept in the model
+	Pick []string `yaml:"pick,omitempty" json:"pick,omitempty"`
+	// Omit lists the fields of From removed from the model
+	Omit []string `yaml:"omit,omitempty" json:"omit,omitempty"`
+	// Rename maps the names of fields of From to their new names
+	Rename map[string]string `yaml:"rename,omitempty" json:"rename,omitempty"`
+	// Partial makes all the fields of From optional
+	Partial bool `yaml:"partial,omitempty" json:"partial,omitempty"`
+	// RequiredAll makes all the fields of From required
+	RequiredAll bool `yaml:"required_all,omitempty" json:"required_all,omitempty"`
+	// Fields of the model, order matters
+	Fields []*FieldSpec `yaml:"fields" json:"fields"`
+}
+
+// EnumSpec presents a named enum in yaml
+type EnumSpec struct {
+	// Description of the enum
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Deprecated marks the enum as deprecated
+	Deprecated *DeprecationSpec `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	// Type of the values, string (default) or an integer type
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// Values of the enum, order matters
+	Values []*EnumValueSpec `yaml:"values" json:"values"`
+}
+
+// EnumValueSpec presents a value of enum in yaml, it can be
+// the value itself or a map:
+//
+//     values:
+//       - active
+//       - value: inactive
+//         name: disabled
+//         tag: 3
+//
+type EnumValueSpec struct {
+	// Value of the enum value
+	Value interface{} `yaml:"value" json:"value"`
+	// Name of the value, defaults to the value
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Description of the value
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Tag is the number of the value in proto enum
+	Tag int `yaml:"tag,omitempty" json:"tag,omitempty"`
+	// Deprecated marks the value as deprecated
+	Deprecated *DeprecationSpec `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+}
+
+// UnmarshalYAML accepts both the value itself and the full map.
+func (v *EnumValueSpec) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	if _, ok := value.(map[interface{}]interface{}); !ok {
+		v.Value = value
+		return nil
+	}
+	type plain EnumValueSpec
+	return unmarshal((*plain)(v))
+}
+
+// UnionSpec presents a named union in yaml, the values are the alternatives
+// of the union:
+//
+//     unions:
+//       PaymentMethod:
+//         discriminator: kind
+//         values:
+//           - name: card
+//             type: Card
+//           - name: bank_account
+//             type: Bank
+//
+type UnionSpec struct {
+	// Discriminator is the name of the field holding the name of the
+	// alternative, the alternatives must be models if set
+	Discriminator string `yaml:"discriminator,omitempty" json:"discriminator,omitempty"`
+	// Values of the union, order matters
+	Values []*FieldSpec `yaml:"values" json:"values"`
+}
+
+// DeprecationSpec presents a deprecation in yaml, it can be
+// a boolean, the reason or a map:
+//
+//     deprecated: true
+//     deprecated: use email instead
+//     deprecated:
+//       reason: use email instead
+//       since: v2
+//
+type DeprecationSpec struct {
+	// Reason of the deprecation
+	Reason string `yaml:"reason,omitempty" json:"reason,omitempty"`
+	// Since is the version since which it is deprecated
+	Since string `yaml:"since,omitempty" json:"since,omitempty"`
+}
+
+// UnmarshalYAML accepts a boolean, the reason and the full map.
+func (d *DeprecationSpec) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case bool:
+		if !v {
+			return fmt.Errorf("deprecated must be true, a reason or a map")
+		}
+		return nil
+	case string:
+		d.Reason = v
+		return nil
+	}
+	type plain DeprecationSpec
+	return unmarshal((*plain)(d))
+}
+
+// deprecate marks the element with the given meta as deprecated if d is not
+// nil and returns the meta.
+func (d *DeprecationSpec) deprecate(meta expr.MetaExpr) expr.MetaExpr {
+	if d == nil {
+		return meta
+	}
+	return expr.Deprecate(meta, d.Reason, d.Since)
+}
+
+// versionMeta sets the version meta of an element available from since
+// until until, and returns the meta.
+func versionMeta(meta expr.MetaExpr, since, until string) expr.MetaExpr {
+	if since == "" && until == "" {
+		return meta
+	}
+	if meta == nil {
+		meta = make(expr.MetaExpr)
+	}
+	if since != "" {
+		meta[expr.VersionSinceMeta] = []string{since}
+	}
+	if until != "" {
+		meta[expr.VersionUntilMeta] = []string{until}
+	}
+	return meta
+}
+
+// FieldSpec presents a field of model in yaml
+type FieldSpec struct {
+	// Name of the field
+	Name string `yaml:"name" json:"name"`
+	// Type of the field, a primitive name, a model name,
+	// array<type> or map<key, type>
+	Type string `yaml:"type" json:"type"`
+	// Tag is the rpc tag of the field
+	Tag int `yaml:"tag,omitempty" json:"tag,omitempty"`
+	// Description of the field
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Docs links to the external documentation of the field
+	Docs *DocsSpec `yaml:"docs,omitempty" json:"docs,omitempty"`
+	// Required marks the field as required
+	Required bool `yaml:"required,omitempty" json:"required,omitempty"`
+	// Deprecated marks the field as deprecated
+	Deprecated *DeprecationSpec `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	// Since is the first API version the field is available in
+	Since string `yaml:"since,omitempty" json:"since,omitempty"`
+	// Until is the last API version the field is available in
+	Until string `yaml:"until,omitempty" json:"until,omitempty"`
+	// Default value of the field
+	Default interface{} `yaml:"default,omitempty" json:"default,omitempty"`
+	// Example value of the field
+	Example interface{} `yaml:"example,omitempty" json:"example,omitempty"`
+
+	// Enum lists the accepted values
+	Enum []interface{} `yaml:"enum,omitempty" json:"enum,omitempty"`
+	// Format of string value, e.g. email, uuid
+	Format string `yaml:"format,omitempty" json:"format,omitempty"`
+	// Pattern is a RE2 regular expression the value must match
+	Pattern string `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	// Minimum value of number
+	Minimum *float64 `yaml:"minimum,omitempty" json:"minimum,omitempty"`
+	// Maximum value of number
+	Maximum *float64 `yaml:"maximum,omitempty" json:"maximum,omitempty"`
+	// MinLength of string, bytes, array or map
+	MinLength *int `yaml:"min_length,omitempty" json:"min_length,omitempty"`
+	// MaxLength of string, bytes, array or map
+	MaxLength *int `yaml:"max_length,omitempty" json:"max_length,omitempty"`
+	// Precision is the maximum number of digits of decimal or bigint
+	Precision *int `yaml:"precision,omitempty" json:"precision,omitempty"`
+	// Scale is the maximum number of digits after the decimal point
+	Scale *int `yaml:"scale,omitempty" json:"scale,omitempty"`
+
+	// Meta is a list of key/value pairs
+	Meta map[string][]string `yaml:"meta,omitempty" json:"meta,omitempty"`
+}
+
+// ServiceSpec presents a service in yaml
+type ServiceSpec struct {
+	// Description of the service
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Docs links to the external documentation of the service
+	Docs *DocsSpec `yaml:"docs,omitempty" json:"docs,omitempty"`
+	// Deprecated marks the service as deprecated
+	Deprecated *DeprecationSpec `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	// Since is the first API version the service is available in
+	Since string `yaml:"since,omitempty" json:"since,omitempty"`
+	// Until is the last API version the service is available in
+	Until string `yaml:"until,omitempty" json:"until,omitempty"`
+	// Errors returned by all the methods of the service, keyed by error name
+	Errors map[string]*ErrorSpec `yaml:"errors,omitempty" json:"errors,omitempty"`
+	// Security lists the security requirements of the methods of the
+	// service, default to the requirements of the spec
+	Security []*SecuritySpec `yaml:"security,omitempty" json:"security,omitempty"`
+	// Methods of the service, keyed by method name
+	Methods map[string]*MethodSpec `yaml:"methods" json:"methods"`
+}
+
+// MethodSpec presents a method of service in yaml
+type MethodSpec struct {
+	// Description of the method
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Docs links to the external documentation of the method
+	Docs *DocsSpec `yaml:"docs,omitempty" json:"docs,omitempty"`
+	// Deprecated marks the method as deprecated
+	Deprecated *DeprecationSpec `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	// Since is the first API version the method is available in
+	Since string `yaml:"since,omitempty" json:"since,omitempty"`
+	// Until is the last API version the method is available in
+	Until string `yaml:"until,omitempty" json:"until,omitempty"`
+	// Errors returned by the method, keyed by error name
+	Errors map[string]*ErrorSpec `yaml:"errors,omitempty" json:"errors,omitempty"`
+	// Security lists the security requirements of the method, default
+	// to the requirements of the service
+	Security []*SecuritySpec `yaml:"security,omitempty" json:"security,omitempty"`
+	// Payload is the model name of request
+	Payload string `yaml:"payload,omitempty" json:"payload,omitempty"`
+	// Result is the model name of response
+	Result string `yaml:"result,omitempty" json:"result,omitempty"`
+	// HTTP is the route of method, e.g. "GET /users/{id}"
+	HTTP string `yaml:"http,omitempty" json:"http,omitempty"`
+
+	// Meta is a list of key/value pairs, e.g. graphql:query
+	Meta map[string][]string `yaml:"meta,omitempty" json:"meta,omitempty"`
+}
+
+// ServerSpec presents a server hosting services in yaml
+type ServerSpec struct {
+	// Description of the server
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Services hosted by the server, all the services if empty
+	Services []string `yaml:"services,omitempty" json:"services,omitempty"`
+	// Hosts of the server, the first host is the default one
+	Hosts []*HostSpec `yaml:"hosts" json:"hosts"`
+}
+
+// HostSpec presents a host of server in yaml
+type HostSpec struct {
+	// Name of the host, e.g. production
+	Name string `yaml:"name" json:"name"`
+	// Description of the host
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// URIs of the host which may contain {variable}, e.g.
+	// "https://{version}.example.com/calc"
+	URIs []string `yaml:"uris" json:"uris"`
+	// Variables used in the URIs, they must have a default
+	// value or an enum, the type defaults to string
+	Variables []*FieldSpec `yaml:"variables,omitempty" json:"variables,omitempty"`
+}
+
+// ErrorSpec presents an error returned by methods in yaml
+type ErrorSpec struct {
+	// Description of the error
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Temporary marks the error as temporary (i.e. retryable)
+	Temporary bool `yaml:"temporary,omitempty" json:"temporary,omitempty"`
+	// Timeout marks the error as due to a timeout
+	Timeout bool `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// Fault marks the error as due to a server-side fault
+	Fault bool `yaml:"fault,omitempty" json:"fault,omitempty"`
+	// Status is the HTTP status of the error responses
+	Status int `yaml:"status,omitempty" json:"status,omitempty"`
+	// Code is the gRPC code of the error responses
+	Code int `yaml:"code,omitempty" json:"code,omitempty"`
+}
+
+// errorExpr returns the error expression with the given name.
+func (es *ErrorSpec) errorExpr(name string) *expr.ErrorExpr {
+	e := expr.NewErrorExpr(name, nil)
+	if es == nil {
+		return e
+	}
+	e.Description = es.Description
+	meta := expr.MetaExpr{}
+	if es.Temporary {
+		meta[expr.ErrorTemporaryMeta] = nil
+	}
+	if es.Timeout {
+		meta[expr.ErrorTimeoutMeta] = nil
+	}
+	if es.Fault {
+		meta[expr.ErrorFaultMeta] = nil
+	}
+	if es.Status != 0 {
+		meta[expr.ErrorHTTPStatusMeta] = []string{strconv.Itoa(es.Status)}
+	}
+	if es.Code != 0 {
+		meta[expr.ErrorGRPCCodeMeta] = []string{strconv.Itoa(es.Code)}
+	}
+	if len(meta) > 0 {
+		e.Meta = meta
+	}
+	return e
+}
+
+// SchemeSpec presents a security scheme in yaml
+type SchemeSpec struct {
+	// Kind of the scheme, basic, apikey, jwt or oauth2
+	Kind string `yaml:"kind" json:"kind"`
+	// Description of the scheme
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// In is the location of the credentials, header, query or cookie,
+	// JWT and OAuth2 tokens default to the Authorization header
+	In string `yaml:"in,omitempty" json:"in,omitempty"`
+	// Name of the header, query parameter or cookie
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Scopes of JWT and OAuth2 schemes, keyed by scope name
+	Scopes map[string]string `yaml:"scopes,omitempty" json:"scopes,omitempty"`
+	// Flows of OAuth2 schemes
+	Flows []*FlowSpec `yaml:"flows,omitempty" json:"flows,omitempty"`
+}
+
+// FlowSpec presents an OAuth2 flow in yaml
+type FlowSpec struct {
+	// Kind of the flow, authorization_code, implicit, password or
+	// client_credentials
+	Kind string `yaml:"kind" json:"kind"`
+	// AuthorizationURL of the implicit and authorization code flows
+	AuthorizationURL string `yaml:"authorization_url,omitempty" json:"authorization_url,omitempty"`
+	// TokenURL of the password, client credentials and authorization
+	// code flows
+	TokenURL string `yaml:"token_url,omitempty" json:"token_url,omitempty"`
+	// RefreshURL used to refresh the tokens
+	RefreshURL string `yaml:"refresh_url,omitempty" json:"refresh_url,omitempty"`
+}
+
+// SecuritySpec presents a security requirement in yaml, it can be the name
+// of a scheme or a map, all the schemes of a requirement must be satisfied:
+//
+//     security:
+//       - api_key
+//       - schemes: [jwt, api_key]
+//         scopes: [read]
+//       - none
+//
+type SecuritySpec struct {
+	// Schemes of the requirement, "none" makes the security optional
+	Schemes []string `yaml:"schemes" json:"schemes"`
+	// Scopes required by the JWT and OAuth2 schemes
+	Scopes []string `yaml:"scopes,omitempty" json:"scopes,omitempty"`
+}
+
+// UnmarshalYAML accepts both the name of a scheme and the full map.
+func (s *SecuritySpec) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		s.Schemes = []string{name}
+		return nil
+	}
+	type plain SecuritySpec
+	return unmarshal((*plain)(s))
+}
+
+// ParseSpec parses a spec from yaml content
+func ParseSpec(data []byte) (*Spec, error) {
+	spec := &Spec{}
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// LoadSpec reads a spec from a yaml file
+func LoadSpec(path string) (*Spec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSpec(data)
+}
@@ -0,0 +1,183 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrNotFound is returned when the record doesn't exist
+	ErrNotFound = errors.New("not found")
+	// ErrNotImplemented is returned when the method has no handler
+	ErrNotImplemented = errors.New("not implemented")
+	// ErrConflict is returned when a record with the same id already exists
+	ErrConflict = errors.New("conflict")
+)
+
+// IDField is the name of field used as the identifier of records
+const IDField = "id"
+
+// Store is an in-memory store used as the default handler of
+// CRUD methods.
+type Store struct {
+	mu          sync.RWMutex
+	seq         int
+	collections map[string]map[string]map[string]interface{}
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{
+		collections: map[string]map[string]map[string]interface{}{},
+	}
+}
+
+// Create inserts a copy of the record to collection, an id is generated if
+// the record doesn't have one. It returns ErrConflict if a record with the
+// same id already exists.
+func (s *Store) Create(coll string, rec map[string]interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.collections[coll]
+	if !ok {
+		c = map[string]map[string]interface{}{}
+		s.collections[coll] = c
+	}
+	rec = copyRecord(rec)
+	id := idOf(rec)
+	if id == "" {
+		for {
+			s.seq++
+			id = fmt.Sprintf("%d", s.seq)
+			if _, ok := c[id]; !ok {
+				break
+			}
+		}
+		rec[IDField] = id
+	} else if _, ok := c[id]; ok {
+		return nil, ErrConflict
+	}
+	c[id] = rec
+	return copyRecord(rec), nil
+}
+
+// Get returns the record with the given id
+func (s *Store) Get(coll, id string) (map[string]interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rec, ok := s.collections[coll][id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyRecord(rec), nil
+}
+
+// List returns all records of collection sorted by id
+func (s *Store) List(coll string) []map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make([]map[string]interface{}, 0, len(s.collections[coll]))
+	for _, rec := range s.collections[coll] {
+		res = append(res, copyRecord(rec))
+	}
+	sort.Slice(res, func(i, j int) bool { return idOf(res[i]) < idOf(res[j]) })
+	return res
+}
+
+// Update merges the fields of rec to the record with the given id
+func (s *Store) Update(coll, id string, rec map[string]interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.collections[coll][id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	for k, v := range rec {
+		old[k] = v
+	}
+	old[IDField] = id
+	return copyRecord(old), nil
+}
+
+// Delete removes the record with the given id
+func (s *Store) Delete(coll, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.collections[coll][id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.collections[coll], id)
+	return nil
+}
+
+// handler returns the default handler of method which dispatches to the
+// store according to the method name prefix.
+func (s *Store) handler(m *Method) HandlerFunc {
+	coll := m.Service
+	name := strings.ToLower(m.Name)
+	switch {
+	case hasAnyPrefix(name, "create", "add", "new"):
+		return func(_ context.Context, p interface{}) (interface{}, error) {
+			rec, _ := p.(map[string]interface{})
+			if rec == nil {
+				rec = map[string]interface{}{}
+			}
+			return s.Create(coll, rec)
+		}
+	case hasAnyPrefix(name, "list", "all"):
+		return func(_ context.Context, _ interface{}) (interface{}, error) {
+			return s.List(coll), nil
+		}
+	case hasAnyPrefix(name, "get", "show", "read", "find"):
+		return func(_ context.Context, p interface{}) (interface{}, error) {
+			return s.Get(coll, idOf(p))
+		}
+	case hasAnyPrefix(name, "update", "patch", "edit"):
+		return func(_ context.Context, p interface{}) (interface{}, error) {
+			rec, _ := p.(map[string]interface{})
+			return s.Update(coll, idOf(p), rec)
+		}
+	case hasAnyPrefix(name, "delete", "remove"):
+		return func(_ context.Context, p interface{}) (interface{}, error) {
+			return nil, s.Delete(coll, idOf(p))
+		}
+	}
+	return func(context.Context, interface{}) (interface{}, error) {
+		return nil, ErrNotImplemented
+	}
+}
+
+// copyRecord returns a shallow copy of the record so that the records of the
+// store are never shared with the callers.
+func copyRecord(rec map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(rec)+1)
+	for k, v := range rec {
+		res[k] = v
+	}
+	return res
+}
+
+func idOf(v interface{}) string {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	id, ok := m[IDField]
+	if !ok || id == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", id)
+}
+
+func hasAnyPrefix(s string, prefixes ...string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	"go.zoe.im/goser/expr"
+)
+
+// primitives maps the type names used in spec to primitive types
+var primitives = map[string]expr.DataType{
+	"bool":    expr.Boolean,
+	"boolean": expr.Boolean,
+	"int":     expr.Int,
+	"int32":   expr.Int32,
+	"int64":   expr.Int64,
+	"uint":    expr.UInt,
+	"uint32":  expr.UInt32,
+	"uint64":  expr.UInt64,
+	"float32": expr.Float32,
+	"float64": expr.Float64,
+	"string":  expr.String,
+	"bytes":   expr.Bytes,
+	"any":     expr.Any,
+
+	"timestamp": expr.Timestamp,
+	"date":      expr.Date,
+	"duration":  expr.Duration,
+	"decimal":   expr.Decimal,
+	"bigint":    expr.BigInt,
+}
+
+// parseType converts the type name used in spec to data type,
+// the name can be a primitive, a model or a composite type:
+//
+//     "string"
+//     "User"         a model, an enum or a union
+//     "array<User>"  or "[]User"
+//     "map<string, array<int32>>"
+//
+func (r *Runtime) parseType(name string) (expr.DataType, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return expr.String, nil
+	}
+	if t, ok := primitives[name]; ok {
+		return t, nil
+	}
+	if strings.HasPrefix(name, "[]") {
+		elem, err := r.parseType(name[2:])
+		if err != nil {
+			return nil, err
+		}
+		return &expr.Array{ElemType: &expr.AttributeExpr{Type: elem}}, nil
+	}
+	if i := strings.Index(name, "<"); i > 0 && strings.HasSuffix(name, ">") {
+		args := splitTypeArgs(name[i+1 : len(name)-1])
+		switch name[:i] {
+		case "array":
+			if len(args) != 1 {
+				return nil, fmt.Errorf("array type %q must have 1 element type", name)
+			}
+			elem, err := r.parseType(args[0])
+			if err != nil {
+				return nil, err
+			}
+			return &expr.Array{ElemType: &expr.AttributeExpr{Type: elem}}, nil
+		case "map":
+			if len(args) != 2 {
+				return nil, fmt.Errorf("map type %q must have key and element types", name)
+			}
+			key, err := r.parseType(args[0])
+			if err != nil {
+				return nil, err
+			}
+			elem, err := r.parseType(args[1])
+			if err != nil {
+				return nil, err
+			}
+			return &expr.Map{
+				KeyType:  &expr.AttributeExpr{Type: key},
+				ElemType: &expr.AttributeExpr{Type: elem},
+			}, nil
+		}
+	}
+	if e, ok := r.enums[name]; ok {
+		return e, nil
+	}
+	if u, ok := r.unions[name]; ok {
+		return u, nil
+	}
+	if m, ok := r.models[name]; ok {
+		return m.Type, nil
+	}
+	return nil, fmt.Errorf("unknown type %q", name)
+}
+
+// splitTypeArgs splits the arguments of a composite type on the top level
+// commas only.
+func splitTypeArgs(s string) []string {
+	var (
+		args  []string
+		depth int
+		start int
+	)
+	for i, c := range s {
+		switch c {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(args, strings.TrimSpace(s[start:]))
+}
+
+// attribute creates the attribute expression of a field
+func (r *Runtime) attribute(f *FieldSpec) (*expr.AttributeExpr, error) {
+	t, err := r.parseType(f.Type)
+	if err != nil {
+		return nil, fmt.Errorf("field %s: %v", f.Name, err)
+	}
+	att := &expr.AttributeExpr{
+		Type:        t,
+		Description: f.Description,
+		Docs:        f.Docs.docsExpr(),
+		Meta:        expr.MetaExpr{},
+	}
+	for k, v := range f.Meta {
+		att.Meta[k] = v
+	}
+	att.Meta = f.Deprecated.deprecate(att.Meta)
+	att.Meta = versionMeta(att.Meta, f.Since, f.Until)
+	if f.Tag > 0 {
+		att.Meta["rpc:tag"] = []string{fmt.Sprintf("%d", f.Tag)}
+	}
+	if f.Default != nil {
+		att.SetDefault(normalize(f.Default))
+	}
+	if f.Example != nil {
+		att.UserExamples = append(att.UserExamples, &expr.ExampleExpr{
+			Summary: "default",
+			Value:   normalize(f.Example),
+		})
+	}
+	if len(f.Enum) > 0 || f.Format != "" || f.Pattern != "" ||
+		f.Minimum != nil || f.Maximum != nil ||
+		f.MinLength != nil || f.MaxLength != nil ||
+		f.Precision != nil || f.Scale != nil {
+		enum := make([]interface{}, len(f.Enum))
+		for i, v := range f.Enum {
+			enum[i] = normalize(v)
+		}
+		if len(enum) == 0 {
+			enum = nil
+		}
+		att.Validation = &expr.ValidationExpr{
+			Values:    enum,
+			Format:    expr.ValidationFormat(f.Format),
+			Pattern:   f.Pattern,
+			Minimum:   f.Minimum,
+			Maximum:   f.Maximum,
+			MinLength: f.MinLength,
+			MaxLength: f.MaxLength,
+			Precision: f.Precision,
+			Scale:     f.Scale,
+		}
+	}
+	return att, nil
+}
+
+// validateDefaults validates the default value and the examples of the field
+// attribute against the attribute, the types of the fields referring to
+// models must be loaded.
+func validateDefaults(name string, att *expr.AttributeExpr) error {
+	if att.DefaultValue != nil {
+		if err := expr.Validate(att, att.DefaultValue); err != nil {
+			return fmt.Errorf("field %s: invalid default: %v", name, err)
+		}
+	}
+	for _, ex := range att.UserExamples {
+		if err := expr.Validate(att, ex.Value); err != nil {
+			return fmt.Errorf("field %s: invalid example: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// normalize converts the maps decoded from yaml, which use interface{}
+// keys, to maps which can be marshaled to json.
+func normalize(v interface{}) interface{} {
+	switch actual := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(actual))
+		for k, v := range actual {
+			m[fmt.Sprintf("%v", k)] = normalize(v)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(actual))
+		for i, v := range actual {
+			s[i] = normalize(v)
+		}
+		return s
+	default:
+		return v
+	}
+}